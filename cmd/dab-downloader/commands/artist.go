@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/discography"
 	"dab-downloader/internal/shared"
 	"github.com/spf13/cobra"
 )
@@ -20,10 +22,12 @@ func NewArtistCommand() *cobra.Command {
 	}
 
 	// Add flags
-	cmd.Flags().String("filter", "all", "Filter by item type (albums, eps, singles), comma-separated")
+	cmd.Flags().String("filter", "all", "Filter expression or preset, e.g. 'albums,eps year:2010- -title:live' (see README)")
+	cmd.Flags().String("save-preset", "", "Save the --filter expression as a named preset in the config file")
 	cmd.Flags().Bool("no-confirm", false, "Skip confirmation prompt")
 	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
 	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+	cmd.Flags().String("duplicates", "", "Edition to keep of duplicate releases: prefer-explicit, prefer-hires, prefer-newest or keep-all (defaults to the DuplicatePolicy setting)")
 
 	return cmd
 }
@@ -43,6 +47,8 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 	noConfirm, _ := cmd.Flags().GetBool("no-confirm")
 	format, _ := cmd.Flags().GetString("format")
 	bitrate, _ := cmd.Flags().GetString("bitrate")
+	duplicates, _ := cmd.Flags().GetString("duplicates")
+	savePreset, _ := cmd.Flags().GetString("save-preset")
 	debug, _ := cmd.Flags().GetBool("debug")
 	
 	// Check if filter flag was explicitly set by user
@@ -58,12 +64,34 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 	if bitrate != "320" {
 		config.Bitrate = bitrate
 	}
+	if duplicates != "" {
+		config.DuplicatePolicy = duplicates
+	}
+	if config.DuplicatePolicy != "" && !discography.IsPolicy(config.DuplicatePolicy) {
+		return fmt.Errorf("unknown duplicate policy %q (supported: %s)", config.DuplicatePolicy, strings.Join(discography.Policies, ", "))
+	}
+	
+	// Check the filter before the discography is fetched
+	if _, err := discography.ParseFilter(filter, config.FilterPresets); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	if savePreset != "" {
+		if filter == "" {
+			return fmt.Errorf("--save-preset needs a --filter expression")
+		}
+		if err := saveFilterPreset(cmd, savePreset, filter); err != nil {
+			return err
+		}
+		serviceContainer.Logger.Success("Saved filter preset %q: %s", savePreset, filter)
+	}
 	
 	artistID := args[0]
 	serviceContainer.Logger.Info("🎵 Starting artist discography download for ID: %s", artistID)
 	
-	// Download artist discography
-	stats, err := serviceContainer.DownloadService.DownloadArtist(context.Background(), artistID, config, debug, config.Format, config.Bitrate, filter, noConfirm)
+	// Download artist discography, stopping cleanly on Ctrl-C
+	ctx, stop := interruptContext()
+	defer stop()
+	stats, err := serviceContainer.DownloadService.DownloadArtist(ctx, artistID, config, debug, config.Format, config.Bitrate, filter, noConfirm)
 	
 	// Handle errors but don't return early - we still want to show summaries
 	var hasError bool
@@ -76,6 +104,8 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 		} else {
 			serviceContainer.Logger.Error("❌ Failed to download discography: %v", err)
 		}
+	} else if ctx.Err() != nil {
+		serviceContainer.Logger.Warning("⏹️ Discography download interrupted.")
 	} else {
 		serviceContainer.Logger.Success("✅ Discography download completed!")
 	}
@@ -96,7 +126,7 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 	}
 	
 	// Show summary if we have any stats at all (success, failed, or skipped)
-	if stats != nil && (stats.SuccessCount > 0 || stats.FailedCount > 0 || stats.SkippedCount > 0) {
+	if stats != nil && (stats.SuccessCount > 0 || stats.FailedCount > 0 || stats.SkippedCount > 0 || stats.CancelledCount > 0) {
 		if debug {
 			serviceContainer.Logger.Debug("DEBUG: About to display download summary")
 		}
@@ -125,15 +155,25 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 			}
 		}
 		
+		if stats.CancelledCount > 0 {
+			shared.ColorWarning.Printf("⏹️ Not downloaded (interrupted): %d items\n", stats.CancelledCount)
+		}
+		
 		// Show download location
 		shared.ColorSuccess.Printf("📁 Artist discography downloaded to: %s\n", config.DownloadLocation)
-		shared.ColorSuccess.Printf("🎉 Discography download completed for %s\n", artistName)
+		if ctx.Err() == nil {
+			shared.ColorSuccess.Printf("🎉 Discography download completed for %s\n", artistName)
+		}
 	} else {
 		if debug {
 			serviceContainer.Logger.Debug("DEBUG: Not displaying summary - condition not met")
 		}
 	}
 	
+	if stats != nil {
+		finishNavidromeSession(ctx, serviceContainer, config, stats.SuccessCount)
+	}
+	
 	// Return error after showing summaries
 	if hasError {
 		return err
@@ -142,3 +182,14 @@ func runArtistCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// saveFilterPreset stores a filter expression under a name in the config file the command loaded
+func saveFilterPreset(cmd *cobra.Command, name, filter string) error {
+	configFile := config.DefaultConfigFile
+	if flag := cmd.Flags().Lookup("config"); flag != nil && flag.Value.String() != "" {
+		configFile = flag.Value.String()
+	}
+	if err := config.SaveFilterPreset(configFile, name, filter); err != nil {
+		return fmt.Errorf("failed to save filter preset: %w", err)
+	}
+	return nil
+}
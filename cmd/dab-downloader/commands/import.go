@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"dab-downloader/internal/api/navidrome"
+	"dab-downloader/internal/core/playlist"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates the command that downloads playlists exported to local files
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [playlist_file]",
+		Short: "Download a playlist from an M3U, XSPF, CSV or JSON file.",
+		Long:  "Reads a playlist file (M3U/M3U8, XSPF, or CSV/JSON exports from migration tools such as Exportify or TuneMyMusic), matches each entry to a DAB track by artist, title, album, duration and ISRC, and downloads the matches. Optionally creates a Navidrome playlist with the results.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runImportCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("navidrome", false, "Create a Navidrome playlist with the downloaded tracks")
+	cmd.Flags().String("playlist-name", "", "Name of the Navidrome playlist (defaults to the playlist's own name)")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+	addM3U8Flags(cmd)
+
+	return cmd
+}
+
+func runImportCommand(cmd *cobra.Command, args []string) error {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	toNavidrome, _ := cmd.Flags().GetBool("navidrome")
+	playlistName, _ := cmd.Flags().GetString("playlist-name")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	imported, err := playlist.ParseFile(args[0])
+	if err != nil {
+		return err
+	}
+	if len(imported.Entries) == 0 {
+		return fmt.Errorf("no tracks found in %s", args[0])
+	}
+	if playlistName == "" {
+		playlistName = imported.Name
+	}
+	serviceContainer.Logger.Info("📄 Importing %d tracks from %s", len(imported.Entries), imported.Name)
+
+	// Fail before downloading when the playlist cannot be created
+	if toNavidrome {
+		if err := serviceContainer.NavidromeService.Authenticate(); err != nil {
+			return fmt.Errorf("failed to authenticate with Navidrome: %w", err)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	report := search.NewMatchReport(config.MatchThreshold)
+	results := downloadMatchingTracks(ctx, serviceContainer, config, imported.Tracks(), report, debug)
+	downloaded := downloadedTracks(results)
+
+	fmt.Printf("\n")
+	shared.ColorInfo.Printf("📊 Import Summary for %s:\n", imported.Name)
+	shared.ColorSuccess.Printf("✅ Downloaded: %d of %d tracks\n", len(downloaded), len(imported.Entries))
+	if missing := len(imported.Entries) - len(downloaded); missing > 0 {
+		shared.ColorWarning.Printf("⚠️  Not downloaded: %d tracks\n", missing)
+	}
+	shared.ColorSuccess.Printf("📁 Downloaded to: %s\n", config.DownloadLocation)
+	saveMatchReport(serviceContainer, config, report)
+	saveM3U8(cmd, serviceContainer, config, playlistName, results)
+
+	if !toNavidrome || len(downloaded) == 0 {
+		finishNavidromeSession(ctx, serviceContainer, config, len(downloaded))
+		return nil
+	}
+
+	playlistID, err := serviceContainer.NavidromeService.CreatePlaylist(playlistName, downloaded)
+	var unmatchedErr *navidrome.UnmatchedTracksError
+	if err != nil && !errors.As(err, &unmatchedErr) {
+		return fmt.Errorf("failed to create Navidrome playlist: %w", err)
+	}
+
+	// Tracks not scanned yet are added after the rescan when NavidromeRescan is enabled
+	if config.NavidromeRescan {
+		unmatchedErr = nil
+		if err := serviceContainer.NavidromeService.FinishSession(ctx); err != nil && !errors.As(err, &unmatchedErr) {
+			return err
+		}
+	}
+
+	if unmatchedErr != nil {
+		shared.ColorWarning.Printf("⚠️  %d tracks were not found in Navidrome yet\n", len(unmatchedErr.Tracks))
+	}
+	shared.ColorSuccess.Printf("🎶 Created Navidrome playlist %s (%s)\n", playlistName, playlistID)
+	return nil
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"dab-downloader/internal/shared"
+)
+
+// interruptedExitCode is the exit status of a command stopped with a second Ctrl-C
+const interruptedExitCode = 130
+
+// interruptContext returns a context that is cancelled on the first Ctrl-C, so running downloads
+// stop and remove their partial files. A second Ctrl-C exits immediately. Call stop when the
+// command is done to restore the default signal handling.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		shared.ColorWarning.Println("\n⏹️ Interrupted, finishing up and removing partial files (press Ctrl-C again to quit now)...")
+		cancel()
+
+		select {
+		case <-signals:
+			os.Exit(interruptedExitCode)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// printInterruptedSummary shows what an interrupted download got done
+func printInterruptedSummary(ctx context.Context, stats *shared.DownloadStats) {
+	if ctx.Err() == nil || stats == nil {
+		return
+	}
+
+	shared.ColorWarning.Println("\n⏹️ Download interrupted")
+	shared.ColorSuccess.Printf("✅ Downloaded: %d\n", stats.SuccessCount)
+	if stats.SkippedCount > 0 {
+		shared.ColorWarning.Printf("⏭️  Already present: %d\n", stats.SkippedCount)
+	}
+	if stats.FailedCount > 0 {
+		shared.ColorError.Printf("❌ Failed: %d\n", stats.FailedCount)
+	}
+	if stats.CancelledCount > 0 {
+		shared.ColorWarning.Printf("⏹️ Not downloaded: %d\n", stats.CancelledCount)
+	}
+}
+
+// addStats adds the statistics of one download to a running total
+func addStats(total, stats *shared.DownloadStats) {
+	if stats == nil {
+		return
+	}
+	total.SuccessCount += stats.SuccessCount
+	total.SkippedCount += stats.SkippedCount
+	total.FailedCount += stats.FailedCount
+	total.CancelledCount += stats.CancelledCount
+	total.FailedItems = append(total.FailedItems, stats.FailedItems...)
+	total.Files = append(total.Files, stats.Files...)
+}
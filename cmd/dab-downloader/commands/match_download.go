@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+)
+
+// trackDownload is the outcome of matching and downloading one requested track
+type trackDownload struct {
+	Requested shared.Track
+	Match     *shared.Track // DAB track now on disk, nil when the track was not downloaded
+	Path      string        // Final file path, empty when the track was not downloaded
+}
+
+// downloadMatchingTracks matches each track to DAB and downloads the confident matches, returning
+// one result per requested track in the same order. Every match is recorded in the report.
+func downloadMatchingTracks(ctx context.Context, serviceContainer *services.ServiceContainer, config *config.Config, tracks []shared.Track, report *search.MatchReport, debug bool) []trackDownload {
+	matcher := search.NewMatcher(report.Threshold)
+	results := make([]trackDownload, len(tracks))
+	for i, track := range tracks {
+		results[i].Requested = track
+	}
+
+	for i, track := range tracks {
+		if ctx.Err() != nil {
+			break
+		}
+		match, err := matcher.FindTrack(ctx, serviceContainer.SearchService, track, debug)
+		report.Add(match)
+		if err != nil {
+			serviceContainer.Logger.Warning("⚠️ %v", err)
+			continue
+		}
+
+		if !match.Accepted() {
+			if match.Candidate != nil {
+				serviceContainer.Logger.Warning("⚠️ Skipping low-confidence match for %s - %s: %s - %s (score %.2f)", track.Artist, track.Title, match.Candidate.Artist, match.Candidate.Title, match.Score)
+			} else {
+				serviceContainer.Logger.Warning("⚠️ Not found on DAB: %s - %s", track.Artist, track.Title)
+			}
+			continue
+		}
+
+		serviceContainer.Logger.Info("⬇️ Downloading %s - %s", match.Candidate.Artist, match.Candidate.Title)
+		stats, err := serviceContainer.DownloadService.DownloadTrackDirect(ctx, *match.Candidate, config, debug, config.Format, config.Bitrate)
+		if err != nil {
+			if errors.Is(err, shared.ErrDownloadCancelled) {
+				return results
+			}
+			serviceContainer.Logger.Warning("⚠️ Failed to download %s - %s: %v", match.Candidate.Artist, match.Candidate.Title, err)
+			continue
+		}
+		if stats != nil && stats.SuccessCount+stats.SkippedCount > 0 {
+			results[i].Match = match.Candidate
+			if len(stats.Files) > 0 {
+				results[i].Path = stats.Files[0]
+			}
+		}
+	}
+	return results
+}
+
+// downloadedTracks returns the DAB tracks that are on disk
+func downloadedTracks(results []trackDownload) []shared.Track {
+	var tracks []shared.Track
+	for _, result := range results {
+		if result.Match != nil {
+			tracks = append(tracks, *result.Match)
+		}
+	}
+	return tracks
+}
+
+// saveMatchReport writes the match report when some tracks, albums or artists were not matched with confidence
+func saveMatchReport(serviceContainer *services.ServiceContainer, config *config.Config, report *search.MatchReport) {
+	problems := len(report.Problems())
+	if problems == 0 {
+		return
+	}
+
+	path := config.MatchReportFile
+	if path == "" {
+		path = search.DefaultMatchReportFile
+	}
+	if err := report.Save(path); err != nil {
+		serviceContainer.Logger.Warning("⚠️ Failed to save match report: %v", err)
+		return
+	}
+	serviceContainer.Logger.Warning("⚠️ %d items were not matched with confidence, see %s", problems, path)
+}
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/library"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// NewMissingCommand creates the command that reports which releases of an artist are not in the library
+func NewMissingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "missing [artist_id]",
+		Short: "Show which releases of an artist are missing from the library.",
+		Long:  "Fetches the artist's discography from DAB and compares it with the tags of the files in the library. Releases are grouped by type and listed as owned, partial (with the missing track numbers) or missing. With --all every artist folder in the library is checked. Nothing is downloaded unless --download is given.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runMissingCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("all", false, "Check every artist folder in the library")
+	cmd.Flags().String("path", "", "Library to compare with (defaults to the download location)")
+	cmd.Flags().String("output", "", "Print the report as json instead of text")
+	cmd.Flags().Bool("download", false, "Download the missing releases after the report")
+	cmd.Flags().Bool("include-partial", false, "With --download, also complete partial releases")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+
+	return cmd
+}
+
+func runMissingCommand(cmd *cobra.Command, args []string) error {
+	// Get command flags
+	all, _ := cmd.Flags().GetBool("all")
+	path, _ := cmd.Flags().GetString("path")
+	output, _ := cmd.Flags().GetString("output")
+	download, _ := cmd.Flags().GetBool("download")
+	includePartial, _ := cmd.Flags().GetBool("include-partial")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if all == (len(args) == 1) {
+		return fmt.Errorf("give either an artist ID or --all")
+	}
+	if output != "" && output != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: json)", output)
+	}
+	if output == "json" && download {
+		return fmt.Errorf("--download cannot be combined with --output json")
+	}
+
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+	if path == "" {
+		path = config.DownloadLocation
+	}
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if download && config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	// Progress goes to stderr with JSON output so the report can be piped. This includes what the
+	// API client prints to stdout while fetching the discography.
+	quiet := output == "json"
+	out := cmd.OutOrStdout()
+	if quiet {
+		restore := redirectStdout()
+		defer restore()
+	}
+	logInfo := func(format string, args ...interface{}) {
+		if quiet {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		} else {
+			serviceContainer.Logger.Info(format, args...)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	logInfo("🔍 Reading the tags of the library in %s", path)
+	index, err := library.Scan(path, func(file string, err error) {
+		if debug {
+			fmt.Fprintf(os.Stderr, "DEBUG: Skipping %s: %v\n", file, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	artistIDs := args
+	if all {
+		artistIDs = resolveArtistFolders(ctx, serviceContainer, index, debug, logInfo)
+	}
+
+	reports, err := writeMissingReports(ctx, out, serviceContainer.APIClient, artistIDs, index, config, quiet, all, debug, logInfo)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		shared.ColorWarning.Fprintln(os.Stderr, "\n⏹️ Interrupted")
+		return nil
+	}
+
+	if download {
+		return downloadMissingReleases(ctx, serviceContainer, config, reports, includePartial, debug)
+	}
+	return nil
+}
+
+// writeMissingReports builds the report of every artist and prints it, or writes all reports to out as
+// JSON. With --all, artists that fail are reported and skipped.
+func writeMissingReports(ctx context.Context, out io.Writer, api library.ArtistAPI, artistIDs []string, index *library.Index, config *config.Config, asJSON, all, debug bool, logInfo func(string, ...interface{})) ([]*library.Report, error) {
+	reports := []*library.Report{}
+	for _, artistID := range artistIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		logInfo("🎵 Comparing the discography of artist %s", artistID)
+		report, err := library.BuildReport(ctx, api, artistID, index, config, debug)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if !all {
+				return nil, err
+			}
+			shared.ColorError.Fprintf(os.Stderr, "❌ %v\n", err)
+			continue
+		}
+		reports = append(reports, report)
+		if !asJSON {
+			printMissingReport(report)
+		}
+	}
+	if ctx.Err() != nil || !asJSON {
+		return reports, nil
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if all {
+		return reports, encoder.Encode(reports)
+	}
+	return reports, encoder.Encode(reports[0])
+}
+
+// redirectStdout sends everything printed to stdout, through fmt or the shared colors, to stderr until
+// the returned function is called. Callers keep the original stdout writer for their own output.
+func redirectStdout() (restore func()) {
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	return func() {
+		os.Stdout, color.Output = stdout, colorOutput
+	}
+}
+
+// resolveArtistFolders finds the DAB artist of every top-level folder of the library, from the DAB_ARTISTID
+// tags of its files or else by searching for an artist with the folder's name
+func resolveArtistFolders(ctx context.Context, serviceContainer *services.ServiceContainer, index *library.Index, debug bool, logInfo func(string, ...interface{})) []string {
+	var artistIDs []string
+	seen := make(map[string]bool)
+	for _, folder := range index.ArtistFolders() {
+		artistID := folder.ArtistID
+		if artistID == "" {
+			results, err := serviceContainer.SearchService.Search(ctx, folder.Name, "artist", 5, debug)
+			if err == nil {
+				for _, artist := range results.Artists {
+					if strings.EqualFold(shared.SanitizeFileName(artist.Name), folder.Name) {
+						artistID = shared.IdToString(artist.ID)
+						break
+					}
+				}
+			}
+		}
+		if artistID == "" {
+			logInfo("⚠️ Skipping %s: no DAB artist found", folder.Name)
+			continue
+		}
+		if !seen[artistID] {
+			seen[artistID] = true
+			artistIDs = append(artistIDs, artistID)
+		}
+	}
+	return artistIDs
+}
+
+// printMissingReport prints the releases of an artist grouped by type
+func printMissingReport(report *library.Report) {
+	fmt.Printf("\n")
+	shared.ColorInfo.Printf("🎵 %s (%s): %d owned, %d partial, %d missing\n", report.Artist, report.ArtistID, report.Owned, report.Partial, report.Missing)
+
+	for _, releaseType := range library.ReleaseTypes {
+		header := false
+		for _, release := range report.Releases {
+			if release.Type != releaseType {
+				continue
+			}
+			if !header {
+				shared.ColorPrompt.Printf("  %sS\n", strings.ToUpper(releaseType))
+				header = true
+			}
+
+			title := release.Title
+			if release.Year != "" {
+				title = release.Year + "  " + title
+			}
+			switch release.Status {
+			case library.StatusOwned:
+				shared.ColorSuccess.Printf("    ✅ %s (%d tracks)\n", title, release.OwnedTracks)
+			case library.StatusPartial:
+				line := fmt.Sprintf("    🟡 %s (%d/%d tracks)", title, release.OwnedTracks, release.Tracks)
+				if len(release.MissingTracks) > 0 {
+					line += ", missing " + strings.Join(release.MissingTracks, ", ")
+				}
+				shared.ColorWarning.Println(line)
+			default:
+				shared.ColorError.Printf("    ❌ %s [%s]\n", title, release.ID)
+			}
+		}
+	}
+}
+
+// downloadMissingReleases downloads the missing releases of the reports, and the partial ones when asked
+func downloadMissingReleases(ctx context.Context, serviceContainer *services.ServiceContainer, config *config.Config, reports []*library.Report, includePartial, debug bool) error {
+	var albumIDs []string
+	for _, report := range reports {
+		for _, release := range report.Releases {
+			if release.Status == library.StatusMissing || (includePartial && release.Status == library.StatusPartial) {
+				albumIDs = append(albumIDs, release.ID)
+			}
+		}
+	}
+	if len(albumIDs) == 0 {
+		serviceContainer.Logger.Success("Nothing to download")
+		return nil
+	}
+
+	serviceContainer.Logger.Info("📥 Downloading %d releases", len(albumIDs))
+	total := &shared.DownloadStats{}
+	for _, albumID := range albumIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		stats, err := serviceContainer.DownloadService.DownloadAlbum(ctx, albumID, config, debug, config.Format, config.Bitrate)
+		addStats(total, stats)
+		if err != nil {
+			serviceContainer.Logger.Error("Failed to download album %s: %v", albumID, err)
+		}
+	}
+
+	serviceContainer.WarningCollector.PrintSummary()
+	if ctx.Err() != nil {
+		printInterruptedSummary(ctx, total)
+		return nil
+	}
+	serviceContainer.Logger.Success("Downloaded: %d, skipped: %d, failed: %d", total.SuccessCount, total.SkippedCount, total.FailedCount)
+	finishNavidromeSession(ctx, serviceContainer, config, total.SuccessCount)
+	return nil
+}
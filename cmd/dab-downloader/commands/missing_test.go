@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"dab-downloader/internal/api/dab"
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/library"
+)
+
+// TestMissingJSONOutputParses checks that nothing the DAB client prints ends up in the JSON report
+func TestMissingJSONOutputParses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/discography":
+			fmt.Fprint(w, `{"artist": {"id": "ar1", "name": "Artist"}, "albums": [{"id": "al1", "title": "First", "artist": "Artist"}]}`)
+		case "/api/album":
+			fmt.Fprint(w, `{"album": {"id": "al1", "title": "First", "artist": "Artist", "type": "album", "tracks": [{"id": 1, "title": "Song"}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	index, err := library.Scan(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	// Stand in for the terminal: stdout and the colored output both go to the pipe
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout, color.Output = writer, writer
+	defer func() { os.Stdout, color.Output = stdout, colorOutput }()
+
+	captured := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		captured <- data
+	}()
+
+	out := os.Stdout
+	restore := redirectStdout()
+	api := dab.NewDabAPI(server.URL, t.TempDir(), server.Client())
+	reports, err := writeMissingReports(context.Background(), out, api, []string{"ar1"}, index, &config.Config{Parallelism: 1}, true, false, false, func(string, ...interface{}) {})
+	restore()
+	writer.Close()
+	data := <-captured
+	if err != nil {
+		t.Fatalf("writeMissingReports failed: %v", err)
+	}
+
+	var report library.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("stdout is not a JSON report: %v\n%s", err, data)
+	}
+	if len(reports) != 1 || report.Artist != "Artist" || report.Missing != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
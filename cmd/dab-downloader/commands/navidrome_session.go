@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"context"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/services"
+)
+
+// finishNavidromeSession runs the Navidrome post-download hook once a command has downloaded
+// something, so the library is rescanned when NavidromeRescan is enabled. A failed rescan is
+// only reported, as the downloads themselves succeeded.
+func finishNavidromeSession(ctx context.Context, serviceContainer *services.ServiceContainer, config *config.Config, downloaded int) {
+	if !config.NavidromeRescan || downloaded == 0 || ctx.Err() != nil || serviceContainer.NavidromeService == nil {
+		return
+	}
+
+	serviceContainer.Logger.Info("🔄 Rescanning the Navidrome library")
+	if err := serviceContainer.NavidromeService.FinishSession(ctx); err != nil {
+		serviceContainer.Logger.Warning("⚠️ Navidrome rescan failed: %v", err)
+	}
+}
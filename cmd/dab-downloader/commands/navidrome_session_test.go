@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/interfaces"
+	"dab-downloader/internal/services"
+)
+
+// rescanCounter is a Navidrome service that only counts post-download hooks
+type rescanCounter struct {
+	interfaces.NavidromeService
+	calls int
+}
+
+func (r *rescanCounter) FinishSession(ctx context.Context) error {
+	r.calls++
+	return nil
+}
+
+func TestFinishNavidromeSession(t *testing.T) {
+	navidrome := &rescanCounter{}
+	serviceContainer := &services.ServiceContainer{NavidromeService: navidrome, Logger: services.NewConsoleLogger()}
+
+	finishNavidromeSession(context.Background(), serviceContainer, &config.Config{NavidromeRescan: false}, 3)
+	finishNavidromeSession(context.Background(), serviceContainer, &config.Config{NavidromeRescan: true}, 0)
+	if navidrome.calls != 0 {
+		t.Fatalf("Expected no rescan when disabled or nothing was downloaded, got %d", navidrome.calls)
+	}
+
+	finishNavidromeSession(context.Background(), serviceContainer, &config.Config{NavidromeRescan: true}, 3)
+	if navidrome.calls != 1 {
+		t.Errorf("Expected one rescan after downloads, got %d", navidrome.calls)
+	}
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"path/filepath"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/playlist"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// autoM3U8Path is the --m3u8 value that places the playlist in the download location
+const autoM3U8Path = "auto"
+
+// addM3U8Flags adds the flags that write the downloaded tracks to a local M3U8 playlist
+func addM3U8Flags(cmd *cobra.Command) {
+	cmd.Flags().String("m3u8", "", "Write an M3U8 playlist of the tracks to this file (without a value: <download location>/<playlist name>.m3u8)")
+	cmd.Flags().Lookup("m3u8").NoOptDefVal = autoM3U8Path
+	cmd.Flags().Bool("m3u8-absolute", false, "Use absolute paths in the M3U8 playlist instead of paths relative to it")
+}
+
+// saveM3U8 writes the playlist requested with --m3u8 in the order of the results. Tracks that were
+// not downloaded are kept as comments.
+func saveM3U8(cmd *cobra.Command, serviceContainer *services.ServiceContainer, config *config.Config, name string, results []trackDownload) {
+	path, _ := cmd.Flags().GetString("m3u8")
+	absolute, _ := cmd.Flags().GetBool("m3u8-absolute")
+	if path == "" {
+		return
+	}
+	if path == autoM3U8Path {
+		path = filepath.Join(config.DownloadLocation, shared.SanitizeFileName(name)+".m3u8")
+	}
+
+	entries := make([]playlist.ExportEntry, len(results))
+	for i, result := range results {
+		track := result.Requested
+		if result.Match != nil {
+			track = *result.Match
+			if track.Duration == 0 {
+				track.Duration = result.Requested.Duration
+			}
+		}
+		entries[i] = playlist.ExportEntry{
+			Title:    track.Title,
+			Artist:   track.Artist,
+			Duration: track.Duration,
+			Path:     result.Path,
+		}
+	}
+
+	if err := playlist.SaveM3U8(path, name, entries, absolute); err != nil {
+		serviceContainer.Logger.Warning("⚠️ Failed to save M3U8 playlist: %v", err)
+		return
+	}
+	shared.ColorSuccess.Printf("🎼 Saved M3U8 playlist to %s\n", path)
+}
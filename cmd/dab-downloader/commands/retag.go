@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dab-downloader/internal/core/downloader"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewRetagCommand creates the retag command for files already on disk
+func NewRetagCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retag [path]",
+		Short: "Rewrite the tags of downloaded FLAC files with fresh DAB and MusicBrainz metadata.",
+		Long:  "Recovers the DAB track of each FLAC file from its existing tags, re-fetches album info and MusicBrainz data, and rewrites the Vorbis comments. Audio frames and embedded pictures are left untouched.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRetagCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("dry-run", false, "Show the field-by-field changes without writing them")
+	cmd.Flags().Int("parallelism", 0, "Number of files to retag in parallel (defaults to the configured parallelism)")
+
+	return cmd
+}
+
+func runRetagCommand(cmd *cobra.Command, args []string) error {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	parallelism, _ := cmd.Flags().GetInt("parallelism")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if parallelism <= 0 {
+		parallelism = config.Parallelism
+	}
+
+	files, err := downloader.FindFLACFiles(args[0])
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		serviceContainer.Logger.Warning("⚠️ No FLAC files found in %s", args[0])
+		return nil
+	}
+
+	if dryRun {
+		serviceContainer.Logger.Info("🔍 Dry run: checking tags of %d files", len(files))
+	} else {
+		serviceContainer.Logger.Info("🏷️ Retagging %d files", len(files))
+	}
+
+	warningCollector, _ := serviceContainer.WarningCollector.(*shared.WarningCollector)
+	retagger := downloader.NewRetagger(serviceContainer.APIClient, config, downloader.RetagOptions{
+		DryRun:      dryRun,
+		Parallelism: parallelism,
+		Debug:       debug,
+	}, warningCollector)
+
+	var updated, unchanged, failed int
+	retagger.RetagFiles(context.Background(), files, func(result downloader.RetagResult) {
+		switch {
+		case result.Err != nil:
+			failed++
+			shared.ColorError.Printf("❌ %s: %v\n", result.FilePath, result.Err)
+		case len(result.Changes) == 0:
+			unchanged++
+			if debug {
+				serviceContainer.Logger.Debug("DEBUG: %s is up to date", result.FilePath)
+			}
+		default:
+			updated++
+			printTagChanges(result, dryRun)
+		}
+	})
+
+	// Warnings are shown before the summary, like the download commands
+	if warningCollector != nil {
+		warningCollector.PrintSummary()
+	}
+
+	fmt.Printf("\n")
+	shared.ColorInfo.Printf("📊 Retag Summary for %s:\n", args[0])
+	if updated > 0 {
+		if dryRun {
+			shared.ColorWarning.Printf("✏️  Would update: %d files\n", updated)
+		} else {
+			shared.ColorSuccess.Printf("✅ Updated: %d files\n", updated)
+		}
+	}
+	if unchanged > 0 {
+		shared.ColorSuccess.Printf("✔️  Already up to date: %d files\n", unchanged)
+	}
+	if failed > 0 {
+		shared.ColorError.Printf("❌ Failed: %d files\n", failed)
+		return fmt.Errorf("failed to retag %d files", failed)
+	}
+
+	return nil
+}
+
+// printTagChanges prints the field-by-field tag changes of a file
+func printTagChanges(result downloader.RetagResult, dryRun bool) {
+	verb := "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+	shared.ColorInfo.Printf("\n🏷️ %s %s (%s)\n", verb, result.FilePath, result.Track)
+
+	for _, change := range result.Changes {
+		oldValue := strings.Join(change.OldValues, "; ")
+		newValue := strings.Join(change.NewValues, "; ")
+		switch {
+		case len(change.OldValues) == 0:
+			shared.ColorSuccess.Printf("  + %s: %s\n", change.Field, newValue)
+		case len(change.NewValues) == 0:
+			shared.ColorError.Printf("  - %s: %s\n", change.Field, oldValue)
+		default:
+			shared.ColorWarning.Printf("  ~ %s: %s → %s\n", change.Field, oldValue, newValue)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"strings"
+
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewSearchCommand creates the command that searches DAB and downloads the selected results
+func NewSearchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search DAB for artists, albums or tracks and download the selection.",
+		Long:  "Searches DAB and shows the results page by page to select items to download. With --auto the first result is downloaded without prompting; with --output the results are printed for scripts.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runSearchCommand,
+	}
+
+	// Add flags
+	cmd.Flags().String("type", "all", "Type of search (artist, album, track, all)")
+	cmd.Flags().Bool("auto", false, "Download the first result without prompting")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+	AddSearchOutputFlags(cmd)
+
+	return cmd
+}
+
+func runSearchCommand(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+	if handled, err := HandleSearchOutput(cmd, query); handled || err != nil {
+		return err
+	}
+
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	searchType, _ := cmd.Flags().GetString("type")
+	auto, _ := cmd.Flags().GetBool("auto")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	return runPromptSearch(ctx, serviceContainer, config, query, searchType, debug, auto)
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dab-downloader/internal/core/search"
+	"github.com/spf13/cobra"
+)
+
+// AddSearchOutputFlags adds the flags that print search results for scripts instead of prompting
+func AddSearchOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().String("output", "", "Print the results and exit instead of prompting ("+strings.Join(search.OutputFormats, ", ")+")")
+	cmd.Flags().Int("limit", 10, "Number of results per type")
+	cmd.Flags().Int("offset", 0, "Number of results per type to skip, for paging")
+}
+
+// HandleSearchOutput prints the search results in the format given with --output for the search
+// command. It returns false when --output is not set and the interactive search should run.
+func HandleSearchOutput(cmd *cobra.Command, query string) (bool, error) {
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		return false, nil
+	}
+	if !search.IsOutputFormat(output) {
+		return true, fmt.Errorf("unsupported output format %q (supported: %s)", output, strings.Join(search.OutputFormats, ", "))
+	}
+
+	// Get command flags
+	searchType, _ := cmd.Flags().GetString("type")
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if searchType == "" {
+		searchType = "all"
+	}
+	if limit <= 0 {
+		return true, fmt.Errorf("--limit must be greater than 0")
+	}
+	if offset < 0 {
+		return true, fmt.Errorf("--offset cannot be negative")
+	}
+
+	// Get configuration and services
+	_, serviceContainer := initConfigAndServices(cmd)
+
+	results, err := serviceContainer.SearchService.SearchPage(context.Background(), query, searchType, limit, offset, debug)
+	if err != nil {
+		return true, fmt.Errorf("search failed: %w", err)
+	}
+	return true, search.WriteResults(cmd.OutOrStdout(), results, output)
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSearchCommandOutputFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"daft punk", "--output", "yaml"}, `unsupported output format "yaml"`},
+		{[]string{"daft punk", "--output", "json", "--limit", "0"}, "--limit must be greater than 0"},
+		{[]string{"daft punk", "--output", "tsv", "--offset", "-10"}, "--offset cannot be negative"},
+		{[]string{}, "requires at least 1 arg"},
+	}
+
+	for _, test := range tests {
+		cmd := NewSearchCommand()
+		cmd.SetArgs(test.args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("search %v: got error %v, want %q", test.args, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"dab-downloader/internal/api/spotify"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewSpotifyCommand creates the command that downloads Spotify links from DAB. The library
+// commands (liked, saved-albums, followed-artists) are its subcommands.
+func NewSpotifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spotify <link>",
+		Short: "Download a Spotify playlist, album, track or artist from DAB.",
+		Long:  "Matches a Spotify playlist, album, track or artist to DAB and downloads it. Links can be open.spotify.com URLs, spotify.link share links or spotify: URIs. Use the subcommands to download your own Spotify library.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSpotifyCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("expand", false, "Download the full album of every track in a playlist instead of the tracks")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+	addM3U8Flags(cmd)
+
+	cmd.AddCommand(NewSpotifyLibraryCommands()...)
+	return cmd
+}
+
+func runSpotifyCommand(cmd *cobra.Command, args []string) error {
+	parsed, err := spotify.ResolveLink(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+
+	// The URI is parsed without following a share link again
+	uri := fmt.Sprintf("spotify:%s:%s", parsed.Type, parsed.ID)
+	if handled, err := HandleSpotifyLink(cmd, uri); handled || err != nil {
+		return err
+	}
+
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	expand, _ := cmd.Flags().GetBool("expand")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	if err := serviceContainer.SpotifyService.Authenticate(); err != nil {
+		return fmt.Errorf("failed to authenticate with Spotify: %w", err)
+	}
+
+	var spotifyTracks []shared.SpotifyTrack
+	var name string
+	if parsed.Type == spotify.PlaylistLink {
+		spotifyTracks, name, err = serviceContainer.SpotifyService.GetPlaylistTracks(uri)
+	} else {
+		spotifyTracks, name, err = serviceContainer.SpotifyService.GetAlbumTracks(uri)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Spotify %s: %w", parsed.Type, err)
+	}
+	serviceContainer.Logger.Info("🎵 Found %d tracks in %s", len(spotifyTracks), name)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Whole albums are downloaded without per-track results to write an M3U8 playlist from
+	if expand && parsed.Type == spotify.PlaylistLink {
+		if m3u8, _ := cmd.Flags().GetString("m3u8"); m3u8 != "" {
+			serviceContainer.Logger.Warning("⚠️ --m3u8 is ignored with --expand")
+		}
+		albums := playlistAlbums(spotifyTracks)
+		report := search.NewMatchReport(config.MatchThreshold)
+		downloaded, total := downloadSpotifyAlbums(ctx, serviceContainer, config, albums, report, debug)
+		printInterruptedSummary(ctx, total)
+		printLibrarySummary(name+" (albums)", downloaded, len(albums), config)
+		saveMatchReport(serviceContainer, config, report)
+		finishNavidromeSession(ctx, serviceContainer, config, total.SuccessCount)
+		return nil
+	}
+
+	tracks := make([]shared.Track, len(spotifyTracks))
+	for i, track := range spotifyTracks {
+		tracks[i] = spotifyTrackToTrack(track)
+	}
+
+	report := search.NewMatchReport(config.MatchThreshold)
+	results := downloadMatchingTracks(ctx, serviceContainer, config, tracks, report, debug)
+	downloaded := len(downloadedTracks(results))
+	printLibrarySummary(name, downloaded, len(tracks), config)
+	saveMatchReport(serviceContainer, config, report)
+	saveM3U8(cmd, serviceContainer, config, name, results)
+	finishNavidromeSession(ctx, serviceContainer, config, downloaded)
+	return nil
+}
+
+// playlistAlbums returns the albums of the playlist tracks, each once and in playlist order
+func playlistAlbums(tracks []shared.SpotifyTrack) []shared.SpotifyAlbum {
+	seen := make(map[shared.SpotifyAlbum]bool)
+	var albums []shared.SpotifyAlbum
+	for _, track := range tracks {
+		artist := track.AlbumArtist
+		if artist == "" {
+			artist = track.Artist
+		}
+		album := shared.SpotifyAlbum{Name: track.AlbumName, Artist: artist}
+		if album.Name == "" || seen[album] {
+			continue
+		}
+		seen[album] = true
+		albums = append(albums, album)
+	}
+	return albums
+}
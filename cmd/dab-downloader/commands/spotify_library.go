@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/discography"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewSpotifyLibraryCommands creates the spotify subcommands that download from the user's own library
+func NewSpotifyLibraryCommands() []*cobra.Command {
+	liked := &cobra.Command{
+		Use:   "liked",
+		Short: "Download your Spotify Liked Songs.",
+		Args:  cobra.NoArgs,
+		RunE:  runSpotifyLikedCommand,
+	}
+	addM3U8Flags(liked)
+
+	savedAlbums := &cobra.Command{
+		Use:   "saved-albums",
+		Short: "Download the albums saved in your Spotify library.",
+		Args:  cobra.NoArgs,
+		RunE:  runSpotifySavedAlbumsCommand,
+	}
+
+	followedArtists := &cobra.Command{
+		Use:   "followed-artists",
+		Short: "Download the discographies of the artists you follow on Spotify.",
+		Args:  cobra.NoArgs,
+		RunE:  runSpotifyFollowedArtistsCommand,
+	}
+	followedArtists.Flags().String("filter", "all", "Filter expression or preset, e.g. 'albums,eps year:2010- -title:live' (see README)")
+	followedArtists.Flags().Bool("no-confirm", false, "Skip confirmation prompt")
+
+	commands := []*cobra.Command{liked, savedAlbums, followedArtists}
+	for _, cmd := range commands {
+		cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+		cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+	}
+	return commands
+}
+
+func runSpotifyLikedCommand(cmd *cobra.Command, args []string) error {
+	config, serviceContainer, debug, err := initSpotifyLibraryCommand(cmd)
+	if err != nil || config == nil {
+		return err
+	}
+
+	spotifyTracks, err := serviceContainer.SpotifyService.GetLikedTracks()
+	if err != nil {
+		return fmt.Errorf("failed to get liked songs: %w", err)
+	}
+	serviceContainer.Logger.Info("❤️ Found %d liked songs", len(spotifyTracks))
+
+	tracks := make([]shared.Track, len(spotifyTracks))
+	for i, track := range spotifyTracks {
+		tracks[i] = spotifyTrackToTrack(track)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	report := search.NewMatchReport(config.MatchThreshold)
+	results := downloadMatchingTracks(ctx, serviceContainer, config, tracks, report, debug)
+	downloaded := len(downloadedTracks(results))
+	printLibrarySummary("Liked Songs", downloaded, len(tracks), config)
+	saveMatchReport(serviceContainer, config, report)
+	saveM3U8(cmd, serviceContainer, config, "Liked Songs", results)
+	finishNavidromeSession(ctx, serviceContainer, config, downloaded)
+	return nil
+}
+
+func runSpotifySavedAlbumsCommand(cmd *cobra.Command, args []string) error {
+	config, serviceContainer, debug, err := initSpotifyLibraryCommand(cmd)
+	if err != nil || config == nil {
+		return err
+	}
+
+	albums, err := serviceContainer.SpotifyService.GetSavedAlbums()
+	if err != nil {
+		return fmt.Errorf("failed to get saved albums: %w", err)
+	}
+	serviceContainer.Logger.Info("💿 Found %d saved albums", len(albums))
+
+	ctx, stop := interruptContext()
+	defer stop()
+	report := search.NewMatchReport(config.MatchThreshold)
+	downloaded, total := downloadSpotifyAlbums(ctx, serviceContainer, config, albums, report, debug)
+
+	printInterruptedSummary(ctx, total)
+	printLibrarySummary("Saved Albums", downloaded, len(albums), config)
+	saveMatchReport(serviceContainer, config, report)
+	finishNavidromeSession(ctx, serviceContainer, config, total.SuccessCount)
+	return nil
+}
+
+// downloadSpotifyAlbums searches DAB for each Spotify album and downloads the matches, recording every
+// match in the report. It returns the number of albums downloaded and the combined track stats.
+func downloadSpotifyAlbums(ctx context.Context, serviceContainer *services.ServiceContainer, config *config.Config, albums []shared.SpotifyAlbum, report *search.MatchReport, debug bool) (int, *shared.DownloadStats) {
+	downloaded := 0
+	total := &shared.DownloadStats{}
+	for _, spotifyAlbum := range albums {
+		if ctx.Err() != nil {
+			break
+		}
+		results, err := serviceContainer.SearchService.Search(ctx, spotifyAlbum.Name+" "+search.PrimaryArtist(spotifyAlbum.Artist), "album", 5, debug)
+		if err != nil {
+			serviceContainer.Logger.Warning("⚠️ Search failed for %s - %s: %v", spotifyAlbum.Artist, spotifyAlbum.Name, err)
+			continue
+		}
+
+		album := findAlbumMatch(serviceContainer, report, spotifyAlbum, results)
+		if album == nil {
+			continue
+		}
+
+		serviceContainer.Logger.Info("⬇️ Downloading %s - %s", album.Artist, album.Title)
+		stats, err := serviceContainer.DownloadService.DownloadAlbum(ctx, album.ID, config, debug, config.Format, config.Bitrate)
+		addStats(total, stats)
+		if err != nil {
+			if errors.Is(err, shared.ErrDownloadCancelled) || ctx.Err() != nil {
+				break
+			}
+			serviceContainer.Logger.Warning("⚠️ Failed to download %s - %s: %v", album.Artist, album.Title, err)
+			continue
+		}
+		downloaded++
+	}
+	return downloaded, total
+}
+
+func runSpotifyFollowedArtistsCommand(cmd *cobra.Command, args []string) error {
+	config, serviceContainer, debug, err := initSpotifyLibraryCommand(cmd)
+	if err != nil || config == nil {
+		return err
+	}
+	filter, _ := cmd.Flags().GetString("filter")
+	noConfirm, _ := cmd.Flags().GetBool("no-confirm")
+	if _, err := discography.ParseFilter(filter, config.FilterPresets); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+
+	artists, err := serviceContainer.SpotifyService.GetFollowedArtists()
+	if err != nil {
+		return fmt.Errorf("failed to get followed artists: %w", err)
+	}
+	serviceContainer.Logger.Info("🎤 Found %d followed artists", len(artists))
+
+	ctx, stop := interruptContext()
+	defer stop()
+	report := search.NewMatchReport(config.MatchThreshold)
+	downloaded := 0
+	total := &shared.DownloadStats{}
+	for _, spotifyArtist := range artists {
+		if ctx.Err() != nil {
+			break
+		}
+		results, err := serviceContainer.SearchService.Search(ctx, spotifyArtist.Name, "artist", 5, debug)
+		if err != nil {
+			serviceContainer.Logger.Warning("⚠️ Search failed for %s: %v", spotifyArtist.Name, err)
+			continue
+		}
+
+		artist := findArtistMatch(serviceContainer, report, spotifyArtist, results)
+		if artist == nil {
+			continue
+		}
+
+		serviceContainer.Logger.Info("⬇️ Downloading discography of %s", artist.Name)
+		stats, err := serviceContainer.DownloadService.DownloadArtist(ctx, shared.IdToString(artist.ID), config, debug, config.Format, config.Bitrate, filter, noConfirm)
+		addStats(total, stats)
+		if err != nil {
+			if errors.Is(err, shared.ErrDownloadCancelled) || ctx.Err() != nil {
+				break
+			}
+			if !errors.Is(err, shared.ErrNoItemsSelected) {
+				serviceContainer.Logger.Warning("⚠️ Failed to download %s: %v", artist.Name, err)
+			}
+			continue
+		}
+		downloaded++
+	}
+
+	printInterruptedSummary(ctx, total)
+	printLibrarySummary("Followed Artists", downloaded, len(artists), config)
+	saveMatchReport(serviceContainer, config, report)
+	finishNavidromeSession(ctx, serviceContainer, config, total.SuccessCount)
+	return nil
+}
+
+// initSpotifyLibraryCommand applies the shared flags and logs in as the Spotify user. A nil
+// config without an error means the command cannot run and has already told the user why.
+func initSpotifyLibraryCommand(cmd *cobra.Command) (*config.Config, *services.ServiceContainer, bool, error) {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil, nil, false, nil
+	}
+
+	if err := serviceContainer.SpotifyService.AuthenticateUser(context.Background()); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to log in to Spotify: %w", err)
+	}
+	return config, serviceContainer, debug, nil
+}
+
+// spotifyTrackToTrack converts a Spotify track to the shared track type used for matching
+func spotifyTrackToTrack(track shared.SpotifyTrack) shared.Track {
+	return shared.Track{
+		Title:       track.Name,
+		Artist:      track.Artist,
+		Album:       track.AlbumName,
+		AlbumArtist: track.AlbumArtist,
+		ISRC:        track.ISRC,
+		Duration:    track.Duration,
+	}
+}
+
+// findAlbumMatch scores the search results for a Spotify album with the matcher and returns the
+// accepted match. The outcome is recorded in the report and near misses are reported.
+func findAlbumMatch(serviceContainer *services.ServiceContainer, report *search.MatchReport, spotifyAlbum shared.SpotifyAlbum, results *shared.SearchResults) *shared.Album {
+	var candidates []shared.Album
+	if results != nil {
+		candidates = results.Albums
+	}
+	album, match := search.NewMatcher(report.Threshold).BestAlbum(spotifyAlbum.Name, spotifyAlbum.Artist, candidates)
+	report.Add(match)
+	switch {
+	case match.Accepted():
+		return album
+	case album != nil:
+		serviceContainer.Logger.Warning("⚠️ Skipping low-confidence match for %s - %s: %s - %s (score %.2f)", spotifyAlbum.Artist, spotifyAlbum.Name, album.Artist, album.Title, match.Score)
+	default:
+		serviceContainer.Logger.Warning("⚠️ Not found on DAB: %s - %s", spotifyAlbum.Artist, spotifyAlbum.Name)
+	}
+	return nil
+}
+
+// findArtistMatch returns the search result named like the Spotify artist. The outcome is recorded
+// in the report and near misses are reported.
+func findArtistMatch(serviceContainer *services.ServiceContainer, report *search.MatchReport, spotifyArtist shared.SpotifyArtist, results *shared.SearchResults) *shared.Artist {
+	var candidates []shared.Artist
+	if results != nil {
+		candidates = results.Artists
+	}
+	artist, match := search.NewMatcher(report.Threshold).BestArtist(spotifyArtist.Name, candidates)
+	report.Add(match)
+	switch {
+	case match.Accepted():
+		return artist
+	case artist != nil:
+		serviceContainer.Logger.Warning("⚠️ Skipping low-confidence match for %s: %s (score %.2f)", spotifyArtist.Name, artist.Name, match.Score)
+	default:
+		serviceContainer.Logger.Warning("⚠️ Not found on DAB: %s", spotifyArtist.Name)
+	}
+	return nil
+}
+
+// printLibrarySummary prints how many library items were found on DAB and downloaded
+func printLibrarySummary(source string, downloaded, total int, config *config.Config) {
+	fmt.Printf("\n")
+	shared.ColorInfo.Printf("📊 Download Summary for %s:\n", source)
+	shared.ColorSuccess.Printf("✅ Downloaded: %d of %d items\n", downloaded, total)
+	if missing := total - downloaded; missing > 0 {
+		shared.ColorWarning.Printf("⚠️  Not downloaded: %d items\n", missing)
+	}
+	shared.ColorSuccess.Printf("📁 Downloaded to: %s\n", config.DownloadLocation)
+}
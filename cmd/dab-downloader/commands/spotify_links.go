@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dab-downloader/internal/api/spotify"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// HandleSpotifyLink downloads Spotify track and artist links for the spotify command: a track
+// link downloads the matching DAB track and an artist link the matching DAB discography.
+// It returns false for playlist and album links, which the spotify command handles itself.
+func HandleSpotifyLink(cmd *cobra.Command, link string) (bool, error) {
+	parsed, err := spotify.ResolveLink(context.Background(), link)
+	if err != nil {
+		return false, err
+	}
+	if parsed.Type != spotify.TrackLink && parsed.Type != spotify.ArtistLink {
+		return false, nil
+	}
+
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "" && format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "" && bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return true, nil
+	}
+
+	if err := serviceContainer.SpotifyService.Authenticate(); err != nil {
+		return true, fmt.Errorf("failed to authenticate with Spotify: %w", err)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if parsed.Type == spotify.TrackLink {
+		spotifyTrack, err := serviceContainer.SpotifyService.GetTrack(link)
+		if err != nil {
+			return true, fmt.Errorf("failed to get Spotify track: %w", err)
+		}
+
+		report := search.NewMatchReport(config.MatchThreshold)
+		if downloadedTracks(downloadMatchingTracks(ctx, serviceContainer, config, []shared.Track{spotifyTrackToTrack(spotifyTrack)}, report, debug)) == nil {
+			saveMatchReport(serviceContainer, config, report)
+			return true, fmt.Errorf("could not download %s - %s", spotifyTrack.Artist, spotifyTrack.Name)
+		}
+		serviceContainer.Logger.Success("✅ Downloaded %s - %s", spotifyTrack.Artist, spotifyTrack.Name)
+		return true, nil
+	}
+
+	spotifyArtist, err := serviceContainer.SpotifyService.GetArtist(link)
+	if err != nil {
+		return true, fmt.Errorf("failed to get Spotify artist: %w", err)
+	}
+
+	results, err := serviceContainer.SearchService.Search(ctx, spotifyArtist.Name, "artist", 5, debug)
+	if err != nil {
+		return true, fmt.Errorf("failed to search for %s: %w", spotifyArtist.Name, err)
+	}
+	report := search.NewMatchReport(config.MatchThreshold)
+	artist := findArtistMatch(serviceContainer, report, spotifyArtist, results)
+	if artist == nil {
+		saveMatchReport(serviceContainer, config, report)
+		return true, fmt.Errorf("artist %s not found on DAB", spotifyArtist.Name)
+	}
+
+	serviceContainer.Logger.Info("🎵 Starting artist discography download for %s", artist.Name)
+	stats, err := serviceContainer.DownloadService.DownloadArtist(ctx, shared.IdToString(artist.ID), config, debug, config.Format, config.Bitrate, "", false)
+	printInterruptedSummary(ctx, stats)
+	if err != nil && !errors.Is(err, shared.ErrDownloadCancelled) && !errors.Is(err, shared.ErrNoItemsSelected) {
+		return true, fmt.Errorf("failed to download discography: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSpotifyCommandRouting(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"https://example.com/playlist/abc"}, "not a Spotify URL"},
+		{[]string{"spotify:show:abc"}, "unsupported Spotify URI"},
+		{[]string{"liked", "extra"}, `unknown command "extra" for "spotify liked"`},
+		{[]string{"followed-artists", "--filter"}, "flag needs an argument"},
+	}
+
+	for _, test := range tests {
+		cmd := NewSpotifyCommand()
+		cmd.SetArgs(test.args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("spotify %v: got error %v, want %q", test.args, err, test.want)
+		}
+	}
+}
+
+func TestHandleSpotifyLinkLeavesCollections(t *testing.T) {
+	for _, link := range []string{"spotify:playlist:abc", "https://open.spotify.com/album/xyz?si=1"} {
+		handled, err := HandleSpotifyLink(NewSpotifyCommand(), link)
+		if handled || err != nil {
+			t.Errorf("HandleSpotifyLink(%q) = %v, %v, want the spotify command to handle it", link, handled, err)
+		}
+	}
+}
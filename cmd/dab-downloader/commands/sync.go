@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"dab-downloader/internal/core/playlistsync"
+	"dab-downloader/internal/core/search"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewSyncCommand creates the command that keeps Navidrome playlists in sync with Spotify
+func NewSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [spotify_playlist_url...]",
+		Short: "Keep Navidrome playlists in sync with Spotify playlists.",
+		Long:  "Creates a Navidrome playlist for each Spotify playlist on the first run and remembers the mapping. Later runs add new tracks, remove tracks removed on Spotify and keep the Spotify order. Songs missing from the library are downloaded from DAB first. Songs added to the Navidrome playlist by hand are kept.",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  runSyncCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("all", false, "Sync every playlist synced before")
+	cmd.Flags().Bool("no-download", false, "Only use songs already in the Navidrome library")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+
+	return cmd
+}
+
+func runSyncCommand(cmd *cobra.Command, args []string) error {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	all, _ := cmd.Flags().GetBool("all")
+	noDownload, _ := cmd.Flags().GetBool("no-download")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	store, err := playlistsync.LoadMappingStore(config.PlaylistSyncFile)
+	if err != nil {
+		return err
+	}
+
+	playlistURLs := args
+	if all {
+		for _, mapping := range store.All() {
+			playlistURLs = append(playlistURLs, mapping.SpotifyURL)
+		}
+	}
+	playlistURLs = uniquePlaylistURLs(playlistURLs)
+	if len(playlistURLs) == 0 {
+		return fmt.Errorf("provide a Spotify playlist URL or use --all")
+	}
+
+	if err := serviceContainer.SpotifyService.Authenticate(); err != nil {
+		return fmt.Errorf("failed to authenticate with Spotify: %w", err)
+	}
+	if err := serviceContainer.NavidromeService.Authenticate(); err != nil {
+		return fmt.Errorf("failed to authenticate with Navidrome: %w", err)
+	}
+	navidromeService, ok := serviceContainer.NavidromeService.(*services.NavidromeServiceWrapper)
+	if !ok {
+		return fmt.Errorf("playlist sync requires the Navidrome service")
+	}
+
+	report := search.NewMatchReport(config.MatchThreshold)
+	var downloadMissing playlistsync.MissingTrackHandler
+	if !noDownload {
+		downloadMissing = func(ctx context.Context, tracks []shared.Track) (int, error) {
+			return len(downloadedTracks(downloadMatchingTracks(ctx, serviceContainer, config, tracks, report, debug))), nil
+		}
+	}
+
+	syncer := playlistsync.NewSyncer(serviceContainer.SpotifyService, navidromeService.Client(), store, downloadMissing)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var failed int
+	for _, playlistURL := range playlistURLs {
+		if ctx.Err() != nil {
+			break
+		}
+		serviceContainer.Logger.Info("🔄 Syncing %s", playlistURL)
+		result, err := syncer.Sync(ctx, playlistURL)
+		if err != nil {
+			failed++
+			serviceContainer.Logger.Error("❌ Failed to sync %s: %v", playlistURL, err)
+			continue
+		}
+		printSyncResult(result)
+	}
+	saveMatchReport(serviceContainer, config, report)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d playlists", failed)
+	}
+	return nil
+}
+
+// uniquePlaylistURLs drops the URLs of playlists already listed, such as a playlist given as an
+// argument that --all adds again. URLs that cannot be parsed are kept for the sync to report.
+func uniquePlaylistURLs(playlistURLs []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, playlistURL := range playlistURLs {
+		if id, err := playlistsync.SpotifyPlaylistID(playlistURL); err == nil {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		unique = append(unique, playlistURL)
+	}
+	return unique
+}
+
+// printSyncResult prints what a playlist sync changed
+func printSyncResult(result *playlistsync.Result) {
+	fmt.Printf("\n")
+	shared.ColorInfo.Printf("📊 Sync Summary for %s:\n", result.PlaylistName)
+	if result.Created {
+		shared.ColorSuccess.Printf("🆕 Created Navidrome playlist %s\n", result.NavidromePlaylistID)
+	}
+	if result.Downloaded > 0 {
+		shared.ColorSuccess.Printf("⬇️  Downloaded: %d tracks\n", result.Downloaded)
+	}
+	if result.Added > 0 {
+		shared.ColorSuccess.Printf("➕ Added: %d tracks\n", result.Added)
+	}
+	if result.Removed > 0 {
+		shared.ColorWarning.Printf("➖ Removed: %d tracks\n", result.Removed)
+	}
+	if result.Reordered {
+		shared.ColorInfo.Printf("🔀 Reordered to match Spotify\n")
+	}
+	if result.Added == 0 && result.Removed == 0 && !result.Reordered {
+		shared.ColorSuccess.Printf("✔️  Already up to date\n")
+	}
+	if len(result.Unmatched) > 0 {
+		shared.ColorError.Printf("❌ Not found: %d tracks\n", len(result.Unmatched))
+		for _, track := range result.Unmatched {
+			shared.ColorError.Printf("   - %s - %s\n", track.Artist, track.Title)
+		}
+	}
+}
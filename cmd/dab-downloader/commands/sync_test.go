@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniquePlaylistURLs(t *testing.T) {
+	urls := []string{
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc",
+		"not a playlist",
+		"https://open.spotify.com/playlist/0vvXsWCC9xrXsKd4FyS8kM",
+		"spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+		"https://open.spotify.com/playlist/0vvXsWCC9xrXsKd4FyS8kM",
+	}
+	want := []string{
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc",
+		"not a playlist",
+		"https://open.spotify.com/playlist/0vvXsWCC9xrXsKd4FyS8kM",
+	}
+	if got := uniquePlaylistURLs(urls); !reflect.DeepEqual(got, want) {
+		t.Errorf("uniquePlaylistURLs() = %v, want %v", got, want)
+	}
+}
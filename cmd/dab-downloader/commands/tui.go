@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/services"
+	"dab-downloader/internal/shared"
+	"dab-downloader/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// NewTUICommand creates the command that opens the full-screen browser
+func NewTUICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui [query]",
+		Short: "Browse, select and download in a full-screen terminal UI.",
+		Long:  "Opens a full-screen interface to search DAB, browse artist discographies, select releases and tracks with checkboxes and watch the download queue. Without an interactive terminal it falls back to the regular search prompts.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runTUICommand,
+	}
+
+	// Add flags
+	cmd.Flags().String("type", "all", "Type of search (artist, album, track, all)")
+	cmd.Flags().String("format", "flac", "Format to convert to after downloading (e.g., mp3, ogg, opus)")
+	cmd.Flags().String("bitrate", "320", "Bitrate for lossy formats (in kbps, e.g., 192, 256, 320)")
+
+	return cmd
+}
+
+func runTUICommand(cmd *cobra.Command, args []string) error {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	searchType, _ := cmd.Flags().GetString("type")
+	format, _ := cmd.Flags().GetString("format")
+	bitrate, _ := cmd.Flags().GetString("bitrate")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	// Override config with command flags if provided
+	if format != "flac" {
+		config.Format = format
+	}
+	if bitrate != "320" {
+		config.Bitrate = bitrate
+	}
+	if config.Format != "flac" && !shared.CheckFFmpeg() {
+		printInstallInstructions()
+		return nil
+	}
+
+	query := strings.Join(args, " ")
+	ctx, stop := interruptContext()
+	defer stop()
+	if shared.IsTTY() {
+		app := tui.NewApp(serviceContainer.SearchService, serviceContainer.DownloadService, serviceContainer.WarningCollector, tui.Options{
+			Config:     config,
+			Debug:      debug,
+			Query:      query,
+			SearchType: searchType,
+		})
+		err := app.Run(ctx)
+		if !errors.Is(err, tui.ErrNoTerminal) {
+			if err != nil {
+				return err
+			}
+			shared.ColorInfo.Println(app.Summary())
+			serviceContainer.WarningCollector.PrintSummary()
+			return nil
+		}
+		serviceContainer.Logger.Warning("Full-screen mode is unavailable (%v), using the search prompts", err)
+	}
+
+	return runPromptSearch(ctx, serviceContainer, config, query, searchType, debug, false)
+}
+
+// runPromptSearch searches with the interactive prompts, or takes the first result with auto, and
+// downloads the selection
+func runPromptSearch(ctx context.Context, serviceContainer *services.ServiceContainer, config *config.Config, query, searchType string, debug, auto bool) error {
+	if query == "" {
+		query = shared.GetUserInput("Search for", "")
+		if query == "" {
+			return fmt.Errorf("no search query given")
+		}
+	}
+
+	selectedItems, itemTypes, err := serviceContainer.SearchService.HandleSearch(ctx, query, searchType, debug, auto, config)
+	if err != nil {
+		return err
+	}
+
+	total := &shared.DownloadStats{}
+	for i, item := range selectedItems {
+		if ctx.Err() != nil {
+			break
+		}
+		var stats *shared.DownloadStats
+		switch itemTypes[i] {
+		case "artist":
+			artist := item.(shared.Artist)
+			stats, err = serviceContainer.DownloadService.DownloadArtist(ctx, shared.IdToString(artist.ID), config, debug, config.Format, config.Bitrate, "", false)
+		case "album":
+			album := item.(shared.Album)
+			stats, err = serviceContainer.DownloadService.DownloadAlbum(ctx, album.ID, config, debug, config.Format, config.Bitrate)
+		case "track":
+			track := item.(shared.Track)
+			stats, err = serviceContainer.DownloadService.DownloadTrackDirect(ctx, track, config, debug, config.Format, config.Bitrate)
+		}
+		addStats(total, stats)
+		if err != nil {
+			serviceContainer.Logger.Error("Download failed: %v", err)
+			continue
+		}
+		if stats != nil {
+			serviceContainer.Logger.Success("Downloaded: %d, skipped: %d, failed: %d", stats.SuccessCount, stats.SkippedCount, stats.FailedCount)
+		}
+	}
+
+	printInterruptedSummary(ctx, total)
+	serviceContainer.WarningCollector.PrintSummary()
+	return nil
+}
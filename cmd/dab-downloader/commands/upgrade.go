@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"dab-downloader/internal/api/dab"
+	"dab-downloader/internal/core/downloader"
+	"dab-downloader/internal/shared"
+	"github.com/spf13/cobra"
+)
+
+// NewUpgradeCommand creates the command that replaces files with better streams from DAB
+func NewUpgradeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade [path]",
+		Short: "Replace downloaded files with better quality streams when DAB has them.",
+		Long:  "Scans the library (or the given path), reads the quality of each file from its FLAC stream info or codec, recovers its DAB track from the tags and compares it with the album quality DAB offers. Files that can be strictly improved, e.g. 16/44.1 to 24/96 or MP3 to FLAC, are listed first and only replaced after confirmation. The tags of the old file, including fields you added, are kept.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runUpgradeCommand,
+	}
+
+	// Add flags
+	cmd.Flags().Bool("dry-run", false, "Only list the files that would be upgraded")
+	cmd.Flags().Bool("yes", false, "Replace the files without asking for confirmation")
+	cmd.Flags().Int("parallelism", 0, "Number of files to check and download in parallel (defaults to the configured parallelism)")
+
+	return cmd
+}
+
+func runUpgradeCommand(cmd *cobra.Command, args []string) error {
+	// Get configuration and services
+	config, serviceContainer := initConfigAndServices(cmd)
+
+	// Get command flags
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+	parallelism, _ := cmd.Flags().GetInt("parallelism")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if parallelism <= 0 {
+		parallelism = config.Parallelism
+	}
+	root := config.DownloadLocation
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	dabAPI, ok := serviceContainer.APIClient.(*dab.DabAPI)
+	if !ok {
+		return fmt.Errorf("upgrade needs the DAB API client")
+	}
+
+	files, err := downloader.FindAudioFiles(root)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		serviceContainer.Logger.Warning("⚠️ No audio files found in %s", root)
+		return nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	warningCollector, _ := serviceContainer.WarningCollector.(*shared.WarningCollector)
+	upgrader := downloader.NewUpgrader(serviceContainer.APIClient, downloader.NewTrackDownloader(dabAPI, config), config, downloader.UpgradeOptions{
+		Parallelism: parallelism,
+		Debug:       debug,
+	}, warningCollector)
+
+	// Dry run: list what can be upgraded before anything is downloaded
+	serviceContainer.Logger.Info("🔍 Checking the quality of %d files", len(files))
+	candidates := upgrader.CheckFiles(ctx, files, func(candidate downloader.UpgradeCandidate) {
+		if candidate.Err != nil && !errors.Is(candidate.Err, ctx.Err()) {
+			shared.ColorError.Printf("❌ %s: %v\n", candidate.FilePath, candidate.Err)
+		} else if debug && candidate.Err == nil && !candidate.Better {
+			serviceContainer.Logger.Debug("%s (%s) is already the best available quality", candidate.FilePath, candidate.Current)
+		}
+	})
+	if ctx.Err() != nil {
+		shared.ColorWarning.Println("\n⏹️ Interrupted, nothing was replaced")
+		return nil
+	}
+
+	var upgrades, upToDate, failed int
+	fmt.Printf("\n")
+	for _, candidate := range candidates {
+		switch {
+		case candidate.Err != nil:
+			failed++
+		case candidate.Better:
+			upgrades++
+			shared.ColorWarning.Printf("⬆️  %s (%s)\n", candidate.FilePath, candidate.Track)
+			fmt.Printf("    %s → FLAC %d/%g\n", candidate.Current, candidate.Available.MaximumBitDepth, candidate.Available.MaximumSamplingRate)
+		default:
+			upToDate++
+		}
+	}
+
+	shared.ColorInfo.Printf("\n📊 Upgrade check for %s:\n", root)
+	shared.ColorWarning.Printf("⬆️  Can be upgraded: %d files\n", upgrades)
+	if upToDate > 0 {
+		shared.ColorSuccess.Printf("✔️  Best available quality: %d files\n", upToDate)
+	}
+	if failed > 0 {
+		shared.ColorError.Printf("❌ Could not be checked: %d files\n", failed)
+	}
+
+	if dryRun || upgrades == 0 {
+		return nil
+	}
+	if !yes && !shared.GetYesNoInput(fmt.Sprintf("Replace %d files with the better streams? (y/n)", upgrades), "n") {
+		return nil
+	}
+
+	// Replace the files
+	stats := &shared.DownloadStats{}
+	upgrader.UpgradeFiles(ctx, candidates, func(result downloader.UpgradeResult) {
+		switch {
+		case result.Err == nil:
+			stats.SuccessCount++
+			shared.ColorSuccess.Printf("✅ %s: %s → %s\n", result.NewPath, result.Previous, result.Delivered)
+		case errors.Is(result.Err, ctx.Err()):
+			stats.CancelledCount++
+		default:
+			stats.FailedCount++
+			stats.FailedItems = append(stats.FailedItems, fmt.Sprintf("%s: %v", result.FilePath, result.Err))
+			shared.ColorError.Printf("❌ %s: %v\n", result.FilePath, result.Err)
+		}
+	})
+
+	// Warnings are shown before the summary, like the download commands
+	if warningCollector != nil {
+		warningCollector.PrintSummary()
+	}
+	if ctx.Err() != nil {
+		printInterruptedSummary(ctx, stats)
+		return nil
+	}
+
+	shared.ColorInfo.Printf("\n📊 Upgrade Summary for %s:\n", root)
+	shared.ColorSuccess.Printf("✅ Upgraded: %d files\n", stats.SuccessCount)
+	if stats.FailedCount > 0 {
+		shared.ColorError.Printf("❌ Failed: %d files\n", stats.FailedCount)
+		return fmt.Errorf("failed to upgrade %d files", stats.FailedCount)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package acoustid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"dab-downloader/internal/shared"
+)
+
+// 1. Constants and types
+const (
+	DefaultBaseURL      = "https://api.acoustid.org/v2/"
+	defaultTimeout      = 30 * time.Second
+	defaultRateLimit    = 334 * time.Millisecond // AcoustID allows 3 requests per second
+	defaultBurstLimit   = 3
+	defaultMaxRetries   = 3
+	defaultInitialDelay = 1 * time.Second
+	defaultMaxDelay     = 30 * time.Second
+)
+
+// Config holds configuration for the AcoustID API client
+type Config struct {
+	BaseURL      string        `json:"base_url"` // Any AcoustID-compatible endpoint, e.g. a local stub in tests
+	APIKey       string        `json:"api_key"`
+	Timeout      time.Duration `json:"timeout"`
+	MaxRetries   int           `json:"max_retries"`
+	InitialDelay time.Duration `json:"initial_delay"`
+	MaxDelay     time.Duration `json:"max_delay"`
+	RateLimit    time.Duration `json:"rate_limit"`
+	BurstLimit   int           `json:"burst_limit"`
+	Debug        bool          `json:"debug"`
+}
+
+// Client represents an AcoustID API client
+type Client struct {
+	httpClient  *http.Client
+	config      Config
+	rateLimiter *rate.Limiter
+}
+
+// 2. Constructor and configuration
+
+// DefaultConfig returns sensible defaults for the AcoustID API client
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:      DefaultBaseURL,
+		Timeout:      defaultTimeout,
+		MaxRetries:   defaultMaxRetries,
+		InitialDelay: defaultInitialDelay,
+		MaxDelay:     defaultMaxDelay,
+		RateLimit:    defaultRateLimit,
+		BurstLimit:   defaultBurstLimit,
+	}
+}
+
+// NewClient creates a new AcoustID API client for the given application key
+func NewClient(apiKey string) *Client {
+	config := DefaultConfig()
+	config.APIKey = apiKey
+	return NewClientWithConfig(config)
+}
+
+// NewClientWithConfig creates a new AcoustID API client with custom configuration
+func NewClientWithConfig(config Config) *Client {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(config.BaseURL, "/") {
+		config.BaseURL += "/"
+	}
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
+		config:      config,
+		rateLimiter: rate.NewLimiter(rate.Every(config.RateLimit), config.BurstLimit),
+	}
+}
+
+// SetDebug enables or disables debug logging for the client
+func (c *Client) SetDebug(debug bool) {
+	c.config.Debug = debug
+}
+
+// 3. Core HTTP methods (private)
+
+// post sends a single form encoded request to the AcoustID API
+func (c *Client) post(ctx context.Context, path string, form url.Values) ([]byte, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Handle network timeouts
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return nil, &shared.HTTPError{
+				StatusCode: http.StatusGatewayTimeout,
+				Status:     "Gateway Timeout",
+				Message:    err.Error(),
+			}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		message := string(body)
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return nil, &shared.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Message:    message,
+		}
+	}
+
+	return body, nil
+}
+
+// postWithRetry sends a request with retry logic
+func (c *Client) postWithRetry(ctx context.Context, path string, form url.Values) ([]byte, error) {
+	var result []byte
+	var err error
+
+	retryErr := shared.RetryWithBackoffForHTTPWithDebug(
+		c.config.MaxRetries,
+		c.config.InitialDelay,
+		c.config.MaxDelay,
+		func() error {
+			result, err = c.post(ctx, path, form)
+			return err
+		},
+		c.config.Debug,
+	)
+
+	if retryErr != nil {
+		return nil, retryErr
+	}
+	return result, nil
+}
+
+// 4. Public API methods
+
+// Lookup identifies a Chromaprint fingerprint and returns the matches ordered by descending score
+func (c *Client) Lookup(ctx context.Context, fingerprint string, duration int) ([]Result, error) {
+	if fingerprint == "" {
+		return nil, fmt.Errorf("fingerprint cannot be empty")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+
+	form := url.Values{}
+	form.Set("client", c.config.APIKey)
+	form.Set("format", "json")
+	form.Set("meta", "recordings")
+	form.Set("duration", strconv.Itoa(duration))
+	form.Set("fingerprint", fingerprint)
+
+	body, err := c.postWithRetry(ctx, "lookup", form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up fingerprint: %w", err)
+	}
+
+	var response LookupResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lookup response: %w", err)
+	}
+
+	if response.Status != "ok" {
+		if response.Error != nil {
+			return nil, fmt.Errorf("acoustid error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		return nil, fmt.Errorf("unexpected acoustid status: %s", response.Status)
+	}
+
+	return response.Results, nil
+}
+
+// BestRecording returns the highest scoring result that links to at least one MusicBrainz recording
+func BestRecording(results []Result) (*Recording, float64, bool) {
+	var best *Recording
+	bestScore := -1.0
+	for i := range results {
+		if len(results[i].Recordings) == 0 || results[i].Score <= bestScore {
+			continue
+		}
+		best = &results[i].Recordings[0]
+		bestScore = results[i].Score
+	}
+	if best == nil {
+		return nil, 0, false
+	}
+	return best, bestScore, true
+}
+
+// Data types
+
+// Recording represents a MusicBrainz recording linked to an AcoustID
+type Recording struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Duration int    `json:"duration"`
+}
+
+// Result represents a single AcoustID match
+type Result struct {
+	ID         string      `json:"id"`
+	Score      float64     `json:"score"` // Match confidence between 0 and 1
+	Recordings []Recording `json:"recordings"`
+}
+
+// APIError represents an error returned in the AcoustID response body
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// LookupResponse represents the AcoustID lookup response
+type LookupResponse struct {
+	Status  string    `json:"status"`
+	Results []Result  `json:"results"`
+	Error   *APIError `json:"error,omitempty"`
+}
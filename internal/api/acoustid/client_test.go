@@ -0,0 +1,89 @@
+package acoustid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newStubClient creates a client pointed at a local AcoustID stub
+func newStubClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.MaxRetries = 1
+	config.InitialDelay = 10 * time.Millisecond
+	config.MaxDelay = 10 * time.Millisecond
+	config.RateLimit = time.Millisecond
+	return NewClientWithConfig(config)
+}
+
+func TestLookup(t *testing.T) {
+	client := newStubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/lookup" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if r.Form.Get("client") != "test-key" || r.Form.Get("fingerprint") != "AQAAtest" || r.Form.Get("duration") != "215" {
+			t.Errorf("Unexpected form values: %v", r.Form)
+		}
+		if r.Form.Get("meta") != "recordings" {
+			t.Errorf("Expected recordings meta, got %q", r.Form.Get("meta"))
+		}
+		w.Write([]byte(`{"status":"ok","results":[
+			{"id":"a1","score":0.42,"recordings":[{"id":"rec-low"}]},
+			{"id":"a2","score":0.97,"recordings":[{"id":"rec-high","title":"Song"}]},
+			{"id":"a3","score":0.99}
+		]}`))
+	})
+
+	results, err := client.Lookup(context.Background(), "AQAAtest", 215)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	recording, score, ok := BestRecording(results)
+	if !ok {
+		t.Fatal("Expected a recording match")
+	}
+	if recording.ID != "rec-high" || score != 0.97 {
+		t.Errorf("Expected rec-high with score 0.97, got %s with %.2f", recording.ID, score)
+	}
+}
+
+func TestLookupAPIError(t *testing.T) {
+	client := newStubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"error","error":{"code":4,"message":"invalid API key"}}`))
+	})
+
+	if _, err := client.Lookup(context.Background(), "AQAAtest", 215); err == nil {
+		t.Error("Expected an error for an error status")
+	}
+}
+
+func TestLookupValidation(t *testing.T) {
+	client := NewClient("test-key")
+	if _, err := client.Lookup(context.Background(), "", 215); err == nil {
+		t.Error("Expected an error for an empty fingerprint")
+	}
+	if _, err := client.Lookup(context.Background(), "AQAAtest", 0); err == nil {
+		t.Error("Expected an error for a zero duration")
+	}
+}
+
+func TestBestRecordingNoRecordings(t *testing.T) {
+	if _, _, ok := BestRecording([]Result{{ID: "a1", Score: 0.9}}); ok {
+		t.Error("Expected no match when results have no recordings")
+	}
+}
@@ -33,6 +33,9 @@ const (
 	rateLimitThreshold   = 10 // Adjust rate limit after this many consecutive 429s
 )
 
+// StreamQuality is the quality tier requested for every stream, the highest quality FLAC
+const StreamQuality = "27"
+
 // Fibonacci sequence for backoff delays
 var fibonacciSequence = []int{1, 2, 3, 5, 8, 13, 21, 34}
 
@@ -158,6 +161,9 @@ func (api *DabAPI) requestWithRetry(ctx context.Context, url string) (*http.Resp
 		resp, err := api.executeRequest(ctx, url)
 		if err != nil {
 			lastErr = err
+			if ctx.Err() != nil {
+				return nil, err // Cancelled, don't retry
+			}
 			if attempt < maxRetries-1 {
 				api.waitWithBackoff(attempt, baseRetryDelay)
 				continue
@@ -451,7 +457,7 @@ func (api *DabAPI) GetStreamURL(ctx context.Context, trackID string) (string, er
 	err := shared.RetryWithBackoff(shared.DefaultMaxRetries, 1, func() error {
 		resp, err := api.Request(ctx, "api/stream", true, []shared.QueryParam{
 			{Name: "trackId", Value: trackID},
-			{Name: "quality", Value: "27"}, // Highest quality FLAC
+			{Name: "quality", Value: StreamQuality},
 		})
 		if err != nil {
 			return fmt.Errorf("failed to get stream URL: %w", err)
@@ -488,6 +494,11 @@ func (api *DabAPI) DownloadCover(ctx context.Context, coverURL string) ([]byte,
 
 // Search searches for artists, albums, or tracks.
 func (api *DabAPI) Search(ctx context.Context, query string, searchType string, limit int, debug bool) (*shared.SearchResults, error) {
+	return api.SearchPage(ctx, query, searchType, limit, 0, debug)
+}
+
+// SearchPage searches like Search, skipping the first offset results of each type.
+func (api *DabAPI) SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error) {
 	results := &shared.SearchResults{}
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -509,6 +520,9 @@ func (api *DabAPI) Search(ctx context.Context, query string, searchType string,
 				{Name: "type", Value: t},
 				{Name: "limit", Value: strconv.Itoa(limit)},
 			}
+			if offset > 0 {
+				params = append(params, shared.QueryParam{Name: "offset", Value: strconv.Itoa(offset)})
+			}
 			resp, err := api.Request(ctx, "api/search", true, params)
 			if err != nil {
 				errChan <- err
@@ -680,6 +694,13 @@ func (api *DabAPI) processAlbumInParallel(ctx context.Context, album *shared.Alb
 			album.TotalTracks = fullAlbum.TotalTracks
 			album.TotalDiscs = fullAlbum.TotalDiscs
 			album.Year = fullAlbum.Year
+			if album.UPC == "" {
+				album.UPC = fullAlbum.UPC
+			}
+			album.ParentalWarning = album.ParentalWarning || fullAlbum.ParentalWarning
+			if album.Label == nil {
+				album.Label = fullAlbum.Label
+			}
 		}
 	}
 
@@ -197,7 +197,7 @@ func (c *Client) GetTrackMetadata(ctx context.Context, mbid string) (*Track, err
 		return nil, fmt.Errorf("MBID cannot be empty")
 	}
 
-	path := fmt.Sprintf("recording/%s?inc=artists+releases+url-rels", mbid)
+	path := fmt.Sprintf("recording/%s?inc=artists+releases+release-groups+media+url-rels", mbid)
 	body, err := c.getWithRetry(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch track metadata for MBID %s: %w", mbid, err)
@@ -216,7 +216,7 @@ func (c *Client) GetReleaseMetadata(ctx context.Context, mbid string) (*Release,
 		return nil, fmt.Errorf("MBID cannot be empty")
 	}
 
-	path := fmt.Sprintf("release/%s?inc=artists+labels+recordings+url-rels+release-groups", mbid)
+	path := fmt.Sprintf("release/%s?inc=artists+artist-credits+labels+recordings+url-rels+release-groups", mbid)
 	body, err := c.getWithRetry(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch release metadata for MBID %s: %w", mbid, err)
@@ -329,21 +329,32 @@ func buildTrackSearchQuery(artist, album, title string) string {
 
 // Artist represents a MusicBrainz artist
 type Artist struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	SortName string `json:"sort-name"`
 }
 
 // ArtistCredit represents artist credit information
 type ArtistCredit struct {
-	Artist Artist `json:"artist"`
+	Name       string `json:"name"`       // Name as credited on the release, may differ from Artist.Name
+	JoinPhrase string `json:"joinphrase"` // Text joining this credit to the next one (e.g. " feat. ")
+	Artist     Artist `json:"artist"`
+}
+
+// Recording represents the recording a media track points to
+type Recording struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
 }
 
 // MediaTrack represents a track within media
 type MediaTrack struct {
-	ID     string `json:"id"`
-	Number string `json:"number"`
-	Title  string `json:"title"`
-	Length int    `json:"length"`
+	ID           string         `json:"id"`
+	Number       string         `json:"number"`
+	Title        string         `json:"title"`
+	Length       int            `json:"length"`
+	Recording    Recording      `json:"recording"`
+	ArtistCredit []ArtistCredit `json:"artist-credit"`
 }
 
 // Disc represents a disc within media
@@ -353,14 +364,18 @@ type Disc struct {
 
 // Media represents media information
 type Media struct {
-	Format string       `json:"format"`
-	Discs  []Disc       `json:"discs"`
-	Tracks []MediaTrack `json:"tracks"`
+	Format     string       `json:"format"`
+	TrackCount int          `json:"track-count"`
+	Discs      []Disc       `json:"discs"`
+	Tracks     []MediaTrack `json:"tracks"`
 }
 
 // ReleaseGroup represents a MusicBrainz release group
 type ReleaseGroup struct {
-	ID string `json:"id"`
+	ID               string   `json:"id"`
+	PrimaryType      string   `json:"primary-type"`
+	SecondaryTypes   []string `json:"secondary-types"`
+	FirstReleaseDate string   `json:"first-release-date"`
 }
 
 // TrackRelease represents release information within a track
@@ -368,6 +383,9 @@ type TrackRelease struct {
 	ID           string         `json:"id"`
 	Title        string         `json:"title"`
 	Date         string         `json:"date"`
+	Country      string         `json:"country"`
+	Status       string         `json:"status"`
+	TrackCount   int            `json:"track-count"`
 	ArtistCredit []ArtistCredit `json:"artist-credit"`
 	ReleaseGroup ReleaseGroup   `json:"release-group"`
 	Media        []Media        `json:"media"`
@@ -15,66 +15,19 @@ import (
 	subsonic "github.com/delucks/go-subsonic"
 )
 
-// Authenticate authenticates the client with the navidrome api
+// Authenticate verifies the credentials against the navidrome api. Every request is
+// authenticated by the transport, so this only needs to run once to fail early.
 func (n *NavidromeClient) Authenticate() error {
-	// Ping the server to get the salt
-	pingURL := fmt.Sprintf("%s/rest/ping.view?v=1.16.1&c=dab-downloader&f=json", n.URL)
-	resp, err := http.Get(pingURL)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	var pingResponse struct {
-		SubsonicResponse struct {
-			Status string `json:"status"`
-			Salt   string `json:"salt"`
-		} `json:"subsonic-response"`
-	}
-
-	if err := json.Unmarshal(body, &pingResponse); err != nil {
-		return err
-	}
-
-	if pingResponse.SubsonicResponse.Status != "ok" {
-		// Try with auth
-		pingURL = fmt.Sprintf("%s/rest/ping.view?u=%s&p=%s&v=1.16.1&c=dab-downloader&f=json", n.URL, n.Username, n.Password)
-		resp, err = http.Get(pingURL)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-
-		body, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-
-		if err := json.Unmarshal(body, &pingResponse); err != nil {
-			return err
-		}
-
-		if pingResponse.SubsonicResponse.Status != "ok" {
-			return fmt.Errorf("ping failed: %s", pingResponse.SubsonicResponse.Status)
-		}
+	if _, err := n.get("ping", nil); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
 	}
+	n.authenticated = true
+	return nil
+}
 
-	n.Salt = pingResponse.SubsonicResponse.Salt
-	n.Token = getSaltedPassword(n.Password, n.Salt)
-
-	n.Client = subsonic.Client{
-		Client:       http.DefaultClient,
-		BaseUrl:      n.URL,
-		User:         n.Username,
-		ClientName:   "dab-downloader",
-		PasswordAuth: true,
-	}
-	return n.Client.Authenticate(n.Password)
+// IsAuthenticated reports whether Authenticate succeeded
+func (n *NavidromeClient) IsAuthenticated() bool {
+	return n.authenticated
 }
 
 // SearchTrack searches for a track on the navidrome server
@@ -179,41 +132,35 @@ func (n *NavidromeClient) SearchAlbum(albumName string, artistName string) (*sub
 	return nil, nil // Album not found
 }
 
-// CreatePlaylist creates a new playlist on the navidrome server
-func (n *NavidromeClient) CreatePlaylist(name string) error {
-	// Use url.Values to properly encode the playlist name
-	data := url.Values{}
-	data.Set("name", name)
-
-	// Construct the URL for creating the playlist
-	createURL := fmt.Sprintf("%s/rest/createPlaylist.view?%s&u=%s&t=%s&s=%s&v=1.16.1&c=dab-downloader&f=json",
-		n.URL, data.Encode(), n.Username, n.Token, n.Salt)
+// CreatePlaylist creates a new playlist on the navidrome server and returns its ID
+func (n *NavidromeClient) CreatePlaylist(name string) (string, error) {
+	params := url.Values{}
+	params.Set("name", name)
 
-	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", createURL, nil)
+	response, err := n.get("createPlaylist", params)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create playlist: %w", err)
 	}
 
-	// Execute the request
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+	if response.Playlist != nil && response.Playlist.ID != "" {
+		return response.Playlist.ID, nil
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := ioutil.ReadAll(resp.Body)
+	// Servers implementing API versions before 1.14 return an empty response
+	playlistID, err := n.SearchPlaylist(name)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return "", fmt.Errorf("playlist created but its ID could not be found: %w", err)
 	}
+	return playlistID, nil
+}
 
-	// Check the response status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to create playlist: status code %d, body: %s", resp.StatusCode, string(body))
+// GetPlaylists returns the playlists visible to the user
+func (n *NavidromeClient) GetPlaylists() ([]Playlist, error) {
+	response, err := n.get("getPlaylists", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlists: %w", err)
 	}
-
-	return nil
+	return response.Playlists.Playlist, nil
 }
 
 // UpdatePlaylist updates a playlist
@@ -239,74 +186,58 @@ func (n *NavidromeClient) UpdatePlaylist(playlistID string, name *string, commen
 func (n *NavidromeClient) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
 	params := url.Values{}
 	params.Add("playlistId", playlistID)
-	params.Add("u", n.Username)
-	params.Add("t", n.Token)
-	params.Add("s", n.Salt)
-	params.Add("v", "1.16.1")
-	params.Add("c", "dab-downloader")
-	params.Add("f", "json")
-
 	for _, songID := range trackIDs {
 		params.Add("songIdToAdd", songID)
 	}
 
-	updateURL := fmt.Sprintf("%s/rest/updatePlaylist.view?%s", n.URL, params.Encode())
-
-	log.Printf("Calling update playlist URL: %s", updateURL)
-
-	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", updateURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if _, err := n.get("updatePlaylist", params); err != nil {
+		return fmt.Errorf("failed to update playlist: %w", err)
 	}
+	return nil
+}
 
-	// Execute the request
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
+// GetPlaylistTracks returns the tracks in a playlist in playlist order. A missing playlist
+// is reported as an *APIError with code ErrorCodeNotFound.
+func (n *NavidromeClient) GetPlaylistTracks(playlistID string) ([]Song, error) {
+	params := url.Values{}
+	params.Set("id", playlistID)
 
-	// Read the response body
-	body, err := ioutil.ReadAll(resp.Body)
+	response, err := n.get("getPlaylist", params)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
 	}
-
-	// Check the response status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update playlist: status code %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	// Unmarshal the response to check for Subsonic errors
-	var subsonicResponse struct {
-		SubsonicResponse struct {
-			Status string `json:"status"`
-			Error  struct {
-				Code    int    `json:"code"`
-				Message string `json:"message"`
-			} `json:"error"`
-		} `json:"subsonic-response"`
+	if response.Playlist == nil {
+		return nil, nil
 	}
+	return response.Playlist.Entry, nil
+}
 
-	if err := json.Unmarshal(body, &subsonicResponse); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+// RemoveTracksFromPlaylist removes the tracks at the given zero-based positions
+func (n *NavidromeClient) RemoveTracksFromPlaylist(playlistID string, indexes []int) error {
+	params := url.Values{}
+	params.Add("playlistId", playlistID)
+	for _, index := range indexes {
+		params.Add("songIndexToRemove", strconv.Itoa(index))
 	}
 
-	if subsonicResponse.SubsonicResponse.Status == "failed" {
-		return fmt.Errorf("failed to update playlist: %s (code %d)", subsonicResponse.SubsonicResponse.Error.Message, subsonicResponse.SubsonicResponse.Error.Code)
+	if _, err := n.get("updatePlaylist", params); err != nil {
+		return fmt.Errorf("failed to remove tracks from playlist: %w", err)
 	}
-
 	return nil
 }
 
-// GetPlaylistTracks returns the tracks in a playlist
-func (n *NavidromeClient) GetPlaylistTracks(playlistID string) ([]*subsonic.Child, error) {
-	playlist, err := n.Client.GetPlaylist(playlistID)
-	if err != nil {
-		return nil, err
+// SetPlaylistTracks replaces the tracks of a playlist, which is how Subsonic reorders one
+func (n *NavidromeClient) SetPlaylistTracks(playlistID string, songIDs []string) error {
+	params := url.Values{}
+	params.Add("playlistId", playlistID)
+	for _, songID := range songIDs {
+		params.Add("songId", songID)
+	}
+
+	if _, err := n.get("createPlaylist", params); err != nil {
+		return fmt.Errorf("failed to replace playlist tracks: %w", err)
 	}
-	return playlist.Entry, nil
+	return nil
 }
 
 // SearchPlaylist searches for a playlist by name and returns its ID
@@ -325,6 +256,49 @@ func (n *NavidromeClient) SearchPlaylist(playlistName string) (string, error) {
 	return "", fmt.Errorf("playlist '%s' not found", playlistName)
 }
 
+// get calls a Subsonic JSON endpoint and returns the response envelope. The transport adds the credentials.
+func (n *NavidromeClient) get(endpoint string, params url.Values) (*responseBody, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("v", apiVersion)
+	query.Set("c", clientName)
+	query.Set("f", "json")
+
+	requestURL := fmt.Sprintf("%s/rest/%s.view?%s", strings.TrimSuffix(n.URL, "/"), endpoint, query.Encode())
+	resp, err := n.httpClient.Get(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var envelope struct {
+		SubsonicResponse responseBody `json:"subsonic-response"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	response := &envelope.SubsonicResponse
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	if response.Status != "ok" {
+		return nil, fmt.Errorf("unexpected response status %q", response.Status)
+	}
+	return response, nil
+}
+
 // getSaltedPassword returns the salted password for navidrome
 func getSaltedPassword(password string, salt string) string {
 	hasher := md5.New()
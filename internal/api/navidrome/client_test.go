@@ -0,0 +1,434 @@
+package navidrome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dab-downloader/internal/shared"
+)
+
+// fakeSubsonic is a minimal Subsonic server holding a song library and playlists
+type fakeSubsonic struct {
+	mu        sync.Mutex
+	password  string
+	songs     []Song
+	playlists []Playlist
+	unscanned []Song // Files on disk that appear in songs once a scan finishes
+	scanPolls int    // getScanStatus calls left before the running scan finishes
+	apiKey    string
+	salts     []string
+	failures  int // Requests answered with 503 before the server recovers
+	stalls    int // Requests answered only after stallDelay
+}
+
+// stallDelay is how long a stalled request waits before it is answered
+const stallDelay = 300 * time.Millisecond
+
+func (f *fakeSubsonic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	stalled := f.stalls > 0
+	if stalled {
+		f.stalls--
+	}
+	f.mu.Unlock()
+	if stalled {
+		time.Sleep(stallDelay)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+	endpoint := strings.TrimSuffix(path.Base(r.URL.Path), ".view")
+
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if query.Has("p") || (query.Has("apiKey") && query.Has("u")) {
+		f.write(w, query.Get("f"), map[string]interface{}{"status": "failed", "error": map[string]interface{}{"code": 43, "message": "Multiple conflicting authentication mechanisms provided"}})
+		return
+	}
+	f.salts = append(f.salts, query.Get("s"))
+	authenticated := query.Get("t") == getSaltedPassword(f.password, query.Get("s"))
+	if query.Has("apiKey") {
+		authenticated = f.apiKey != "" && query.Get("apiKey") == f.apiKey
+	}
+	if !authenticated {
+		f.write(w, query.Get("f"), map[string]interface{}{"status": "failed", "error": map[string]interface{}{"code": 40, "message": "Wrong username or password"}})
+		return
+	}
+
+	response := map[string]interface{}{"status": "ok"}
+	switch endpoint {
+	case "ping":
+	case "createPlaylist":
+		if playlistID := query.Get("playlistId"); playlistID != "" {
+			playlist := f.playlist(playlistID)
+			playlist.Entry = nil
+			for _, songID := range query["songId"] {
+				playlist.Entry = append(playlist.Entry, Song{ID: songID})
+			}
+			response["playlist"] = *playlist
+			break
+		}
+		playlist := Playlist{ID: "pl-" + query.Get("name"), Name: query.Get("name")}
+		f.playlists = append(f.playlists, playlist)
+		response["playlist"] = playlist
+	case "getPlaylist":
+		playlist := f.playlist(query.Get("id"))
+		if playlist == nil {
+			response = map[string]interface{}{"status": "failed", "error": map[string]interface{}{"code": ErrorCodeNotFound, "message": "Playlist not found"}}
+			break
+		}
+		response["playlist"] = *playlist
+	case "getPlaylists":
+		response["playlists"] = map[string]interface{}{"playlist": f.playlists}
+	case "search3":
+		var songs []Song
+		for _, song := range f.songs {
+			if strings.Contains(shared.NormalizeText(song.Title+" "+song.Artist), shared.NormalizeText(query.Get("query"))) {
+				songs = append(songs, song)
+			}
+		}
+		response["searchResult3"] = map[string]interface{}{"song": songs}
+	case "updatePlaylist":
+		playlist := f.playlist(query.Get("playlistId"))
+		remove := make(map[int]bool)
+		for _, index := range query["songIndexToRemove"] {
+			position, _ := strconv.Atoi(index)
+			remove[position] = true
+		}
+		var kept []Song
+		for i, song := range playlist.Entry {
+			if !remove[i] {
+				kept = append(kept, song)
+			}
+		}
+		playlist.Entry = kept
+		for _, songID := range query["songIdToAdd"] {
+			playlist.Entry = append(playlist.Entry, Song{ID: songID})
+		}
+	case "startScan":
+		f.scanPolls = 2
+		response["scanStatus"] = ScanStatus{Scanning: true}
+	case "getScanStatus":
+		if f.scanPolls > 0 {
+			f.scanPolls--
+			if f.scanPolls == 0 {
+				f.songs = append(f.songs, f.unscanned...)
+				f.unscanned = nil
+			}
+		}
+		response["scanStatus"] = ScanStatus{Scanning: f.scanPolls > 0, Count: len(f.songs)}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	f.write(w, query.Get("f"), response)
+}
+
+// playlist returns the playlist with the given ID, or nil
+func (f *fakeSubsonic) playlist(playlistID string) *Playlist {
+	for i := range f.playlists {
+		if f.playlists[i].ID == playlistID {
+			return &f.playlists[i]
+		}
+	}
+	return nil
+}
+
+// write answers in the requested format. go-subsonic only speaks XML, so its ping gets a bare status.
+func (f *fakeSubsonic) write(w http.ResponseWriter, format string, response map[string]interface{}) {
+	if format == "xml" {
+		if response["status"] == "ok" {
+			w.Write([]byte(`<subsonic-response xmlns="http://subsonic.org/restapi" status="ok" version="1.16.1"></subsonic-response>`))
+		} else {
+			w.Write([]byte(`<subsonic-response xmlns="http://subsonic.org/restapi" status="failed" version="1.16.1"><error code="40" message="Wrong username or password"></error></subsonic-response>`))
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"subsonic-response": response})
+}
+
+func newFakeSubsonic(t *testing.T) (*fakeSubsonic, *NavidromeClient) {
+	t.Helper()
+	fake := &fakeSubsonic{
+		password: "secret",
+		songs: []Song{
+			{ID: "s1", Title: "Cinnamon Girl", Artist: "Lana Del Rey", Album: "Norman Fucking Rockwell!"},
+			{ID: "s2", Title: "Cinnamon Girl", Artist: "Neil Young", Album: "Everybody Knows This Is Nowhere", ISRC: []string{"USRE10000001"}},
+			{ID: "s3", Title: "Mariners Apartment Complex", Artist: "Lana Del Rey", Album: "Norman Fucking Rockwell!", MusicBrainzID: "mbid-3"},
+		},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, NewNavidromeClient(server.URL, "user", "secret")
+}
+
+func TestAuthenticate(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Expected authentication to succeed, got %v", err)
+	}
+	if !client.IsAuthenticated() {
+		t.Error("Expected the client to be authenticated")
+	}
+
+	// go-subsonic requests are authenticated by the same transport
+	if _, err := client.Client.Get("ping", nil); err != nil {
+		t.Fatalf("Expected go-subsonic request to succeed, got %v", err)
+	}
+	if len(fake.salts) != 2 || fake.salts[0] == "" || fake.salts[0] == fake.salts[1] {
+		t.Errorf("Expected a fresh salt per request, got %v", fake.salts)
+	}
+
+	wrong := NewNavidromeClient(client.URL, "user", "wrong")
+	err := wrong.Authenticate()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 40 {
+		t.Errorf("Expected a Subsonic error 40, got %v", err)
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	fake.apiKey = "key-123"
+
+	options := DefaultClientOptions()
+	options.APIKey = "key-123"
+	client = NewNavidromeClientWithOptions(client.URL, "user", "", options)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Expected API key authentication to succeed, got %v", err)
+	}
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	fake.failures = 2
+
+	options := DefaultClientOptions()
+	options.InitialDelay = time.Millisecond
+	client = NewNavidromeClientWithOptions(client.URL, "user", "secret", options)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Expected the request to succeed after retries, got %v", err)
+	}
+
+	fake.failures = 10
+	if err := client.Authenticate(); err == nil {
+		t.Error("Expected an error once the retries are exhausted")
+	}
+}
+
+func TestDoesNotRetryPlaylistChanges(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	options := DefaultClientOptions()
+	options.InitialDelay = time.Millisecond
+	client = NewNavidromeClientWithOptions(client.URL, "user", "secret", options)
+
+	fake.failures = 1
+	if _, err := client.CreatePlaylist("Mix"); err == nil {
+		t.Error("Expected createPlaylist to fail without a retry")
+	}
+	if fake.failures != 0 || len(fake.playlists) != 0 {
+		t.Errorf("Expected a single createPlaylist attempt, got %d failures left and %d playlists", fake.failures, len(fake.playlists))
+	}
+}
+
+func TestTimeoutAppliesPerAttempt(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	options := DefaultClientOptions()
+	options.Timeout = stallDelay / 3
+	options.InitialDelay = time.Millisecond
+	client = NewNavidromeClientWithOptions(client.URL, "user", "secret", options)
+
+	// Two stalled attempts together take longer than one timeout, the third attempt succeeds
+	fake.stalls = 2
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Expected the request to succeed after timed out attempts, got %v", err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	redacted := RedactURL("https://music.example.com/rest/ping.view?u=user&t=abc123&s=salt&p=secret&apiKey=key&f=json")
+	for _, secret := range []string{"abc123", "salt", "secret", "key&"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("Expected %q to be redacted from %s", secret, redacted)
+		}
+	}
+	if !strings.Contains(redacted, "u=user") || !strings.Contains(redacted, "f=json") {
+		t.Errorf("Expected other parameters to be kept, got %s", redacted)
+	}
+}
+
+func TestCreatePlaylistReturnsID(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authentication failed: %v", err)
+	}
+
+	playlistID, err := client.CreatePlaylist("Favourites")
+	if err != nil {
+		t.Fatalf("Failed to create playlist: %v", err)
+	}
+	if playlistID != "pl-Favourites" {
+		t.Errorf("Expected playlist ID pl-Favourites, got %q", playlistID)
+	}
+
+	if err := client.AddTracksToPlaylist(playlistID, []string{"s1", "s3"}); err != nil {
+		t.Fatalf("Failed to add tracks: %v", err)
+	}
+	if entries := fake.playlists[0].Entry; len(entries) != 2 || entries[1].ID != "s3" {
+		t.Errorf("Expected songs s1 and s3 in playlist, got %+v", entries)
+	}
+
+	playlists, err := client.GetPlaylists()
+	if err != nil || len(playlists) != 1 || playlists[0].Name != "Favourites" {
+		t.Errorf("Expected the created playlist to be listed, got %+v (%v)", playlists, err)
+	}
+}
+
+func TestEditPlaylistTracks(t *testing.T) {
+	_, client := newFakeSubsonic(t)
+	playlistID, err := client.CreatePlaylist("Mix")
+	if err != nil {
+		t.Fatalf("Failed to create playlist: %v", err)
+	}
+
+	songIDs := func() []string {
+		songs, err := client.GetPlaylistTracks(playlistID)
+		if err != nil {
+			t.Fatalf("Failed to get playlist tracks: %v", err)
+		}
+		var ids []string
+		for _, song := range songs {
+			ids = append(ids, song.ID)
+		}
+		return ids
+	}
+
+	if err := client.AddTracksToPlaylist(playlistID, []string{"s1", "s2", "s3"}); err != nil {
+		t.Fatalf("Failed to add tracks: %v", err)
+	}
+	if err := client.RemoveTracksFromPlaylist(playlistID, []int{1}); err != nil {
+		t.Fatalf("Failed to remove tracks: %v", err)
+	}
+	if ids := songIDs(); strings.Join(ids, ",") != "s1,s3" {
+		t.Errorf("Expected s1,s3 after removal, got %v", ids)
+	}
+
+	if err := client.SetPlaylistTracks(playlistID, []string{"s3", "s2", "s1"}); err != nil {
+		t.Fatalf("Failed to replace tracks: %v", err)
+	}
+	if ids := songIDs(); strings.Join(ids, ",") != "s3,s2,s1" {
+		t.Errorf("Expected s3,s2,s1 after reordering, got %v", ids)
+	}
+
+	_, err = client.GetPlaylistTracks("missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeNotFound {
+		t.Errorf("Expected a not found error for a missing playlist, got %v", err)
+	}
+}
+
+func TestFindSong(t *testing.T) {
+	_, client := newFakeSubsonic(t)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authentication failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		track    shared.Track
+		expected string
+	}{
+		{"ISRC wins over artist", shared.Track{Title: "Cinnamon Girl", Artist: "Lana Del Rey", ISRC: "USRE10000001"}, "s2"},
+		{"MusicBrainz ID", shared.Track{Title: "Mariners Apartment Complex", Artist: "Someone Else", MusicBrainzID: "mbid-3"}, "s3"},
+		{"Fuzzy title and artist", shared.Track{Title: "Cinnamon Girl", Artist: "Neil Young", Album: "Everybody Knows This Is Nowhere (Remastered)"}, "s2"},
+		{"Punctuation differences", shared.Track{Title: "Mariner's Apartment Complex", Artist: "Lana Del Rey"}, "s3"},
+		{"No match", shared.Track{Title: "Video Games", Artist: "Lana Del Rey"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			song, err := client.FindSong(test.track)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			switch {
+			case test.expected == "" && song != nil:
+				t.Errorf("Expected no match, got %s", song.ID)
+			case test.expected != "" && (song == nil || song.ID != test.expected):
+				t.Errorf("Expected %s, got %+v", test.expected, song)
+			}
+		})
+	}
+}
+
+func TestMatchTracksReportsUnmatched(t *testing.T) {
+	_, client := newFakeSubsonic(t)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authentication failed: %v", err)
+	}
+
+	songIDs, unmatched, err := client.MatchTracks([]shared.Track{
+		{Title: "Cinnamon Girl", Artist: "Lana Del Rey"},
+		{Title: "Video Games", Artist: "Lana Del Rey"},
+	})
+	if err != nil {
+		t.Fatalf("Matching failed: %v", err)
+	}
+	if len(songIDs) != 1 || songIDs[0] != "s1" {
+		t.Errorf("Expected song s1, got %v", songIDs)
+	}
+	if len(unmatched) != 1 || unmatched[0].Title != "Video Games" {
+		t.Errorf("Expected Video Games to be unmatched, got %+v", unmatched)
+	}
+}
+
+func TestRescanLibrary(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	fake.unscanned = []Song{{ID: "s4", Title: "Video Games", Artist: "Lana Del Rey"}}
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authentication failed: %v", err)
+	}
+
+	status, err := client.RescanLibrary(context.Background(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("Rescan failed: %v", err)
+	}
+	if status.Scanning || status.Count != 4 {
+		t.Errorf("Expected a finished scan with 4 songs, got %+v", status)
+	}
+
+	song, err := client.FindSong(shared.Track{Title: "Video Games", Artist: "Lana Del Rey"})
+	if err != nil || song == nil || song.ID != "s4" {
+		t.Errorf("Expected the scanned song to be found, got %+v (%v)", song, err)
+	}
+}
+
+func TestWaitForScanTimeout(t *testing.T) {
+	fake, client := newFakeSubsonic(t)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authentication failed: %v", err)
+	}
+	fake.scanPolls = 1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.WaitForScan(ctx, time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to time out, got %v", err)
+	}
+}
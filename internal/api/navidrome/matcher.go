@@ -0,0 +1,148 @@
+package navidrome
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"dab-downloader/internal/shared"
+)
+
+// MinMatchScore is the lowest fuzzy score accepted when no identifier matches
+const MinMatchScore = 0.7
+
+// UnmatchedTracksError lists the tracks that could not be found in the library
+type UnmatchedTracksError struct {
+	Tracks []shared.Track
+}
+
+func (e *UnmatchedTracksError) Error() string {
+	names := make([]string, 0, len(e.Tracks))
+	for _, track := range e.Tracks {
+		names = append(names, fmt.Sprintf("%s - %s", track.Artist, track.Title))
+	}
+	return fmt.Sprintf("%d tracks not found in Navidrome: %s", len(e.Tracks), strings.Join(names, ", "))
+}
+
+// SearchSongs runs a search3 query for songs
+func (n *NavidromeClient) SearchSongs(query string, count int) ([]Song, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("songCount", fmt.Sprintf("%d", count))
+	params.Set("albumCount", "0")
+	params.Set("artistCount", "0")
+
+	response, err := n.get("search3", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search songs: %w", err)
+	}
+	return response.SearchResult3.Song, nil
+}
+
+// FindSong finds the library song for a track. A song sharing the track's ISRC or MusicBrainz
+// recording ID wins, otherwise the best fuzzy title/artist/album match above MinMatchScore.
+// Returns nil when nothing matches.
+func (n *NavidromeClient) FindSong(track shared.Track) (*Song, error) {
+	hasIdentifier := track.ISRC != "" || track.MusicBrainzID != ""
+	seen := make(map[string]bool)
+	var candidates []Song
+	for _, query := range []string{track.Title + " " + track.Artist, track.Title} {
+		songs, err := n.SearchSongs(query, 20)
+		if err != nil {
+			return nil, err
+		}
+		for _, song := range songs {
+			if !seen[song.ID] {
+				seen[song.ID] = true
+				candidates = append(candidates, song)
+			}
+		}
+
+		// The broader title search is only needed to find a song carrying the track's identifiers
+		if song := identifierMatch(track, candidates); song != nil {
+			return song, nil
+		}
+		if song := bestSongMatch(track, candidates); song != nil && !hasIdentifier {
+			return song, nil
+		}
+	}
+
+	return bestSongMatch(track, candidates), nil
+}
+
+// MatchTracks resolves tracks to song IDs, returning the tracks that could not be matched
+func (n *NavidromeClient) MatchTracks(tracks []shared.Track) ([]string, []shared.Track, error) {
+	var songIDs []string
+	var unmatched []shared.Track
+	for _, track := range tracks {
+		song, err := n.FindSong(track)
+		if err != nil {
+			return nil, nil, err
+		}
+		if song == nil {
+			unmatched = append(unmatched, track)
+			continue
+		}
+		songIDs = append(songIDs, song.ID)
+	}
+	return songIDs, unmatched, nil
+}
+
+// identifierMatch returns the song sharing the track's MusicBrainz recording ID or ISRC
+func identifierMatch(track shared.Track, songs []Song) *Song {
+	for i, song := range songs {
+		if track.MusicBrainzID != "" && strings.EqualFold(song.MusicBrainzID, track.MusicBrainzID) {
+			return &songs[i]
+		}
+		if track.ISRC != "" {
+			for _, isrc := range song.ISRC {
+				if strings.EqualFold(isrc, track.ISRC) {
+					return &songs[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bestSongMatch picks the song matching a track by identifier, then by fuzzy score
+func bestSongMatch(track shared.Track, songs []Song) *Song {
+	if song := identifierMatch(track, songs); song != nil {
+		return song
+	}
+
+	var best *Song
+	bestScore := 0.0
+	for i, song := range songs {
+		if score := matchScore(track, song); score >= MinMatchScore && score > bestScore {
+			best, bestScore = &songs[i], score
+		}
+	}
+	return best
+}
+
+// matchScore rates how well a song matches a track from 0 to 1. Titles carry half the weight,
+// artists most of the rest, and the album only breaks ties between editions.
+func matchScore(track shared.Track, song Song) float64 {
+	titleScore := similarity(track.Title, song.Title)
+	if titleScore < 0.8 {
+		return 0
+	}
+
+	album := track.Album
+	if album == "" {
+		album = track.AlbumTitle
+	}
+	score := 0.5*titleScore + 0.35*similarity(track.Artist, song.Artist)
+	if album != "" {
+		score += 0.15 * similarity(album, song.Album)
+	} else {
+		score += 0.15
+	}
+	return score
+}
+
+// similarity compares two names with the shared text similarity after normalization
+func similarity(a, b string) float64 {
+	return shared.TextSimilarity(shared.NormalizeText(a), shared.NormalizeText(b))
+}
@@ -0,0 +1,73 @@
+package navidrome
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultScanPollInterval = 2 * time.Second  // Delay between getScanStatus calls
+	DefaultScanTimeout      = 10 * time.Minute // Longest wait for a library scan
+)
+
+// StartScan asks the server to scan its media library for new files
+func (n *NavidromeClient) StartScan() (*ScanStatus, error) {
+	response, err := n.get("startScan", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start scan: %w", err)
+	}
+	return scanStatusOf(response), nil
+}
+
+// GetScanStatus returns the state of the current library scan
+func (n *NavidromeClient) GetScanStatus() (*ScanStatus, error) {
+	response, err := n.get("getScanStatus", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scan status: %w", err)
+	}
+	return scanStatusOf(response), nil
+}
+
+// WaitForScan polls the scan status until the server reports it is no longer scanning
+func (n *NavidromeClient) WaitForScan(ctx context.Context, pollInterval time.Duration) (*ScanStatus, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := n.GetScanStatus()
+		if err != nil {
+			return nil, err
+		}
+		if !status.Scanning {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("library scan did not finish: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// RescanLibrary starts a library scan and waits for it to finish
+func (n *NavidromeClient) RescanLibrary(ctx context.Context, pollInterval time.Duration) (*ScanStatus, error) {
+	status, err := n.StartScan()
+	if err != nil {
+		return nil, err
+	}
+	if !status.Scanning {
+		// Small libraries may finish before startScan returns
+		return status, nil
+	}
+	return n.WaitForScan(ctx, pollInterval)
+}
+
+// scanStatusOf returns the scan status of a response, treating a missing one as idle
+func scanStatusOf(response *responseBody) *ScanStatus {
+	if response.ScanStatus == nil {
+		return &ScanStatus{}
+	}
+	return response.ScanStatus
+}
@@ -0,0 +1,172 @@
+package navidrome
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	clientName = "dab-downloader"
+	apiVersion = "1.16.1"
+)
+
+// credentialParams are the query parameters that carry credentials
+var credentialParams = []string{"p", "t", "s", "apiKey"}
+
+// readOnlyEndpoints can be sent again safely. A repeated createPlaylist or updatePlaylist
+// could create a second playlist or add the tracks twice when the first attempt got through.
+var readOnlyEndpoints = map[string]bool{
+	"ping":          true,
+	"search3":       true,
+	"getPlaylist":   true,
+	"getPlaylists":  true,
+	"getScanStatus": true,
+}
+
+// authTransport authenticates every Subsonic request and retries transient failures of
+// read-only requests. Each request gets a fresh random salt, so a logged or leaked token
+// cannot be replayed.
+type authTransport struct {
+	base         http.RoundTripper
+	username     string
+	password     string
+	apiKey       string
+	timeout      time.Duration // Limit of each attempt, so retries get their own time
+	maxRetries   int
+	initialDelay time.Duration
+	debug        bool
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	maxRetries := t.maxRetries
+	if !isReadOnly(req) {
+		maxRetries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := t.initialDelay * time.Duration(1<<(attempt-1))
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(delay):
+			}
+		}
+
+		authenticated, cancel := t.authenticate(req)
+		if t.debug {
+			log.Printf("Navidrome request (attempt %d): %s", attempt+1, RedactURL(authenticated.URL.String()))
+		}
+
+		resp, err := base.RoundTrip(authenticated)
+		if err == nil && (!isRetryableStatus(resp.StatusCode) || attempt == maxRetries) {
+			// The attempt's timeout also covers reading the body
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("request failed after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// authenticate returns a copy of the request carrying the credentials and limited to one
+// attempt's timeout. Credentials set by the caller, such as go-subsonic's own token, are replaced.
+func (t *authTransport) authenticate(req *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	authenticated := req.Clone(ctx)
+	query := authenticated.URL.Query()
+	for _, param := range credentialParams {
+		query.Del(param)
+	}
+
+	if t.apiKey != "" {
+		// OpenSubsonic rejects an API key combined with a username
+		query.Del("u")
+		query.Set("apiKey", t.apiKey)
+	} else {
+		salt := generateSalt()
+		query.Set("u", t.username)
+		query.Set("t", getSaltedPassword(t.password, salt))
+		query.Set("s", salt)
+	}
+
+	authenticated.URL.RawQuery = query.Encode()
+	return authenticated, cancel
+}
+
+// isReadOnly reports whether a request only reads from the server and can be retried
+func isReadOnly(req *http.Request) bool {
+	endpoint := strings.TrimSuffix(path.Base(req.URL.Path), ".view")
+	return readOnlyEndpoints[endpoint]
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// cancelBody releases the attempt's timeout once the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// RedactURL replaces the credentials in a Subsonic request URL so it can be logged
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "[unparseable URL]"
+	}
+
+	query := parsed.Query()
+	for _, param := range credentialParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	if parsed.User != nil {
+		parsed.User = url.User(parsed.User.Username())
+	}
+	return parsed.String()
+}
+
+// generateSalt returns a random salt for token authentication
+func generateSalt() string {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(salt)
+}
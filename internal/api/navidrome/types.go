@@ -1,24 +1,140 @@
 package navidrome
 
 import (
+	"fmt"
+	"net/http"
+	"time"
+
 	subsonic "github.com/delucks/go-subsonic"
 )
 
 // NavidromeClient holds the navidrome client and other required fields
 type NavidromeClient struct {
-	URL      string
-	Username string
-	Password string
-	Client   subsonic.Client
-	Salt     string
-	Token    string
+	URL           string
+	Username      string
+	Password      string
+	Client        subsonic.Client
+	httpClient    *http.Client
+	authenticated bool
+}
+
+// ClientOptions configures the transport of a navidrome client
+type ClientOptions struct {
+	APIKey       string        // OpenSubsonic API key, sent instead of username and token when set
+	HTTPClient   *http.Client  // Base client whose transport is wrapped, defaults to http.DefaultClient
+	Timeout      time.Duration // Timeout of each attempt when the base client has none
+	MaxRetries   int           // Retries of read-only requests after network errors, 429 and 5xx responses
+	InitialDelay time.Duration // Delay before the first retry, doubled after each attempt
+	Debug        bool          // Log every request with credentials redacted
+}
+
+// DefaultClientOptions returns sensible default transport options
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:      30 * time.Second,
+		MaxRetries:   3,
+		InitialDelay: 500 * time.Millisecond,
+	}
 }
 
 // NewNavidromeClient creates a new navidrome client
 func NewNavidromeClient(url, username, password string) *NavidromeClient {
+	return NewNavidromeClientWithOptions(url, username, password, DefaultClientOptions())
+}
+
+// NewNavidromeClientWithOptions creates a navidrome client whose requests are authenticated with a fresh
+// salt each (or the API key), time out and are retried according to the options
+func NewNavidromeClientWithOptions(url, username, password string, options ClientOptions) *NavidromeClient {
+	base := options.HTTPClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+	timeout := base.Timeout
+	if timeout == 0 {
+		timeout = options.Timeout
+	}
+
+	// The timeout applies to each attempt in the transport, not to all retries together
+	httpClient := &http.Client{
+		CheckRedirect: base.CheckRedirect,
+		Jar:           base.Jar,
+		Transport: &authTransport{
+			base:         base.Transport,
+			username:     username,
+			password:     password,
+			apiKey:       options.APIKey,
+			timeout:      timeout,
+			maxRetries:   options.MaxRetries,
+			initialDelay: options.InitialDelay,
+			debug:        options.Debug,
+		},
+	}
+
 	return &NavidromeClient{
-		URL:      url,
-		Username: username,
-		Password: password,
+		URL:        url,
+		Username:   username,
+		Password:   password,
+		httpClient: httpClient,
+		Client: subsonic.Client{
+			Client:     httpClient,
+			BaseUrl:    url,
+			User:       username,
+			ClientName: clientName,
+		},
 	}
-}
\ No newline at end of file
+}
+
+// Song is a song as returned by the Subsonic JSON API, including the OpenSubsonic
+// isrc and musicBrainzId fields that go-subsonic does not decode
+type Song struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Artist        string   `json:"artist"`
+	Album         string   `json:"album"`
+	Duration      int      `json:"duration"`
+	Track         int      `json:"track"`
+	DiscNumber    int      `json:"discNumber"`
+	MusicBrainzID string   `json:"musicBrainzId"`
+	ISRC          []string `json:"isrc"`
+}
+
+// Playlist is a playlist as returned by the Subsonic JSON API
+type Playlist struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+	Entry     []Song `json:"entry"`
+}
+
+// ScanStatus is the state of the server's media library scan
+type ScanStatus struct {
+	Scanning bool `json:"scanning"`
+	Count    int  `json:"count"`
+}
+
+// ErrorCodeNotFound is the Subsonic error code for missing data such as a deleted playlist
+const ErrorCodeNotFound = 70
+
+// APIError is an error reported in a Subsonic response
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("subsonic error %d: %s", e.Code, e.Message)
+}
+
+// responseBody is the content of the subsonic-response envelope
+type responseBody struct {
+	Status     string      `json:"status"`
+	Error      *APIError   `json:"error"`
+	Playlist   *Playlist   `json:"playlist"`
+	ScanStatus *ScanStatus `json:"scanStatus"`
+	Playlists  struct {
+		Playlist []Playlist `json:"playlist"`
+	} `json:"playlists"`
+	SearchResult3 struct {
+		Song []Song `json:"song"`
+	} `json:"searchResult3"`
+}
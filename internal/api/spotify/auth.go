@@ -0,0 +1,271 @@
+package spotify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dab-downloader/internal/shared"
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultRedirectURL is the local callback address registered in the Spotify app settings
+	DefaultRedirectURL = "http://127.0.0.1:8888/callback"
+	// DefaultTokenFile is where the user login is kept when none is configured
+	DefaultTokenFile = "config/spotify-token.json"
+
+	loginTimeout = 5 * time.Minute
+)
+
+// userScopes are the permissions requested when logging in as a user
+var userScopes = []string{
+	spotifyauth.ScopePlaylistReadPrivate,
+	spotifyauth.ScopePlaylistReadCollaborative,
+	spotifyauth.ScopeUserLibraryRead,
+	spotifyauth.ScopeUserFollowRead,
+}
+
+// AuthenticateUser logs in as a Spotify user, which is needed for private playlists and the
+// user's library. A saved login is reused and refreshed; otherwise the authorization code flow
+// with PKCE runs through the browser and a local callback listener.
+func (s *SpotifyClient) AuthenticateUser(ctx context.Context) error {
+	token, err := s.loadToken()
+	if err != nil {
+		log.Printf("Ignoring saved Spotify login, logging in again: %v", err)
+		token = nil
+	}
+
+	if token != nil {
+		s.useUserToken(ctx, token)
+		if _, err := s.client.CurrentUser(ctx); err == nil {
+			return nil
+		}
+		log.Printf("Saved Spotify login is no longer valid, logging in again")
+	}
+
+	token, err = s.login(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.saveToken(token); err != nil {
+		return err
+	}
+	s.useUserToken(ctx, token)
+	return nil
+}
+
+// IsUserAuthenticated reports whether the client acts on behalf of a user
+func (s *SpotifyClient) IsUserAuthenticated() bool {
+	return s.userAuthenticated
+}
+
+// login runs the authorization code flow with PKCE and returns the user's token
+func (s *SpotifyClient) login(ctx context.Context) (*oauth2.Token, error) {
+	config := s.oauthConfig()
+	redirect, err := url.Parse(config.RedirectURL)
+	if err != nil || redirect.Host == "" {
+		return nil, fmt.Errorf("invalid Spotify redirect URL %q", config.RedirectURL)
+	}
+	callbackPath := redirect.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+
+	verifier, err := randomString(48)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the Spotify callback on %s: %w", redirect.Host, err)
+	}
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+	send := func(result callbackResult) {
+		select {
+		case results <- result:
+		default:
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "Invalid login state", http.StatusBadRequest)
+			send(callbackResult{err: fmt.Errorf("Spotify callback state mismatch")})
+		case query.Get("error") != "":
+			http.Error(w, "Spotify login failed", http.StatusBadRequest)
+			send(callbackResult{err: fmt.Errorf("Spotify login failed: %s", query.Get("error"))})
+		default:
+			fmt.Fprintln(w, "Spotify login complete. You can close this window.")
+			send(callbackResult{code: query.Get("code")})
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+	)
+	fmt.Printf("Open this URL in your browser to log in to Spotify:\n\n%s\n\n", authURL)
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for the Spotify login: %w", ctx.Err())
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := config.Exchange(ctx, result.code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the Spotify authorization code: %w", err)
+	}
+	return token, nil
+}
+
+// useUserToken switches the client to a user token that is refreshed and saved as needed
+func (s *SpotifyClient) useUserToken(ctx context.Context, token *oauth2.Token) {
+	source := &savingTokenSource{
+		base:  s.oauthConfig().TokenSource(ctx, token),
+		save:  s.saveToken,
+		token: token,
+	}
+	s.client = spotify.New(oauth2.NewClient(ctx, source))
+	s.userAuthenticated = true
+}
+
+// oauthConfig returns the OAuth configuration for the user login. PKCE replaces the client
+// secret, so the client ID is sent as a form parameter.
+func (s *SpotifyClient) oauthConfig() *oauth2.Config {
+	redirectURL := s.RedirectURL
+	if redirectURL == "" {
+		redirectURL = DefaultRedirectURL
+	}
+	return &oauth2.Config{
+		ClientID:    s.ID,
+		RedirectURL: redirectURL,
+		Scopes:      userScopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   spotifyauth.AuthURL,
+			TokenURL:  spotifyauth.TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// tokenFile returns the path of the saved user login
+func (s *SpotifyClient) tokenFile() string {
+	if s.TokenFile == "" {
+		return DefaultTokenFile
+	}
+	return s.TokenFile
+}
+
+// loadToken reads the saved user login, returning nil when there is none
+func (s *SpotifyClient) loadToken() (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.tokenFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Spotify token: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse Spotify token: %w", err)
+	}
+	if token.RefreshToken == "" {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// saveToken writes the user login so later runs can refresh it
+func (s *SpotifyClient) saveToken(token *oauth2.Token) error {
+	path := s.tokenFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create Spotify token directory: %w", err)
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Spotify token: %w", err)
+	}
+	if err := shared.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write Spotify token: %w", err)
+	}
+	return nil
+}
+
+// savingTokenSource saves every refreshed token so the login survives restarts
+type savingTokenSource struct {
+	base  oauth2.TokenSource
+	save  func(*oauth2.Token) error
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (ts *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := ts.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == nil || token.AccessToken != ts.token.AccessToken {
+		ts.token = token
+		if err := ts.save(token); err != nil {
+			log.Printf("Failed to save refreshed Spotify token: %v", err)
+		}
+	}
+	return token, nil
+}
+
+// randomString returns a URL-safe random string made from n random bytes
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random data: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// codeChallenge derives the S256 PKCE challenge from a verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package spotify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := codeChallenge(verifier); got != expected {
+		t.Errorf("Expected challenge %s, got %s", expected, got)
+	}
+}
+
+func TestTokenPersistence(t *testing.T) {
+	client := NewSpotifyClient("id", "")
+	client.TokenFile = filepath.Join(t.TempDir(), "spotify-token.json")
+
+	token, err := client.loadToken()
+	if err != nil || token != nil {
+		t.Fatalf("Expected no saved token, got %v, %v", token, err)
+	}
+
+	saved := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	if err := client.saveToken(saved); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	token, err = client.loadToken()
+	if err != nil || token == nil {
+		t.Fatalf("Failed to load token: %v", err)
+	}
+	if token.RefreshToken != "refresh" || token.AccessToken != "access" {
+		t.Errorf("Loaded token does not match saved token: %+v", token)
+	}
+
+	info, err := os.Stat(client.TokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Token file mode is %o, expected 600", mode)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(client.TokenFile)); len(entries) != 1 {
+		t.Errorf("Expected only the token file, got %v", entries)
+	}
+}
+
+// staticTokenSource returns the tokens it holds one after the other
+type staticTokenSource struct {
+	tokens []*oauth2.Token
+}
+
+func (ts *staticTokenSource) Token() (*oauth2.Token, error) {
+	token := ts.tokens[0]
+	if len(ts.tokens) > 1 {
+		ts.tokens = ts.tokens[1:]
+	}
+	return token, nil
+}
+
+func TestSavingTokenSourceSavesRefreshedTokens(t *testing.T) {
+	initial := &oauth2.Token{AccessToken: "first", RefreshToken: "refresh"}
+	refreshed := &oauth2.Token{AccessToken: "second", RefreshToken: "refresh"}
+
+	var saved []string
+	source := &savingTokenSource{
+		base:  &staticTokenSource{tokens: []*oauth2.Token{initial, refreshed, refreshed}},
+		save:  func(token *oauth2.Token) error { saved = append(saved, token.AccessToken); return nil },
+		token: initial,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+	if len(saved) != 1 || saved[0] != "second" {
+		t.Errorf("Expected only the refreshed token to be saved, got %v", saved)
+	}
+}
+
+func TestLibraryCallsRequireUserLogin(t *testing.T) {
+	client := NewSpotifyClient("id", "secret")
+	if _, err := client.GetLikedTracks(); err != errUserLoginRequired {
+		t.Errorf("Expected a login error, got %v", err)
+	}
+	if _, err := client.GetSavedAlbums(); err != errUserLoginRequired {
+		t.Errorf("Expected a login error, got %v", err)
+	}
+	if _, err := client.GetFollowedArtists(); err != errUserLoginRequired {
+		t.Errorf("Expected a login error, got %v", err)
+	}
+}
+
+func TestUnusableSavedLoginIsSkipped(t *testing.T) {
+	client := NewSpotifyClient("id", "secret")
+	client.TokenFile = filepath.Join(t.TempDir(), "spotify-token.json")
+
+	if err := os.WriteFile(client.TokenFile, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	if client.useSavedLogin(context.Background()) {
+		t.Error("Expected a corrupt token file to be skipped")
+	}
+
+	// An expired token that cannot be refreshed fails before any request is sent
+	expired := &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(-time.Hour)}
+	if err := client.saveToken(expired); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	if client.useSavedLogin(context.Background()) {
+		t.Error("Expected an expired token to be skipped")
+	}
+	if client.userAuthenticated {
+		t.Error("Expected the client to fall back to the app credentials")
+	}
+}
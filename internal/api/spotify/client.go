@@ -11,9 +11,14 @@ import (
 	"golang.org/x/oauth2/clientcredentials"
 )
 
-// Authenticate authenticates the client with the spotify api
+// Authenticate authenticates the client with the spotify api. A saved user login is used when
+// it still works so private playlists stay reachable, otherwise the client credentials are used.
 func (s *SpotifyClient) Authenticate() error {
 	ctx := context.Background()
+	if s.useSavedLogin(ctx) {
+		return nil
+	}
+
 	config := &clientcredentials.Config{
 		ClientID:     s.ID,
 		ClientSecret: s.Secret,
@@ -26,21 +31,42 @@ func (s *SpotifyClient) Authenticate() error {
 
 	httpClient := spotifyauth.New().Client(ctx, token)
 	s.client = spotify.New(httpClient)
+	s.userAuthenticated = false
 	return nil
 }
 
+// useSavedLogin switches to the saved user login if there is one and Spotify still accepts it.
+// An unreadable, expired or revoked login is skipped, since public playlists and albums do not need it.
+func (s *SpotifyClient) useSavedLogin(ctx context.Context) bool {
+	token, err := s.loadToken()
+	if err != nil {
+		log.Printf("Ignoring saved Spotify login: %v", err)
+		return false
+	}
+	if token == nil {
+		return false
+	}
+
+	s.useUserToken(ctx, token)
+	if _, err := s.client.CurrentUser(ctx); err != nil {
+		log.Printf("Saved Spotify login no longer works, using the app credentials: %v", err)
+		s.userAuthenticated = false
+		return false
+	}
+	return true
+}
+
 // GetPlaylistTracks gets the tracks from a spotify playlist
 func (s *SpotifyClient) GetPlaylistTracks(playlistURL string) ([]SpotifyTrack, string, error) { // Updated signature
-	parts := strings.Split(playlistURL, "/")
-	if len(parts) < 5 {
-		return nil, "", fmt.Errorf("invalid playlist URL")
+	ctx := context.Background()
+	playlistID, err := resolveID(ctx, playlistURL, PlaylistLink)
+	if err != nil {
+		return nil, "", err
 	}
-	playlistIDStr := strings.Split(parts[4], "?")[0]
-	playlistID := spotify.ID(playlistIDStr)
 
 	log.Printf("Fetching tracks from playlist: %s", playlistID)
 
-	playlist, err := s.client.GetPlaylist(context.Background(), playlistID)
+	playlist, err := s.client.GetPlaylist(ctx, playlistID)
 	if err != nil {
 		return nil, "", err // Updated return
 	}
@@ -52,19 +78,10 @@ func (s *SpotifyClient) GetPlaylistTracks(playlistURL string) ([]SpotifyTrack, s
 			if item.Track.Album.Name == "" {
 				continue // Skip tracks with no album info
 			}
-			trackName := item.Track.Name
-			artistName := item.Track.Artists[0].Name
-			albumName := item.Track.Album.Name
-			albumArtist := item.Track.Album.Artists[0].Name
-			tracks = append(tracks, SpotifyTrack{
-				Name:        trackName,
-				Artist:      artistName,
-				AlbumName:   albumName,
-				AlbumArtist: albumArtist,
-			}) // Updated append
-		}
-
-		err = s.client.NextPage(context.Background(), &playlist.Tracks)
+			tracks = append(tracks, newFullSpotifyTrack(item.Track))
+		}
+
+		err = s.client.NextPage(ctx, &playlist.Tracks)
 		if err == spotify.ErrNoMorePages {
 			break
 		}
@@ -78,32 +95,213 @@ func (s *SpotifyClient) GetPlaylistTracks(playlistURL string) ([]SpotifyTrack, s
 
 // GetAlbumTracks gets the tracks from a spotify album
 func (s *SpotifyClient) GetAlbumTracks(albumURL string) ([]SpotifyTrack, string, error) {
-	parts := strings.Split(albumURL, "/")
-	if len(parts) < 5 || parts[3] != "album" {
-		return nil, "", fmt.Errorf("invalid album URL")
+	ctx := context.Background()
+	albumID, err := resolveID(ctx, albumURL, AlbumLink)
+	if err != nil {
+		return nil, "", err
 	}
-	albumIDStr := strings.Split(parts[4], "?")[0]
-	albumID := spotify.ID(albumIDStr)
 
 	log.Printf("Fetching tracks from album: %s", albumID)
 
-	album, err := s.client.GetAlbum(context.Background(), albumID)
+	album, err := s.client.GetAlbum(ctx, albumID)
 	if err != nil {
 		return nil, "", err
 	}
 	log.Printf("Spotify Album Name: %s", album.Name)
 
 	var tracks []SpotifyTrack
-	for _, track := range album.Tracks.Tracks {
-		trackName := track.Name
-		artistName := track.Artists[0].Name
-		tracks = append(tracks, SpotifyTrack{
-			Name:        trackName,
-			Artist:      artistName,
-			AlbumName:   album.Name,
-			AlbumArtist: album.Artists[0].Name,
-		})
+	for {
+		for _, track := range album.Tracks.Tracks {
+			tracks = append(tracks, newSpotifyTrack(track, album.Name, album.Artists))
+		}
+
+		// Albums with more than 50 tracks are paged
+		err = s.client.NextPage(ctx, &album.Tracks)
+		if err == spotify.ErrNoMorePages {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
 	}
 
 	return tracks, album.Name, nil
-}
\ No newline at end of file
+}
+
+// GetTrack gets a single track from a spotify track URL
+func (s *SpotifyClient) GetTrack(trackURL string) (SpotifyTrack, error) {
+	ctx := context.Background()
+	trackID, err := resolveID(ctx, trackURL, TrackLink)
+	if err != nil {
+		return SpotifyTrack{}, err
+	}
+
+	log.Printf("Fetching track: %s", trackID)
+
+	track, err := s.client.GetTrack(ctx, trackID)
+	if err != nil {
+		return SpotifyTrack{}, err
+	}
+	return newFullSpotifyTrack(*track), nil
+}
+
+// GetArtist gets an artist from a spotify artist URL
+func (s *SpotifyClient) GetArtist(artistURL string) (SpotifyArtist, error) {
+	ctx := context.Background()
+	artistID, err := resolveID(ctx, artistURL, ArtistLink)
+	if err != nil {
+		return SpotifyArtist{}, err
+	}
+
+	log.Printf("Fetching artist: %s", artistID)
+
+	artist, err := s.client.GetArtist(ctx, artistID)
+	if err != nil {
+		return SpotifyArtist{}, err
+	}
+	return SpotifyArtist{Name: artist.Name}, nil
+}
+
+// GetLikedTracks gets the tracks in the user's Liked Songs. Requires AuthenticateUser.
+func (s *SpotifyClient) GetLikedTracks() ([]SpotifyTrack, error) {
+	if !s.userAuthenticated {
+		return nil, errUserLoginRequired
+	}
+	ctx := context.Background()
+
+	page, err := s.client.CurrentUsersTracks(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []SpotifyTrack
+	for {
+		for _, item := range page.Tracks {
+			tracks = append(tracks, newFullSpotifyTrack(item.FullTrack))
+		}
+
+		err = s.client.NextPage(ctx, page)
+		if err == spotify.ErrNoMorePages {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Fetched %d liked tracks", len(tracks))
+
+	return tracks, nil
+}
+
+// GetSavedAlbums gets the albums saved in the user's library. Requires AuthenticateUser.
+func (s *SpotifyClient) GetSavedAlbums() ([]SpotifyAlbum, error) {
+	if !s.userAuthenticated {
+		return nil, errUserLoginRequired
+	}
+	ctx := context.Background()
+
+	page, err := s.client.CurrentUsersAlbums(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+
+	var albums []SpotifyAlbum
+	for {
+		for _, item := range page.Albums {
+			albums = append(albums, SpotifyAlbum{
+				Name:   item.Name,
+				Artist: joinArtistNames(item.Artists),
+			})
+		}
+
+		err = s.client.NextPage(ctx, page)
+		if err == spotify.ErrNoMorePages {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Fetched %d saved albums", len(albums))
+
+	return albums, nil
+}
+
+// GetFollowedArtists gets the artists the user follows. Requires AuthenticateUser.
+func (s *SpotifyClient) GetFollowedArtists() ([]SpotifyArtist, error) {
+	if !s.userAuthenticated {
+		return nil, errUserLoginRequired
+	}
+	ctx := context.Background()
+
+	var artists []SpotifyArtist
+	options := []spotify.RequestOption{spotify.Limit(50)}
+	for {
+		page, err := s.client.CurrentUsersFollowedArtists(ctx, options...)
+		if err != nil {
+			return nil, err
+		}
+		for _, artist := range page.Artists {
+			artists = append(artists, SpotifyArtist{Name: artist.Name})
+		}
+
+		// Followed artists use cursor paging
+		if page.Next == "" || page.Cursor.After == "" {
+			break
+		}
+		options = []spotify.RequestOption{spotify.Limit(50), spotify.After(page.Cursor.After)}
+	}
+	log.Printf("Fetched %d followed artists", len(artists))
+
+	return artists, nil
+}
+
+// resolveID parses a Spotify URL or URI and checks that it points to the expected kind of entity
+func resolveID(ctx context.Context, link string, expected LinkType) (spotify.ID, error) {
+	parsed, err := ResolveLink(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != expected {
+		return "", fmt.Errorf("expected a Spotify %s link, got a %s link", expected, parsed.Type)
+	}
+	return spotify.ID(parsed.ID), nil
+}
+
+// newSpotifyTrack converts a track, keeping every credited artist
+func newSpotifyTrack(track spotify.SimpleTrack, albumName string, albumArtists []spotify.SimpleArtist) SpotifyTrack {
+	return SpotifyTrack{
+		Name:        track.Name,
+		Artist:      joinArtistNames(track.Artists),
+		Artists:     artistNames(track.Artists),
+		AlbumName:   albumName,
+		AlbumArtist: joinArtistNames(albumArtists),
+		ISRC:        track.ExternalIDs.ISRC,
+		Duration:    int(track.Duration) / 1000,
+	}
+}
+
+// newFullSpotifyTrack converts a full track, which keeps its ISRC in a map
+func newFullSpotifyTrack(track spotify.FullTrack) SpotifyTrack {
+	converted := newSpotifyTrack(track.SimpleTrack, track.Album.Name, track.Album.Artists)
+	if isrc := track.ExternalIDs["isrc"]; isrc != "" {
+		converted.ISRC = isrc
+	}
+	return converted
+}
+
+// artistNames returns the names of all credited artists
+func artistNames(artists []spotify.SimpleArtist) []string {
+	names := make([]string, 0, len(artists))
+	for _, artist := range artists {
+		if artist.Name != "" {
+			names = append(names, artist.Name)
+		}
+	}
+	return names
+}
+
+// joinArtistNames returns the credited artists as one display string, e.g. "Calvin Harris, Dua Lipa"
+func joinArtistNames(artists []spotify.SimpleArtist) string {
+	return strings.Join(artistNames(artists), ", ")
+}
@@ -1,16 +1,24 @@
 package spotify
 
 import (
+	"fmt"
+
 	"github.com/zmb3/spotify/v2"
 )
 
 // SpotifyClient holds the spotify client and other required fields
 type SpotifyClient struct {
-	client *spotify.Client
-	ID     string
-	Secret string
+	client            *spotify.Client
+	ID                string
+	Secret            string
+	RedirectURL       string // Callback for the user login, defaults to DefaultRedirectURL
+	TokenFile         string // Saved user login, defaults to DefaultTokenFile
+	userAuthenticated bool
 }
 
+// errUserLoginRequired is returned by library calls made without a user login
+var errUserLoginRequired = fmt.Errorf("this requires a Spotify user login")
+
 // NewSpotifyClient creates a new spotify client
 func NewSpotifyClient(id, secret string) *SpotifyClient {
 	return &SpotifyClient{
@@ -22,7 +30,21 @@ func NewSpotifyClient(id, secret string) *SpotifyClient {
 // SpotifyTrack represents a track from Spotify
 type SpotifyTrack struct {
 	Name        string
-	Artist      string
+	Artist      string   // All credited artists joined with ", "
+	Artists     []string // Credited artists, primary artist first
 	AlbumName   string
 	AlbumArtist string
-}
\ No newline at end of file
+	ISRC        string
+	Duration    int // Seconds
+}
+
+// SpotifyAlbum represents an album saved in a Spotify library
+type SpotifyAlbum struct {
+	Name   string
+	Artist string
+}
+
+// SpotifyArtist represents an artist followed on Spotify
+type SpotifyArtist struct {
+	Name string
+}
@@ -0,0 +1,103 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LinkType is the kind of Spotify entity a link points to
+type LinkType string
+
+const (
+	PlaylistLink LinkType = "playlist"
+	AlbumLink    LinkType = "album"
+	TrackLink    LinkType = "track"
+	ArtistLink   LinkType = "artist"
+)
+
+// Link identifies a Spotify entity
+type Link struct {
+	Type LinkType
+	ID   string
+}
+
+// shareLinkHosts redirect to open.spotify.com
+var shareLinkHosts = map[string]bool{
+	"spotify.link":     true,
+	"spotify.app.link": true,
+}
+
+// ParseLink parses open.spotify.com URLs (including /intl-xx/ and /embed/ paths and query
+// strings) and spotify: URIs, including the legacy spotify:user:<name>:playlist:<id> form
+func ParseLink(raw string) (Link, error) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "spotify:") {
+		parts := strings.Split(raw, ":")
+		for i := 1; i < len(parts)-1; i++ {
+			if link, ok := newLink(parts[i], parts[i+1]); ok {
+				return link, nil
+			}
+		}
+		return Link{}, fmt.Errorf("unsupported Spotify URI: %s", raw)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || !isSpotifyHost(parsed.Hostname()) {
+		return Link{}, fmt.Errorf("not a Spotify URL: %s", raw)
+	}
+
+	// Paths look like /playlist/<id>, /intl-de/album/<id> or /embed/track/<id>
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if link, ok := newLink(parts[i], parts[i+1]); ok {
+			return link, nil
+		}
+	}
+	return Link{}, fmt.Errorf("unsupported Spotify URL: %s", raw)
+}
+
+// ResolveLink parses a link like ParseLink and also follows spotify.link share links
+func ResolveLink(ctx context.Context, raw string) (Link, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !shareLinkHosts[strings.ToLower(parsed.Hostname())] {
+		return ParseLink(raw)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return Link{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Link{}, fmt.Errorf("failed to resolve Spotify share link: %w", err)
+	}
+	resp.Body.Close()
+
+	return ParseLink(resp.Request.URL.String())
+}
+
+// newLink builds a link from a type and ID pair, rejecting unknown types and empty IDs
+func newLink(linkType, id string) (Link, bool) {
+	switch LinkType(linkType) {
+	case PlaylistLink, AlbumLink, TrackLink, ArtistLink:
+	default:
+		return Link{}, false
+	}
+	if id == "" {
+		return Link{}, false
+	}
+	return Link{Type: LinkType(linkType), ID: id}, true
+}
+
+// isSpotifyHost reports whether a host is spotify.com or one of its subdomains
+func isSpotifyHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "spotify.com" || strings.HasSuffix(host, ".spotify.com")
+}
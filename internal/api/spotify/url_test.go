@@ -0,0 +1,55 @@
+package spotify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseLink(t *testing.T) {
+	tests := map[string]Link{
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M":             {PlaylistLink, "37i9dQZF1DXcBWIGoYBM5M"},
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc&pt=x": {PlaylistLink, "37i9dQZF1DXcBWIGoYBM5M"},
+		"https://open.spotify.com/intl-de/album/4aawyAB9vmqN3uQ7FjRGTy":        {AlbumLink, "4aawyAB9vmqN3uQ7FjRGTy"},
+		"https://open.spotify.com/embed/track/11dFghVXANMlKmJXsNCbNl":          {TrackLink, "11dFghVXANMlKmJXsNCbNl"},
+		"open.spotify.com/artist/0TnOYISbd1XYRBk9myaseg":                       {},
+		"https://open.spotify.com/artist/0TnOYISbd1XYRBk9myaseg/":              {ArtistLink, "0TnOYISbd1XYRBk9myaseg"},
+		"spotify:track:11dFghVXANMlKmJXsNCbNl":                                 {TrackLink, "11dFghVXANMlKmJXsNCbNl"},
+		"spotify:user:someone:playlist:37i9dQZF1DXcBWIGoYBM5M":                 {PlaylistLink, "37i9dQZF1DXcBWIGoYBM5M"},
+		"  https://open.spotify.com/album/4aawyAB9vmqN3uQ7FjRGTy?si=xyz  ":     {AlbumLink, "4aawyAB9vmqN3uQ7FjRGTy"},
+		"https://open.spotify.com/show/5CfCWKI5pZ28U0uOzXkDHe":                 {},
+		"https://notspotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M":               {},
+		"spotify:episode:512ojhOuo1ktJprKbVcKyQ":                               {},
+		"https://open.spotify.com/playlist/":                                   {},
+	}
+
+	for raw, expected := range tests {
+		link, err := ParseLink(raw)
+		if expected == (Link{}) {
+			if err == nil {
+				t.Errorf("ParseLink(%q) = %+v, expected an error", raw, link)
+			}
+			continue
+		}
+		if err != nil || link != expected {
+			t.Errorf("ParseLink(%q) = %+v, %v; expected %+v", raw, link, err, expected)
+		}
+	}
+}
+
+func TestResolveLinkParsesDirectLinks(t *testing.T) {
+	link, err := ResolveLink(context.Background(), "spotify:album:4aawyAB9vmqN3uQ7FjRGTy")
+	if err != nil || link.Type != AlbumLink {
+		t.Errorf("Expected an album link, got %+v, %v", link, err)
+	}
+}
+
+func TestResolveIDChecksType(t *testing.T) {
+	if _, err := resolveID(context.Background(), "https://open.spotify.com/album/4aawyAB9vmqN3uQ7FjRGTy", PlaylistLink); err == nil {
+		t.Error("Expected an error for an album link where a playlist is expected")
+	}
+
+	id, err := resolveID(context.Background(), "https://open.spotify.com/intl-fr/playlist/37i9dQZF1DXcBWIGoYBM5M?si=1", PlaylistLink)
+	if err != nil || id != "37i9dQZF1DXcBWIGoYBM5M" {
+		t.Errorf("Expected playlist ID, got %q, %v", id, err)
+	}
+}
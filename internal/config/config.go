@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"dab-downloader/internal/shared"
 )
 
 // Add these constants to types.go or create constants.go
@@ -13,6 +15,7 @@ const (
 	RequestTimeout    = 10 * time.Minute
 	UserAgent         = "DAB-Downloader/2.0"
 	DefaultMaxRetries = 3
+	DefaultConfigFile = "config/config.json"
 )
 
 // NamingOptions defines the configurable naming masks
@@ -53,24 +56,43 @@ func (cfg *Config) ApplyDefaultNamingMasks() {
 
 // Configuration structure
 type Config struct {
-	APIURL              string        `json:"APIURL"`
-	DownloadLocation    string        `json:"DownloadLocation"`
-	Parallelism         int           `json:"Parallelism"`
-	SpotifyClientID     string        `json:"SpotifyClientID"`
-	SpotifyClientSecret string        `json:"SpotifyClientSecret"`
-	NavidromeURL        string        `json:"NavidromeURL"`
-	NavidromeUsername   string        `json:"NavidromeUsername"`
-	NavidromePassword   string        `json:"NavidromePassword"`
-	Format              string        `json:"Format"`
-	Bitrate             string        `json:"Bitrate"`
-	SaveAlbumArt        bool          `json:"SaveAlbumArt"`
-	DisableUpdateCheck  bool          `json:"DisableUpdateCheck"`
-	IsDockerContainer   bool          `json:"-"` // Not saved to config.json
-	UpdateRepo          string        `json:"UpdateRepo"`
-	NamingMasks         NamingOptions `json:"naming"`
-	VerifyDownloads     bool          `json:"VerifyDownloads"`     // Enable/disable download verification
-	MaxRetryAttempts    int           `json:"MaxRetryAttempts"`    // Configurable retry attempts
-	WarningBehavior     string        `json:"WarningBehavior"`     // "immediate", "summary", or "silent"
+	APIURL                  string        `json:"APIURL"`
+	DownloadLocation        string        `json:"DownloadLocation"`
+	Parallelism             int           `json:"Parallelism"`
+	SpotifyClientID         string        `json:"SpotifyClientID"`
+	SpotifyClientSecret     string        `json:"SpotifyClientSecret"`
+	SpotifyRedirectURL      string        `json:"SpotifyRedirectURL,omitempty"`      // Callback for the Spotify user login, defaults to http://127.0.0.1:8888/callback
+	SpotifyTokenFile        string        `json:"SpotifyTokenFile,omitempty"`        // Saved Spotify user login, defaults to config/spotify-token.json
+	NavidromeURL            string        `json:"NavidromeURL"`
+	NavidromeUsername       string        `json:"NavidromeUsername"`
+	NavidromePassword       string        `json:"NavidromePassword"`
+	NavidromeAPIKey         string        `json:"NavidromeAPIKey,omitempty"`         // OpenSubsonic API key, used instead of the username and password
+	NavidromeRescan         bool          `json:"NavidromeRescan"`                   // Rescan the library after downloads and retry unmatched playlist tracks
+	PlaylistSyncFile        string        `json:"PlaylistSyncFile,omitempty"`        // Spotify to Navidrome playlist mappings, defaults to config/playlist-sync.json
+	MatchThreshold          float64       `json:"MatchThreshold,omitempty"`          // Lowest score (0-1) to accept a DAB match for a Spotify track, defaults to 0.75
+	MatchReportFile         string        `json:"MatchReportFile,omitempty"`         // Unmatched and low-confidence tracks, defaults to config/match-report.json
+	Format                  string        `json:"Format"`
+	Bitrate                 string        `json:"Bitrate"`
+	SaveAlbumArt            bool          `json:"SaveAlbumArt"`
+	DisableUpdateCheck      bool          `json:"DisableUpdateCheck"`
+	IsDockerContainer       bool          `json:"-"`                                 // Not saved to config.json
+	UpdateRepo              string        `json:"UpdateRepo"`
+	NamingMasks             NamingOptions `json:"naming"`
+	VerifyDownloads         bool          `json:"VerifyDownloads"`                   // Enable/disable download verification
+	MaxRetryAttempts        int           `json:"MaxRetryAttempts"`                  // Configurable retry attempts
+	WarningBehavior         string        `json:"WarningBehavior"`                   // "immediate", "summary", or "silent"
+	MusicBrainzReleaseTags  bool          `json:"MusicBrainzReleaseTags"`            // Tag from the full MusicBrainz release, DAB values only as fallback
+	AcoustIDFallback        bool          `json:"AcoustIDFallback"`                  // Identify tracks without an ISRC match by audio fingerprint
+	AcoustIDAPIKey          string        `json:"AcoustIDAPIKey"`                    // AcoustID application API key
+	AcoustIDURL             string        `json:"AcoustIDURL,omitempty"`             // AcoustID-compatible endpoint, defaults to api.acoustid.org
+	AcoustIDMinScore        float64       `json:"AcoustIDMinScore,omitempty"`        // Minimum match score (0-1) to accept, defaults to 0.8
+	FpcalcPath              string        `json:"FpcalcPath,omitempty"`              // Path to the Chromaprint fpcalc binary, defaults to fpcalc in PATH
+	ReleaseReview           string        `json:"ReleaseReview,omitempty"`           // Ambiguous MusicBrainz release handling: "off", "interactive" or "report"
+	ReleaseReviewMargin     int           `json:"ReleaseReviewMargin,omitempty"`     // Score margin below which the release selection is ambiguous, defaults to 20
+	ReleaseReviewCandidates int           `json:"ReleaseReviewCandidates,omitempty"` // Number of candidate releases to show, defaults to 5
+	ReleaseReviewReport     string        `json:"ReleaseReviewReport,omitempty"`     // Review report and remembered choices, defaults to config/release-review.json
+	DuplicatePolicy         string        `json:"DuplicatePolicy,omitempty"`         // Edition kept of duplicate releases: "prefer-explicit", "prefer-hires", "prefer-newest" or "keep-all"
+	FilterPresets           map[string]string `json:"FilterPresets,omitempty"`   // Named discography filter expressions, e.g. "studio": "albums -title:live"
 }
 
 // CreateDirIfNotExists creates a directory if it does not exist
@@ -107,4 +129,37 @@ func SaveConfig(filePath string, config *Config) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SaveFilterPreset stores a named discography filter in the config file. The file is read again as raw JSON
+// so that command-line overrides of the running configuration are not written to it and keys this version
+// does not know about are kept.
+func SaveFilterPreset(filePath string, name string, expression string) error {
+	settings := make(map[string]interface{})
+	if data, err := os.ReadFile(filePath); err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	presets, _ := settings["FilterPresets"].(map[string]interface{})
+	if presets == nil {
+		presets = make(map[string]interface{})
+	}
+	presets[name] = expression
+	settings["FilterPresets"] = presets
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := CreateDirIfNotExists(filepath.Dir(filePath)); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := shared.WriteFileAtomic(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
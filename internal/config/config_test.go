@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFilterPresetKeepsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.json")
+	original := `{"APIURL": "https://dab.example", "Format": "mp3", "FuturePlugin": {"enabled": true}, "FilterPresets": {"studio": "albums -title:live"}}`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveFilterPreset(path, "recent", "year:2015-"); err != nil {
+		t.Fatalf("SaveFilterPreset failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := settings["FuturePlugin"]; !ok {
+		t.Errorf("unknown key dropped: %s", data)
+	}
+	if _, ok := settings["Parallelism"]; ok {
+		t.Errorf("unset key written: %s", data)
+	}
+
+	cfg := &Config{}
+	if err := LoadConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != "mp3" || cfg.FilterPresets["studio"] != "albums -title:live" || cfg.FilterPresets["recent"] != "year:2015-" {
+		t.Errorf("unexpected config after saving: %+v", cfg)
+	}
+}
+
+func TestSaveFilterPresetCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "config.json")
+	if err := SaveFilterPreset(path, "studio", "albums"); err != nil {
+		t.Fatalf("SaveFilterPreset failed: %v", err)
+	}
+
+	cfg := &Config{}
+	if err := LoadConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FilterPresets["studio"] != "albums" {
+		t.Errorf("preset not saved: %+v", cfg.FilterPresets)
+	}
+}
@@ -0,0 +1,331 @@
+package discography
+
+import (
+	"regexp"
+	"strings"
+
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Constants and Types
+// ============================================================================
+
+// Duplicate policies decide which edition of a release is kept
+const (
+	PolicyPreferExplicit = "prefer-explicit" // The explicit edition, then the best quality
+	PolicyPreferHiRes    = "prefer-hires"    // The best quality, then the explicit edition
+	PolicyPreferNewest   = "prefer-newest"   // The latest release, e.g. a remaster
+	PolicyKeepAll        = "keep-all"        // No duplicate detection
+
+	DefaultPolicy = PolicyPreferExplicit
+)
+
+// Policies lists the supported duplicate policies
+var Policies = []string{PolicyPreferExplicit, PolicyPreferHiRes, PolicyPreferNewest, PolicyKeepAll}
+
+// isrcOverlap is the share of the smaller track list that has to appear on the other edition
+const isrcOverlap = 0.5
+
+// editionMarkers are words in a bracketed title suffix, or after " - ", that name an edition of a
+// release rather than a different release
+var editionMarkers = []string{
+	"explicit", "clean", "edited", "censored", "remaster", "deluxe", "expanded", "edition",
+	"anniversary", "bonus", "reissue", "international",
+}
+
+var (
+	explicitMarker = regexp.MustCompile(`(?i)[(\[][^)\]]*explicit[^)\]]*[)\]]`)
+	cleanMarker    = regexp.MustCompile(`(?i)[(\[][^)\]]*(clean|edited|censored)[^)\]]*[)\]]`)
+	titleSuffix    = regexp.MustCompile(`\s*(\([^)]*\)|\[[^\]]*\]|\s-\s[^()\[\]]*)$`)
+	nonAlphaNum    = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// Group is a release with the other editions it was collapsed with
+type Group struct {
+	Preferred  shared.Album
+	Duplicates []shared.Album // Other editions, in discography order
+}
+
+// ============================================================================
+// 2. Public API
+// ============================================================================
+
+// IsPolicy reports whether the policy is one of Policies
+func IsPolicy(policy string) bool {
+	for _, p := range Policies {
+		if policy == p {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupDuplicates collapses the editions of each release in a discography and picks the one the
+// policy prefers. Groups keep the order of their first edition. An empty policy uses DefaultPolicy;
+// with PolicyKeepAll every album is its own group.
+func GroupDuplicates(albums []shared.Album, policy string) []Group {
+	if policy == "" {
+		policy = DefaultPolicy
+	}
+
+	parent := make([]int, len(albums))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	if policy != PolicyKeepAll {
+		for i := range albums {
+			for j := i + 1; j < len(albums); j++ {
+				if IsDuplicate(albums[i], albums[j]) {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range albums {
+		root := find(i)
+		if _, exists := members[root]; !exists {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	groups := make([]Group, 0, len(roots))
+	for _, root := range roots {
+		editions := members[root]
+		best := editions[0]
+		for _, i := range editions[1:] {
+			if prefer(albums[i], albums[best], policy) {
+				best = i
+			}
+		}
+
+		group := Group{Preferred: albums[best]}
+		for _, i := range editions {
+			if i != best {
+				group.Duplicates = append(group.Duplicates, albums[i])
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// Preferred returns the preferred edition of every release in a discography
+func Preferred(groups []Group) []shared.Album {
+	albums := make([]shared.Album, len(groups))
+	for i, group := range groups {
+		albums[i] = group.Preferred
+	}
+	return albums
+}
+
+// IsDuplicate reports whether two albums are editions of the same release: they share a UPC, or their
+// titles match once edition markers are removed and their track lists overlap by ISRC. Without ISRCs,
+// an edition marker on a title or the same release year decides. Self-titled albums share a title and
+// often a length, so equal track counts alone are not enough.
+func IsDuplicate(a, b shared.Album) bool {
+	if a.UPC != "" && a.UPC == b.UPC {
+		return true
+	}
+	if NormalizeTitle(a.Title) != NormalizeTitle(b.Title) || releaseKind(a) != releaseKind(b) {
+		return false
+	}
+
+	if overlap, known := isrcOverlapRatio(a, b); known {
+		// Clean editions have their own ISRCs, so a low overlap only rules out releases of the same length
+		if overlap >= isrcOverlap {
+			return true
+		}
+		if !IsExplicit(a) && !IsExplicit(b) && !isClean(a) && !isClean(b) {
+			return false
+		}
+	}
+
+	sameYear := releaseYear(a) != "" && releaseYear(a) == releaseYear(b)
+	countA, countB := trackCount(a), trackCount(b)
+	switch {
+	case hasEditionMarker(a.Title) || hasEditionMarker(b.Title):
+		return true
+	case countA > 0 && countB > 0 && countA == countB:
+		return sameYear
+	case countA == 0 && countB == 0:
+		return sameYear
+	default:
+		return false
+	}
+}
+
+// NormalizeTitle lowercases a title and removes edition markers such as "(Deluxe Edition)",
+// "[Explicit]" or " - 2011 Remaster" and punctuation
+func NormalizeTitle(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for {
+		suffix := titleSuffix.FindString(title)
+		if suffix == "" || !hasEditionMarker(suffix) {
+			break
+		}
+		title = strings.TrimSpace(strings.TrimSuffix(title, suffix))
+	}
+	return strings.TrimSpace(nonAlphaNum.ReplaceAllString(title, " "))
+}
+
+// IsExplicit reports whether an album is the explicit edition, from its parental warning or its title
+func IsExplicit(album shared.Album) bool {
+	if album.ParentalWarning || explicitMarker.MatchString(album.Title) {
+		return true
+	}
+	for _, track := range album.Tracks {
+		if track.ParentalWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// 3. Helper Functions
+// ============================================================================
+
+// prefer reports whether album a is a better pick than b under the policy
+func prefer(a, b shared.Album, policy string) bool {
+	explicit := func() int { return compareBool(IsExplicit(a), IsExplicit(b)) }
+	quality := func() int { return compareQuality(a.AudioQuality, b.AudioQuality) }
+	newest := func() int { return strings.Compare(a.ReleaseDate, b.ReleaseDate) }
+	tracks := func() int { return compareInt(trackCount(a), trackCount(b)) }
+
+	var order []func() int
+	switch policy {
+	case PolicyPreferHiRes:
+		order = []func() int{quality, explicit, tracks, newest}
+	case PolicyPreferNewest:
+		order = []func() int{newest, quality, explicit, tracks}
+	default:
+		order = []func() int{explicit, quality, tracks, newest}
+	}
+	for _, compare := range order {
+		if result := compare(); result != 0 {
+			return result > 0
+		}
+	}
+	return false // Keep the first edition listed
+}
+
+// isrcOverlapRatio returns the share of the smaller ISRC set found in the other, and whether both albums list ISRCs
+func isrcOverlapRatio(a, b shared.Album) (float64, bool) {
+	isrcsA, isrcsB := isrcSet(a), isrcSet(b)
+	if len(isrcsA) == 0 || len(isrcsB) == 0 {
+		return 0, false
+	}
+	if len(isrcsA) > len(isrcsB) {
+		isrcsA, isrcsB = isrcsB, isrcsA
+	}
+
+	common := 0
+	for isrc := range isrcsA {
+		if isrcsB[isrc] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(isrcsA)), true
+}
+
+func isrcSet(album shared.Album) map[string]bool {
+	isrcs := make(map[string]bool)
+	for _, track := range album.Tracks {
+		if track.ISRC != "" {
+			isrcs[strings.ToUpper(track.ISRC)] = true
+		}
+	}
+	return isrcs
+}
+
+// hasEditionMarker reports whether a title or title suffix names an edition
+func hasEditionMarker(title string) bool {
+	title = strings.ToLower(title)
+	suffix := titleSuffix.FindString(title)
+	for _, marker := range editionMarkers {
+		if strings.Contains(suffix, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isClean(album shared.Album) bool {
+	return cleanMarker.MatchString(album.Title)
+}
+
+// releaseKind groups album types so a single is never a duplicate of an album with the same title
+func releaseKind(album shared.Album) string {
+	switch strings.ToLower(album.Type) {
+	case "single", "ep":
+		return strings.ToLower(album.Type)
+	default:
+		return "album"
+	}
+}
+
+func trackCount(album shared.Album) int {
+	if len(album.Tracks) > 0 {
+		return len(album.Tracks)
+	}
+	return album.TotalTracks
+}
+
+func releaseYear(album shared.Album) string {
+	if album.Year != "" {
+		return album.Year
+	}
+	if len(album.ReleaseDate) >= 4 {
+		return album.ReleaseDate[:4]
+	}
+	return ""
+}
+
+func compareQuality(a, b shared.AudioQuality) int {
+	if result := compareInt(a.MaximumBitDepth, b.MaximumBitDepth); result != 0 {
+		return result
+	}
+	switch {
+	case a.MaximumSamplingRate > b.MaximumSamplingRate:
+		return 1
+	case a.MaximumSamplingRate < b.MaximumSamplingRate:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a && !b:
+		return 1
+	case !a && b:
+		return -1
+	default:
+		return 0
+	}
+}
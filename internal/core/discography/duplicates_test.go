@@ -0,0 +1,189 @@
+package discography
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"dab-downloader/internal/shared"
+)
+
+// tracksWithISRCs builds a track list with the given ISRCs
+func tracksWithISRCs(isrcs ...string) []shared.Track {
+	tracks := make([]shared.Track, len(isrcs))
+	for i, isrc := range isrcs {
+		tracks[i] = shared.Track{ID: fmt.Sprintf("t%d", i+1), TrackNumber: i + 1, ISRC: isrc}
+	}
+	return tracks
+}
+
+func albumIDs(albums []shared.Album) []string {
+	ids := make([]string, len(albums))
+	for i, album := range albums {
+		ids[i] = album.ID
+	}
+	return ids
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"Nevermind":                         "nevermind",
+		"Nevermind (Deluxe Edition)":        "nevermind",
+		"Nevermind [Explicit]":              "nevermind",
+		"Nevermind (Remastered) [Explicit]": "nevermind",
+		"Nevermind - 2011 Remaster":         "nevermind",
+		"Nevermind (20th Anniversary)":      "nevermind",
+		"Live at Reading (Live)":            "live at reading live",
+		"Songs - From the Film":             "songs from the film",
+		"  Good Kid, M.A.A.D City (Clean) ": "good kid m a a d city",
+	}
+	for title, want := range tests {
+		if got := NormalizeTitle(title); got != want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b shared.Album
+		want bool
+	}{
+		{
+			name: "same UPC",
+			a:    shared.Album{Title: "One", UPC: "123"},
+			b:    shared.Album{Title: "Other", UPC: "123"},
+			want: true,
+		},
+		{
+			name: "explicit and clean with their own ISRCs",
+			a:    shared.Album{Title: "Album", ParentalWarning: true, Tracks: tracksWithISRCs("A1", "A2", "A3")},
+			b:    shared.Album{Title: "Album (Clean)", Tracks: tracksWithISRCs("B1", "B2", "B3")},
+			want: true,
+		},
+		{
+			name: "deluxe with bonus tracks sharing ISRCs",
+			a:    shared.Album{Title: "Album", Tracks: tracksWithISRCs("A1", "A2", "A3")},
+			b:    shared.Album{Title: "Album (Deluxe)", Tracks: tracksWithISRCs("A1", "A2", "A3", "A4", "A5")},
+			want: true,
+		},
+		{
+			name: "same title with different recordings",
+			a:    shared.Album{Title: "Greatest Hits", Tracks: tracksWithISRCs("A1", "A2", "A3")},
+			b:    shared.Album{Title: "Greatest Hits", Tracks: tracksWithISRCs("B1", "B2", "B3")},
+			want: false,
+		},
+		{
+			name: "remaster without track lists",
+			a:    shared.Album{Title: "Album", TotalTracks: 10},
+			b:    shared.Album{Title: "Album - 2011 Remaster", TotalTracks: 12},
+			want: true,
+		},
+		{
+			name: "self-titled albums of the same length",
+			a:    shared.Album{Title: "Weezer", Year: "1994", TotalTracks: 10},
+			b:    shared.Album{Title: "Weezer", Year: "2001", TotalTracks: 10},
+			want: false,
+		},
+		{
+			name: "reissue of the same length and year",
+			a:    shared.Album{Title: "Album", Year: "2019", TotalTracks: 10},
+			b:    shared.Album{Title: "Album", ReleaseDate: "2019-11-01", TotalTracks: 10},
+			want: true,
+		},
+		{
+			name: "single named like the album",
+			a:    shared.Album{Title: "Song", Type: "album", TotalTracks: 1},
+			b:    shared.Album{Title: "Song", Type: "single", TotalTracks: 1},
+			want: false,
+		},
+		{
+			name: "different titles",
+			a:    shared.Album{Title: "First", TotalTracks: 10},
+			b:    shared.Album{Title: "Second", TotalTracks: 10},
+			want: false,
+		},
+		{
+			name: "same title and year without tracks",
+			a:    shared.Album{Title: "Album", Year: "2020"},
+			b:    shared.Album{Title: "Album", ReleaseDate: "2020-03-01"},
+			want: true,
+		},
+		{
+			name: "same title in other years without tracks",
+			a:    shared.Album{Title: "Album", Year: "2020"},
+			b:    shared.Album{Title: "Album", Year: "2004"},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsDuplicate(test.a, test.b); got != test.want {
+				t.Errorf("IsDuplicate() = %v, want %v", got, test.want)
+			}
+			if got := IsDuplicate(test.b, test.a); got != test.want {
+				t.Errorf("IsDuplicate() swapped = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupDuplicatesPolicies(t *testing.T) {
+	hiRes := shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96}
+	cd := shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}
+	albums := []shared.Album{
+		{ID: "clean", Title: "Album (Clean)", ReleaseDate: "2015-01-01", AudioQuality: hiRes, TotalTracks: 10},
+		{ID: "explicit", Title: "Album", ReleaseDate: "2015-01-01", AudioQuality: cd, ParentalWarning: true, TotalTracks: 10},
+		{ID: "remaster", Title: "Album - 2021 Remaster", ReleaseDate: "2021-06-01", AudioQuality: cd, TotalTracks: 10},
+		{ID: "other", Title: "Other Album", ReleaseDate: "2018-01-01", TotalTracks: 9},
+	}
+
+	tests := map[string][]string{
+		"":                   {"explicit", "other"},
+		PolicyPreferExplicit: {"explicit", "other"},
+		PolicyPreferHiRes:    {"clean", "other"},
+		PolicyPreferNewest:   {"remaster", "other"},
+		PolicyKeepAll:        {"clean", "explicit", "remaster", "other"},
+	}
+	for policy, want := range tests {
+		groups := GroupDuplicates(albums, policy)
+		if got := albumIDs(Preferred(groups)); !reflect.DeepEqual(got, want) {
+			t.Errorf("policy %q kept %v, want %v", policy, got, want)
+		}
+	}
+
+	groups := GroupDuplicates(albums, PolicyPreferExplicit)
+	if got := albumIDs(groups[0].Duplicates); !reflect.DeepEqual(got, []string{"clean", "remaster"}) {
+		t.Errorf("collapsed editions = %v, want [clean remaster]", got)
+	}
+	if len(groups[1].Duplicates) != 0 {
+		t.Errorf("unrelated album has duplicates %v", albumIDs(groups[1].Duplicates))
+	}
+}
+
+func TestGroupDuplicatesExplicitTracks(t *testing.T) {
+	explicitTracks := tracksWithISRCs("A1", "A2")
+	explicitTracks[1].ParentalWarning = true
+	albums := []shared.Album{
+		{ID: "clean", Title: "Album (Edited)", Tracks: tracksWithISRCs("B1", "B2")},
+		{ID: "explicit", Title: "Album", Tracks: explicitTracks},
+	}
+
+	groups := GroupDuplicates(albums, PolicyPreferExplicit)
+	if len(groups) != 1 || groups[0].Preferred.ID != "explicit" {
+		t.Fatalf("groups = %+v, want the explicit edition only", groups)
+	}
+}
+
+func TestIsPolicy(t *testing.T) {
+	for _, policy := range Policies {
+		if !IsPolicy(policy) {
+			t.Errorf("IsPolicy(%q) = false", policy)
+		}
+	}
+	if IsPolicy("prefer-clean") {
+		t.Error("IsPolicy(\"prefer-clean\") = true")
+	}
+}
@@ -0,0 +1,300 @@
+package discography
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Constants and Types
+// ============================================================================
+
+// maxPresetDepth limits how deeply presets may refer to other presets
+const maxPresetDepth = 8
+
+// typeAliases maps the accepted release type names to album types
+var typeAliases = map[string]string{
+	"album": "album", "albums": "album",
+	"ep": "ep", "eps": "ep",
+	"single": "single", "singles": "single",
+}
+
+// Filter selects releases of a discography. Its terms are combined with AND; values of a type term with OR.
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	text   string
+	negate bool
+	match  func(album shared.Album) bool
+}
+
+// ============================================================================
+// 2. Public API
+// ============================================================================
+
+// ParseFilter parses a filter expression. Terms are separated by spaces and can be negated with a leading "-":
+//
+//	albums,eps            release types (type:album,ep)
+//	year:2010-2015        release year range; year:2010, year:2010- and year:-2015 also work
+//	bitdepth:24           minimum bit depth
+//	hires                 hi-res releases only
+//	explicit, clean       explicit or non-explicit editions
+//	title:REGEX           title matches the case-insensitive regex; -title:live excludes live albums
+//	label:TEXT            label contains the text
+//	tracks:5-20           track count range, like year
+//	preset:NAME, NAME     a named preset from the configuration
+//
+// Values containing spaces can be quoted, e.g. title:"live at". An empty expression or "all" matches everything.
+func ParseFilter(expression string, presets map[string]string) (*Filter, error) {
+	return parseFilter(expression, presets, 0)
+}
+
+// Match reports whether an album passes every term of the filter
+func (f *Filter) Match(album shared.Album) bool {
+	for _, term := range f.terms {
+		if term.match(album) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the albums that match the filter, in their original order
+func (f *Filter) Apply(albums []shared.Album) []shared.Album {
+	var filtered []shared.Album
+	for _, album := range albums {
+		if f.Match(album) {
+			filtered = append(filtered, album)
+		}
+	}
+	return filtered
+}
+
+// String returns the parsed terms, with presets expanded
+func (f *Filter) String() string {
+	texts := make([]string, len(f.terms))
+	for i, term := range f.terms {
+		texts[i] = term.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// ============================================================================
+// 3. Helper Functions
+// ============================================================================
+
+func parseFilter(expression string, presets map[string]string, depth int) (*Filter, error) {
+	if depth > maxPresetDepth {
+		return nil, fmt.Errorf("filter presets refer to each other too deeply")
+	}
+
+	tokens, err := splitFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &Filter{}
+	for _, token := range tokens {
+		if strings.EqualFold(token, "all") {
+			continue
+		}
+
+		negate := strings.HasPrefix(token, "-")
+		body := strings.TrimPrefix(token, "-")
+		key, value, hasValue := strings.Cut(body, ":")
+		key = strings.ToLower(key)
+
+		// Presets are expanded in place
+		presetName := ""
+		if key == "preset" && hasValue {
+			presetName = value
+		} else if !hasValue && typeAliases[strings.Split(key, ",")[0]] == "" && !isFlagTerm(key) {
+			presetName = body
+		}
+		if presetName != "" {
+			preset, exists := presets[presetName]
+			if !exists {
+				return nil, fmt.Errorf("unknown filter term or preset %q", presetName)
+			}
+			if negate {
+				return nil, fmt.Errorf("preset %q cannot be negated", presetName)
+			}
+			expanded, err := parseFilter(preset, presets, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("preset %q: %w", presetName, err)
+			}
+			filter.terms = append(filter.terms, expanded.terms...)
+			continue
+		}
+
+		term, err := parseTerm(key, value, hasValue)
+		if err != nil {
+			return nil, err
+		}
+		term.text = token
+		term.negate = negate
+		filter.terms = append(filter.terms, term)
+	}
+	return filter, nil
+}
+
+// parseTerm builds the matcher of a single filter term
+func parseTerm(key, value string, hasValue bool) (filterTerm, error) {
+	if !hasValue {
+		if isFlagTerm(key) {
+			return parseTerm(key, "true", true)
+		}
+		// A bare type list such as "albums,eps"
+		return parseTerm("type", key, true)
+	}
+	if value == "" {
+		return filterTerm{}, fmt.Errorf("filter term %q has no value", key)
+	}
+
+	switch key {
+	case "type":
+		types := make(map[string]bool)
+		for _, name := range strings.Split(strings.ToLower(value), ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			albumType, known := typeAliases[name]
+			if !known {
+				return filterTerm{}, fmt.Errorf("unknown release type %q (supported: albums, eps, singles)", name)
+			}
+			types[albumType] = true
+		}
+		return filterTerm{match: func(album shared.Album) bool { return types[strings.ToLower(album.Type)] }}, nil
+
+	case "year":
+		low, high, err := shared.ParseRange(value)
+		if err != nil {
+			return filterTerm{}, fmt.Errorf("invalid year range %q: %w", value, err)
+		}
+		return filterTerm{match: func(album shared.Album) bool {
+			year, err := strconv.Atoi(releaseYear(album))
+			return err == nil && inRange(year, low, high)
+		}}, nil
+
+	case "tracks":
+		low, high, err := shared.ParseRange(value)
+		if err != nil {
+			return filterTerm{}, fmt.Errorf("invalid track count range %q: %w", value, err)
+		}
+		return filterTerm{match: func(album shared.Album) bool {
+			count := trackCount(album)
+			return count > 0 && inRange(count, low, high)
+		}}, nil
+
+	case "bitdepth":
+		minimum, err := strconv.Atoi(value)
+		if err != nil || minimum <= 0 {
+			return filterTerm{}, fmt.Errorf("invalid bit depth %q", value)
+		}
+		return filterTerm{match: func(album shared.Album) bool {
+			return album.AudioQuality.MaximumBitDepth >= minimum
+		}}, nil
+
+	case "hires", "explicit", "clean":
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return filterTerm{}, fmt.Errorf("invalid value %q for %s, expected true or false", value, key)
+		}
+		return filterTerm{match: func(album shared.Album) bool { return flagValue(key, album) == want }}, nil
+
+	case "title":
+		pattern, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			return filterTerm{}, fmt.Errorf("invalid title pattern %q: %w", value, err)
+		}
+		return filterTerm{match: func(album shared.Album) bool { return pattern.MatchString(album.Title) }}, nil
+
+	case "label":
+		text := strings.ToLower(value)
+		return filterTerm{match: func(album shared.Album) bool {
+			return strings.Contains(strings.ToLower(LabelName(album)), text)
+		}}, nil
+
+	default:
+		return filterTerm{}, fmt.Errorf("unknown filter term %q", key)
+	}
+}
+
+// LabelName returns the label of an album, which DAB sends either as a string or as an object with a name
+func LabelName(album shared.Album) string {
+	switch label := album.Label.(type) {
+	case string:
+		return label
+	case map[string]interface{}:
+		if name, ok := label["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func isFlagTerm(key string) bool {
+	return key == "hires" || key == "explicit" || key == "clean"
+}
+
+func flagValue(key string, album shared.Album) bool {
+	switch key {
+	case "hires":
+		return shared.IsHiRes(album.AudioQuality)
+	case "explicit":
+		return IsExplicit(album)
+	default:
+		return !IsExplicit(album)
+	}
+}
+
+func inRange(value, low, high int) bool {
+	return (low == 0 || value >= low) && (high == 0 || value <= high)
+}
+
+// splitFilter splits an expression on spaces, keeping double-quoted values and comma-separated lists together
+func splitFilter(expression string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	quoted, started := false, false
+	for _, r := range expression {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in filter %q", expression)
+	}
+	if started {
+		tokens = append(tokens, current.String())
+	}
+
+	// "albums, eps" is one list, as the type filter has always accepted it
+	var merged []string
+	for _, token := range tokens {
+		if n := len(merged); n > 0 && (strings.HasSuffix(merged[n-1], ",") || strings.HasPrefix(token, ",")) {
+			merged[n-1] += token
+			continue
+		}
+		merged = append(merged, token)
+	}
+	return merged, nil
+}
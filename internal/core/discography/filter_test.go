@@ -0,0 +1,108 @@
+package discography
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"dab-downloader/internal/shared"
+)
+
+func testDiscography() []shared.Album {
+	hiRes := shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96, IsHiRes: true}
+	cd := shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}
+	return []shared.Album{
+		{ID: "debut", Title: "Debut", Type: "album", Year: "2005", TotalTracks: 11, AudioQuality: cd, Label: "Indie Records"},
+		{ID: "live", Title: "Live at the Forum", Type: "album", ReleaseDate: "2012-04-01", TotalTracks: 18, AudioQuality: hiRes, Label: map[string]interface{}{"name": "Big Label"}},
+		{ID: "second", Title: "Second", Type: "album", Year: "2014", TotalTracks: 10, AudioQuality: hiRes, ParentalWarning: true, Label: "Big Label"},
+		{ID: "ep", Title: "Remixes", Type: "ep", Year: "2015", TotalTracks: 5, AudioQuality: cd},
+		{ID: "single", Title: "Hit (Radio Edit)", Type: "single", Year: "2016", TotalTracks: 1, AudioQuality: hiRes},
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{"", []string{"debut", "live", "second", "ep", "single"}},
+		{"all", []string{"debut", "live", "second", "ep", "single"}},
+		{"albums", []string{"debut", "live", "second"}},
+		{"albums,eps", []string{"debut", "live", "second", "ep"}},
+		{"albums, singles", []string{"debut", "live", "second", "single"}},
+		{"type:ep,single", []string{"ep", "single"}},
+		{"-singles", []string{"debut", "live", "second", "ep"}},
+		{"year:2010-2015", []string{"live", "second", "ep"}},
+		{"year:2015-2010", []string{"live", "second", "ep"}},
+		{"year:2014-", []string{"second", "ep", "single"}},
+		{"year:-2012", []string{"debut", "live"}},
+		{"year:2005", []string{"debut"}},
+		{"bitdepth:24", []string{"live", "second", "single"}},
+		{"hires albums", []string{"live", "second"}},
+		{"explicit", []string{"second"}},
+		{"clean albums", []string{"debut", "live"}},
+		{"-title:live -title:remix", []string{"debut", "second", "single"}},
+		{`title:"radio edit"`, []string{"single"}},
+		{"label:big", []string{"live", "second"}},
+		{"tracks:5-11", []string{"debut", "second", "ep"}},
+		{"albums year:2010- -title:live", []string{"second"}},
+	}
+
+	albums := testDiscography()
+	for _, test := range tests {
+		filter, err := ParseFilter(test.expression, nil)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) failed: %v", test.expression, err)
+			continue
+		}
+		if got := albumIDs(filter.Apply(albums)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseFilter(%q) kept %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestParseFilterPresets(t *testing.T) {
+	presets := map[string]string{
+		"studio": "albums -title:live",
+		"recent": "preset:studio year:2010-",
+		"loop":   "preset:loop",
+	}
+
+	filter, err := ParseFilter("recent", presets)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	if got := albumIDs(filter.Apply(testDiscography())); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("preset kept %v, want [second]", got)
+	}
+	if got := filter.String(); got != "albums -title:live year:2010-" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := ParseFilter("loop", presets); err == nil || !strings.Contains(err.Error(), "too deeply") {
+		t.Errorf("recursive preset error = %v", err)
+	}
+	if _, err := ParseFilter("-studio", presets); err == nil {
+		t.Error("negated preset was accepted")
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expression := range []string{
+		"compilations",
+		"albums,compilations",
+		"year:soon",
+		"tracks:-",
+		"bitdepth:high",
+		"title:(",
+		"hires:maybe",
+		"label:",
+		"color:red",
+		`title:"unterminated`,
+		"preset:missing",
+	} {
+		if _, err := ParseFilter(expression, nil); err == nil {
+			t.Errorf("ParseFilter(%q) succeeded", expression)
+		}
+	}
+}
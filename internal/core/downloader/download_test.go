@@ -0,0 +1,102 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"dab-downloader/internal/api/dab"
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+// newStreamServer serves half of a file and then stalls until the client goes away
+func newStreamServer(t *testing.T, data []byte, stall bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if !stall {
+			w.Write(data)
+			return
+		}
+		w.Write(data[:len(data)/2])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCreateWorkFile(t *testing.T) {
+	td := NewTrackDownloader(dab.NewDabAPI("http://localhost", "", http.DefaultClient), &config.Config{})
+	outputPath := filepath.Join(t.TempDir(), "Artist", "01 - Song.mp3")
+
+	workPath, err := td.createWorkFile(outputPath)
+	if err != nil {
+		t.Fatalf("createWorkFile failed: %v", err)
+	}
+	name := filepath.Base(workPath)
+	if filepath.Dir(workPath) != filepath.Dir(outputPath) || filepath.Ext(name) != ".flac" || !shared.IsTempFile(name) {
+		t.Errorf("unexpected work file %s", workPath)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("final file should not exist yet: %v", err)
+	}
+}
+
+func TestPerformDownloadRemovesFileWhenCancelled(t *testing.T) {
+	server := newStreamServer(t, make([]byte, 1<<20), true)
+	td := NewTrackDownloader(dab.NewDabAPI(server.URL, "", server.Client()), &config.Config{})
+	workPath := filepath.Join(t.TempDir(), ".track.dab-tmp-1.flac")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// Cancel once part of the body has been written
+	go func() {
+		for ctx.Err() == nil {
+			if info, err := os.Stat(workPath); err == nil && info.Size() > 0 {
+				cancel()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	if _, _, err := td.performDownload(ctx, server.URL, DownloadOptions{OutputPath: workPath}, nil); err == nil {
+		t.Fatal("expected the cancelled download to fail")
+	}
+	if _, err := os.Stat(workPath); !os.IsNotExist(err) {
+		t.Errorf("partial file should be removed after cancelling: %v", err)
+	}
+}
+
+func TestPerformDownloadWritesCompleteFile(t *testing.T) {
+	data := make([]byte, 4096)
+	server := newStreamServer(t, data, false)
+	td := NewTrackDownloader(dab.NewDabAPI(server.URL, "", server.Client()), &config.Config{})
+
+	workPath := filepath.Join(t.TempDir(), ".track.dab-tmp-1.flac")
+	if _, _, err := td.performDownload(context.Background(), server.URL, DownloadOptions{OutputPath: workPath}, nil); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if info, err := os.Stat(workPath); err != nil || info.Size() != int64(len(data)) {
+		t.Fatalf("downloaded file missing or short: %v", err)
+	}
+}
+
+func TestVerifyMetadataFollowsSetting(t *testing.T) {
+	td := NewTrackDownloader(dab.NewDabAPI("http://localhost", t.TempDir(), http.DefaultClient), &config.Config{})
+	missing := filepath.Join(t.TempDir(), "missing.flac")
+	track := shared.Track{Title: "Song"}
+
+	// The size check runs on every download; reading the tags back only with VerifyDownloads
+	if err := td.verifyMetadata(missing, track, nil, DownloadOptions{VerifyDownloads: true}); err != nil {
+		t.Errorf("tags were checked without VerifyMetadata: %v", err)
+	}
+	if err := td.verifyMetadata(missing, track, nil, DownloadOptions{VerifyMetadata: true}); err == nil {
+		t.Error("expected the tag check to fail for a missing file")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	
 	"github.com/cheggaaa/pb/v3"
 	
@@ -22,6 +23,7 @@ const (
 	DefaultMaxRetries = 3
 	DefaultRetryDelay = 5
 	DefaultFileMode   = 0755
+	AudioFileMode     = 0644
 )
 
 // DownloadOptions holds configuration for track downloads
@@ -32,6 +34,7 @@ type DownloadOptions struct {
 	Debug            bool
 	MaxRetries       int
 	VerifyDownloads  bool
+	VerifyMetadata   bool // Read the tags back after writing them, the VerifyDownloads setting
 }
 
 // DownloadResult contains information about a completed download
@@ -56,9 +59,12 @@ type TrackDownloader struct {
 
 // NewTrackDownloader creates a new track downloader with the given API client
 func NewTrackDownloader(api *dab.DabAPI, cfg *config.Config) *TrackDownloader {
+	metadataProcessor := NewMetadataProcessor()
+	metadataProcessor.ApplyConfig(cfg)
+
 	return &TrackDownloader{
 		api:               api,
-		metadataProcessor: NewMetadataProcessor(),
+		metadataProcessor: metadataProcessor,
 		config:            cfg,
 		debug:             false,
 	}
@@ -87,9 +93,19 @@ func (td *TrackDownloader) DownloadTrack(ctx context.Context, track shared.Track
 		return nil, fmt.Errorf("failed to get stream URL: %w", err)
 	}
 
+	// Download, tag and convert a temporary file that only gets the real name once it is done,
+	// so a file at the real name is always a complete, tagged track
+	workPath, err := td.createWorkFile(options.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+	workOptions := options
+	workOptions.OutputPath = workPath
+
 	// Download the audio file
-	downloadResult, err := td.downloadAudioFile(ctx, streamURL, options, progressBar)
+	downloadResult, err := td.downloadAudioFile(ctx, streamURL, workOptions, progressBar)
 	if err != nil {
+		td.cleanup(workPath)
 		return nil, fmt.Errorf("failed to download audio: %w", err)
 	}
 
@@ -99,6 +115,12 @@ func (td *TrackDownloader) DownloadTrack(ctx context.Context, track shared.Track
 		return nil, fmt.Errorf("failed to add metadata: %w", err)
 	}
 
+	// Check that the tags were written as expected
+	if err := td.verifyMetadata(downloadResult.FilePath, track, album, options); err != nil {
+		td.cleanup(downloadResult.FilePath)
+		return nil, err
+	}
+
 	// Convert format if needed
 	finalResult, err := td.convertIfNeeded(downloadResult, options)
 	if err != nil {
@@ -106,6 +128,13 @@ func (td *TrackDownloader) DownloadTrack(ctx context.Context, track shared.Track
 		return nil, fmt.Errorf("failed to convert track: %w", err)
 	}
 
+	// Move the finished track into place
+	if err := shared.CommitFile(finalResult.FilePath, options.OutputPath, AudioFileMode); err != nil {
+		td.cleanup(finalResult.FilePath)
+		return nil, err
+	}
+	finalResult.FilePath = options.OutputPath
+
 	return finalResult, nil
 }
 
@@ -118,6 +147,7 @@ func (td *TrackDownloader) DownloadTrackWithDefaults(ctx context.Context, track
 		Debug:           td.debug,
 		MaxRetries:      td.getMaxRetries(),
 		VerifyDownloads: td.getVerifyDownloads(),
+		VerifyMetadata:  td.getVerifyDownloads(),
 	}
 
 	return td.DownloadTrack(ctx, track, album, options, coverData, nil, warningCollector)
@@ -160,7 +190,7 @@ func (td *TrackDownloader) downloadAudioFile(ctx context.Context, streamURL stri
 		maxRetries = td.getMaxRetries()
 	}
 
-	err := shared.RetryWithBackoff(maxRetries, DefaultRetryDelay, func() error {
+	err := shared.RetryWithBackoffContext(ctx, maxRetries, DefaultRetryDelay, func() error {
 		downloadResult, expectedSize, err := td.performDownload(ctx, streamURL, options, progressBar)
 		if err != nil {
 			return err
@@ -239,6 +269,8 @@ func (td *TrackDownloader) setupProgressTracking(body io.ReadCloser, contentLeng
 		shared.ColorDebug.Println("DEBUG: Setting up progress tracking")
 	}
 
+	// Start over when a failed attempt is retried
+	progressBar.SetCurrent(0)
 	if contentLength <= 0 {
 		progressBar.Set("indeterminate", true)
 	} else {
@@ -248,6 +280,21 @@ func (td *TrackDownloader) setupProgressTracking(body io.ReadCloser, contentLeng
 	return progressBar.NewProxyReader(body)
 }
 
+// createWorkFile creates the temporary FLAC file a track is downloaded to, next to its final path
+func (td *TrackDownloader) createWorkFile(outputPath string) (string, error) {
+	if err := td.createOutputDirectory(outputPath); err != nil {
+		return "", err
+	}
+
+	flacPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".flac"
+	f, err := shared.CreateTempFile(flacPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for %s: %w", filepath.Base(outputPath), err)
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
 // createOutputDirectory ensures the output directory exists
 func (td *TrackDownloader) createOutputDirectory(outputPath string) error {
 	dir := filepath.Dir(outputPath)
@@ -310,6 +357,19 @@ func (td *TrackDownloader) verifyDownload(filePath string, expectedSize int64, o
 	return nil
 }
 
+// verifyMetadata checks the tags of the downloaded file against the track when VerifyMetadata is set
+func (td *TrackDownloader) verifyMetadata(filePath string, track shared.Track, album *shared.Album, options DownloadOptions) error {
+	if !options.VerifyMetadata {
+		return nil
+	}
+
+	if err := ValidateTrackMetadata(filePath, track, album); err != nil {
+		return fmt.Errorf("post-download verification failed: %w", err)
+	}
+
+	return nil
+}
+
 // addMetadata adds metadata to the downloaded file
 func (td *TrackDownloader) addMetadata(filePath string, track shared.Track, album *shared.Album, coverData []byte, warningCollector *shared.WarningCollector) error {
 	totalTracks := 0
@@ -414,6 +474,7 @@ func DownloadTrack(ctx context.Context, api *dab.DabAPI, track shared.Track, alb
 		Debug:           debug,
 		MaxRetries:      0, // Use config default
 		VerifyDownloads: true,
+		VerifyMetadata:  globalDownloader.getVerifyDownloads(),
 	}
 
 	result, err := globalDownloader.DownloadTrack(ctx, track, album, options, coverData, bar, warningCollector)
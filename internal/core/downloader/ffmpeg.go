@@ -33,8 +33,15 @@ func ConvertTrack(inputFile, format, bitrate string) (string, error) {
 		return "", fmt.Errorf("unsupported format: %s", format)
 	}
 
+	_, statErr := os.Stat(outputFile)
+	existed := statErr == nil
+
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		// Don't leave a partly converted file that would be taken for a finished track
+		if !existed {
+			os.Remove(outputFile)
+		}
 		return "", fmt.Errorf("failed to convert track: %w\nffmpeg output: %s", err, string(output))
 	}
 
@@ -44,4 +51,29 @@ func ConvertTrack(inputFile, format, bitrate string) (string, error) {
 	}
 
 	return outputFile, nil
-}
\ No newline at end of file
+}
+
+// WriteTags replaces the tags of a lossy audio file using ffmpeg without re-encoding.
+// Multiple values of a field are joined with "; ".
+func WriteTags(filePath string, tags map[string][]string) error {
+	ext := filepath.Ext(filePath)
+	tempFile := strings.TrimSuffix(filePath, ext) + ".tags" + ext
+
+	args := []string{"-y", "-i", filePath, "-map", "0:a", "-c", "copy", "-map_metadata", "-1"}
+	for field, values := range tags {
+		args = append(args, "-metadata", field+"="+strings.Join(values, "; "))
+	}
+	args = append(args, tempFile)
+
+	output, err := exec.Command("ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to write tags: %w\nffmpeg output: %s", err, string(output))
+	}
+
+	if err := os.Rename(tempFile, filePath); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to replace file with tagged copy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+)
+
+// DefaultFpcalcPath is the Chromaprint command line tool looked up in PATH
+const DefaultFpcalcPath = "fpcalc"
+
+// AudioFingerprint holds a Chromaprint fingerprint and the duration it was computed over
+type AudioFingerprint struct {
+	Duration    int    // Duration in whole seconds, as expected by AcoustID
+	Fingerprint string // Compressed, base64 encoded fingerprint
+}
+
+// CheckFpcalc checks if fpcalc is installed and available at the given path or in the system's PATH.
+func CheckFpcalc(fpcalcPath string) bool {
+	if fpcalcPath == "" {
+		fpcalcPath = DefaultFpcalcPath
+	}
+	_, err := exec.LookPath(fpcalcPath)
+	return err == nil
+}
+
+// ComputeFingerprint computes the Chromaprint fingerprint of an audio file using fpcalc.
+func ComputeFingerprint(fpcalcPath, filePath string) (*AudioFingerprint, error) {
+	if fpcalcPath == "" {
+		fpcalcPath = DefaultFpcalcPath
+	}
+
+	output, err := exec.Command(fpcalcPath, "-json", filePath).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to fingerprint track: %w\nfpcalc output: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("failed to fingerprint track: %w", err)
+	}
+
+	return parseFpcalcOutput(output)
+}
+
+// parseFpcalcOutput parses the JSON output of fpcalc -json
+func parseFpcalcOutput(output []byte) (*AudioFingerprint, error) {
+	var result struct {
+		Duration    float64 `json:"duration"`
+		Fingerprint string  `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse fpcalc output: %w", err)
+	}
+	if result.Fingerprint == "" {
+		return nil, fmt.Errorf("fpcalc returned an empty fingerprint")
+	}
+
+	return &AudioFingerprint{
+		Duration:    int(math.Round(result.Duration)),
+		Fingerprint: result.Fingerprint,
+	}, nil
+}
@@ -0,0 +1,176 @@
+package downloader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-flac/flacvorbis"
+
+	"dab-downloader/internal/api/acoustid"
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+func TestParseFpcalcOutput(t *testing.T) {
+	fingerprint, err := parseFpcalcOutput([]byte(`{"duration": 214.63, "fingerprint": "AQADtEmUaEkSRZEGAA"}`))
+	if err != nil {
+		t.Fatalf("Failed to parse fpcalc output: %v", err)
+	}
+	if fingerprint.Duration != 215 {
+		t.Errorf("Expected duration 215, got %d", fingerprint.Duration)
+	}
+	if fingerprint.Fingerprint != "AQADtEmUaEkSRZEGAA" {
+		t.Errorf("Unexpected fingerprint %q", fingerprint.Fingerprint)
+	}
+
+	if _, err := parseFpcalcOutput([]byte(`{"duration": 10}`)); err == nil {
+		t.Error("Expected an error for a missing fingerprint")
+	}
+	if _, err := parseFpcalcOutput([]byte(`not json`)); err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+}
+
+// writeFakeFpcalc creates a script that prints a fixed fpcalc -json result
+func writeFakeFpcalc(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake fpcalc script requires a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "fpcalc")
+	script := "#!/bin/sh\necho '{\"duration\": 180.2, \"fingerprint\": \"AQAAfake\"}'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake fpcalc: %v", err)
+	}
+	return path
+}
+
+// newFingerprintTestProcessor wires a processor to local AcoustID and MusicBrainz stubs
+func newFingerprintTestProcessor(t *testing.T, score string) *MetadataProcessor {
+	t.Helper()
+
+	acoustIDServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("fingerprint") != "AQAAfake" || r.Form.Get("duration") != "180" {
+			t.Errorf("Unexpected lookup form: %v", r.Form)
+		}
+		w.Write([]byte(`{"status":"ok","results":[{"id":"acoustid-1","score":` + score + `,"recordings":[{"id":"recording-1"}]}]}`))
+	}))
+	t.Cleanup(acoustIDServer.Close)
+
+	musicBrainzServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Searches of the title fallback after a rejected match find nothing
+		if strings.HasSuffix(r.URL.Path, "/recording") || strings.HasSuffix(r.URL.Path, "/release") {
+			w.Write([]byte(`{"count":0,"recordings":[],"releases":[]}`))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/recording/recording-1") {
+			t.Errorf("Unexpected MusicBrainz request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":"recording-1","title":"Song",
+			"artist-credit":[{"artist":{"id":"artist-1","name":"Artist"}}],
+			"releases":[{"id":"release-1","title":"Album","artist-credit":[{"artist":{"id":"artist-1"}}],"release-group":{"id":"group-1"}}]}`))
+	}))
+	t.Cleanup(musicBrainzServer.Close)
+
+	processor := NewMetadataProcessor()
+	mbConfig := processor.mbClient.GetConfig()
+	mbConfig.BaseURL = musicBrainzServer.URL + "/ws/2/"
+	mbConfig.RateLimit = time.Millisecond
+	mbConfig.MaxRetries = 1
+	processor.mbClient.UpdateConfig(mbConfig)
+
+	acoustIDConfig := acoustid.DefaultConfig()
+	acoustIDConfig.BaseURL = acoustIDServer.URL
+	acoustIDConfig.RateLimit = time.Millisecond
+	acoustIDConfig.MaxRetries = 1
+	processor.SetAcoustIDFallback(acoustid.NewClientWithConfig(acoustIDConfig), writeFakeFpcalc(t), 0.8)
+
+	return processor
+}
+
+func TestGetFingerprintMetadata(t *testing.T) {
+	processor := newFingerprintTestProcessor(t, "0.93")
+
+	metadata, score, err := processor.GetFingerprintMetadata("track.flac", 0)
+	if err != nil {
+		t.Fatalf("Fingerprint lookup failed: %v", err)
+	}
+	if score != 0.93 {
+		t.Errorf("Expected score 0.93, got %.2f", score)
+	}
+
+	expected := ISRCMetadata{
+		ReleaseID:       "release-1",
+		ReleaseArtistID: "artist-1",
+		ReleaseGroupID:  "group-1",
+		TrackID:         "recording-1",
+		TrackArtistID:   "artist-1",
+	}
+	if *metadata != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *metadata)
+	}
+}
+
+func TestGetFingerprintMetadataBelowMinScore(t *testing.T) {
+	processor := newFingerprintTestProcessor(t, "0.55")
+
+	if _, score, err := processor.GetFingerprintMetadata("track.flac", 0); err == nil {
+		t.Error("Expected low scoring match to be rejected")
+	} else if score != 0.55 {
+		t.Errorf("Expected rejected score 0.55 to be reported, got %.2f", score)
+	}
+}
+
+func TestGetFingerprintMetadataDisabled(t *testing.T) {
+	processor := NewMetadataProcessor()
+	if _, _, err := processor.GetFingerprintMetadata("track.flac", 0); err == nil {
+		t.Error("Expected an error when the AcoustID fallback is disabled")
+	}
+}
+
+func TestAddMusicBrainzMetadataFromFingerprint(t *testing.T) {
+	track := shared.Track{Title: "Song", Artist: "Artist"}
+
+	processor := newFingerprintTestProcessor(t, "0.93")
+	warnings := shared.NewWarningCollector(true)
+	comment := flacvorbis.New()
+	processor.addMusicBrainzMetadata(comment, "track.flac", track, nil, warnings)
+
+	tags := VorbisCommentFields(comment)
+	if firstTag(tags, "MUSICBRAINZ_TRACKID") != "recording-1" || firstTag(tags, "MUSICBRAINZ_ALBUMID") != "release-1" {
+		t.Errorf("Expected the fingerprinted recording to be tagged, got %v", tags)
+	}
+	if matches := warnings.GetWarningsByType()[shared.AcoustIDMatchWarning]; len(matches) != 1 {
+		t.Errorf("Expected one AcoustID match in the summary, got %v", matches)
+	}
+
+	processor = newFingerprintTestProcessor(t, "0.55")
+	warnings = shared.NewWarningCollector(true)
+	comment = flacvorbis.New()
+	processor.addMusicBrainzMetadata(comment, "track.flac", track, nil, warnings)
+
+	if id := firstTag(VorbisCommentFields(comment), "MUSICBRAINZ_TRACKID"); id == "recording-1" {
+		t.Error("Expected the low scoring match not to be tagged")
+	}
+	rejected := warnings.GetWarningsByType()[shared.AcoustIDLowScoreWarning]
+	if len(rejected) != 1 || !strings.Contains(rejected[0].Details, "score 0.55") {
+		t.Errorf("Expected the rejected match in the summary, got %v", rejected)
+	}
+}
+
+func TestApplyConfigWithoutFpcalc(t *testing.T) {
+	processor := NewMetadataProcessor()
+	processor.ApplyConfig(&config.Config{AcoustIDFallback: true, FpcalcPath: filepath.Join(t.TempDir(), "missing-fpcalc")})
+	if processor.acoustIDClient != nil {
+		t.Error("Expected the AcoustID fallback to stay off without fpcalc")
+	}
+}
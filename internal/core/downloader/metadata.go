@@ -2,7 +2,10 @@ package downloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -11,6 +14,8 @@ import (
 	"github.com/go-flac/flacvorbis"
 	
 	"dab-downloader/internal/shared"
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/api/acoustid"
 	"dab-downloader/internal/api/musicbrainz"
 )
 
@@ -22,8 +27,15 @@ const (
 	DefaultEncoder = "EnhancedFLACDownloader/2.0"
 	DefaultEncoding = "FLAC"
 	DefaultSource = "DAB"
+	DefaultAcoustIDMinScore = 0.8
 )
 
+// errAcoustIDScoreTooLow marks an AcoustID match rejected for scoring below the minimum
+var errAcoustIDScoreTooLow = errors.New("AcoustID match score too low")
+
+// fpcalcWarning reports a missing fpcalc once, however often the configuration is applied
+var fpcalcWarning sync.Once
+
 // ISRCMetadata holds comprehensive metadata extracted from ISRC lookup
 type ISRCMetadata struct {
 	ReleaseID        string
@@ -41,8 +53,17 @@ type ISRCMetadata struct {
 
 // MetadataProcessor handles FLAC metadata operations
 type MetadataProcessor struct {
-	mbClient *musicbrainz.Client
-	cache    *AlbumMetadataCache
+	mbClient       *musicbrainz.Client
+	cache          *AlbumMetadataCache
+	releaseTagging bool // Tag from the full matched release instead of only writing MusicBrainz IDs
+
+	// AcoustID fingerprint fallback, nil when disabled
+	acoustIDClient   *acoustid.Client
+	acoustIDMinScore float64
+	fpcalcPath       string
+
+	// Review of ambiguous release selections, nil when disabled
+	reviewer *ReleaseReviewer
 }
 
 // NewMetadataProcessor creates a new metadata processor with default settings
@@ -56,6 +77,56 @@ func NewMetadataProcessor() *MetadataProcessor {
 // SetDebugMode enables or disables debug mode for MusicBrainz client
 func (mp *MetadataProcessor) SetDebugMode(debug bool) {
 	mp.mbClient.SetDebug(debug)
+	if mp.acoustIDClient != nil {
+		mp.acoustIDClient.SetDebug(debug)
+	}
+}
+
+// SetReleaseTagging enables or disables tagging from the full MusicBrainz release
+func (mp *MetadataProcessor) SetReleaseTagging(enabled bool) {
+	mp.releaseTagging = enabled
+}
+
+// ApplyConfig applies the metadata related settings from the application config
+func (mp *MetadataProcessor) ApplyConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	mp.SetReleaseTagging(cfg.MusicBrainzReleaseTags)
+
+	if cfg.AcoustIDFallback && !CheckFpcalc(cfg.FpcalcPath) {
+		fpcalcWarning.Do(func() {
+			shared.ColorWarning.Println("⚠️ AcoustIDFallback is enabled but fpcalc was not found, tracks are not fingerprinted. Install Chromaprint or set FpcalcPath.")
+		})
+		mp.SetAcoustIDFallback(nil, "", 0)
+	} else if cfg.AcoustIDFallback {
+		acoustIDConfig := acoustid.DefaultConfig()
+		acoustIDConfig.APIKey = cfg.AcoustIDAPIKey
+		if cfg.AcoustIDURL != "" {
+			acoustIDConfig.BaseURL = cfg.AcoustIDURL
+		}
+		mp.SetAcoustIDFallback(acoustid.NewClientWithConfig(acoustIDConfig), cfg.FpcalcPath, cfg.AcoustIDMinScore)
+	} else {
+		mp.SetAcoustIDFallback(nil, "", 0)
+	}
+
+	mp.SetReleaseReviewer(NewReleaseReviewer(cfg.ReleaseReview, cfg.ReleaseReviewMargin, cfg.ReleaseReviewCandidates, cfg.ReleaseReviewReport))
+}
+
+// SetReleaseReviewer sets the reviewer consulted when the release selection is ambiguous, nil disables it
+func (mp *MetadataProcessor) SetReleaseReviewer(reviewer *ReleaseReviewer) {
+	mp.reviewer = reviewer
+}
+
+// SetAcoustIDFallback enables fingerprint identification for tracks without an ISRC match.
+// Passing a nil client disables the fallback.
+func (mp *MetadataProcessor) SetAcoustIDFallback(client *acoustid.Client, fpcalcPath string, minScore float64) {
+	if minScore <= 0 {
+		minScore = DefaultAcoustIDMinScore
+	}
+	mp.acoustIDClient = client
+	mp.fpcalcPath = fpcalcPath
+	mp.acoustIDMinScore = minScore
 }
 
 // ============================================================================
@@ -144,7 +215,7 @@ func (mp *MetadataProcessor) AddMetadataWithDebug(filePath string, track shared.
 		return err
 	}
 
-	comment := mp.buildVorbisComment(track, album, totalTracks, warningCollector)
+	comment := mp.buildVorbisComment(filePath, track, album, totalTracks, warningCollector)
 	
 	vorbisCommentBlock := comment.Marshal()
 	f.Meta = append(f.Meta, &vorbisCommentBlock)
@@ -194,6 +265,64 @@ func (mp *MetadataProcessor) GetISRCMetadataWithTrackCount(isrc string, expected
 		return nil, err
 	}
 	
+	return mp.metadataFromRecording(mbTrack, mp.selectBestTrackRelease(mbTrack.Releases, expectedTrackCount)), nil
+}
+
+// GetISRCMetadataForAlbum extracts metadata from ISRC lookup, selecting the release in the context of the album.
+// Ambiguous selections go through the release reviewer when one is configured.
+func (mp *MetadataProcessor) GetISRCMetadataForAlbum(isrc string, track shared.Track, album *shared.Album) (*ISRCMetadata, error) {
+	mbTrack, err := mp.mbClient.SearchTrackByISRC(context.Background(), isrc)
+	if err != nil {
+		return nil, err
+	}
+	return mp.metadataFromRecording(mbTrack, mp.selectTrackReleaseForAlbum(mbTrack.Releases, track, album)), nil
+}
+
+// GetFingerprintMetadata identifies an audio file through its AcoustID fingerprint and resolves the
+// matched recording the same way as an ISRC lookup. It also returns the AcoustID match score.
+func (mp *MetadataProcessor) GetFingerprintMetadata(filePath string, expectedTrackCount int) (*ISRCMetadata, float64, error) {
+	mbTrack, score, err := mp.identifyRecording(filePath)
+	if err != nil {
+		return nil, score, err
+	}
+	return mp.metadataFromRecording(mbTrack, mp.selectBestTrackRelease(mbTrack.Releases, expectedTrackCount)), score, nil
+}
+
+// identifyRecording looks up the MusicBrainz recording of an audio file through its AcoustID fingerprint
+func (mp *MetadataProcessor) identifyRecording(filePath string) (*musicbrainz.Track, float64, error) {
+	if mp.acoustIDClient == nil {
+		return nil, 0, fmt.Errorf("AcoustID fallback is not enabled")
+	}
+
+	fingerprint, err := ComputeFingerprint(mp.fpcalcPath, filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx := context.Background()
+	results, err := mp.acoustIDClient.Lookup(ctx, fingerprint.Fingerprint, fingerprint.Duration)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	recording, score, ok := acoustid.BestRecording(results)
+	if !ok {
+		return nil, 0, fmt.Errorf("no AcoustID match with a MusicBrainz recording")
+	}
+	if score < mp.acoustIDMinScore {
+		return nil, score, fmt.Errorf("best match scored %.2f, below the minimum of %.2f: %w", score, mp.acoustIDMinScore, errAcoustIDScoreTooLow)
+	}
+
+	mbTrack, err := mp.mbClient.GetTrackMetadata(ctx, recording.ID)
+	if err != nil {
+		return nil, score, err
+	}
+
+	return mbTrack, score, nil
+}
+
+// metadataFromRecording extracts MusicBrainz identifiers from a recording and its selected release
+func (mp *MetadataProcessor) metadataFromRecording(mbTrack *musicbrainz.Track, selectedRelease musicbrainz.TrackRelease) *ISRCMetadata {
 	metadata := &ISRCMetadata{
 		TrackID: mbTrack.ID,
 	}
@@ -203,7 +332,6 @@ func (mp *MetadataProcessor) GetISRCMetadataWithTrackCount(isrc string, expected
 	}
 	
 	if len(mbTrack.Releases) > 0 {
-		selectedRelease := mp.selectBestTrackRelease(mbTrack.Releases, expectedTrackCount)
 		metadata.ReleaseID = selectedRelease.ID
 		metadata.ReleaseGroupID = selectedRelease.ReleaseGroup.ID
 		
@@ -212,7 +340,7 @@ func (mp *MetadataProcessor) GetISRCMetadataWithTrackCount(isrc string, expected
 		}
 	}
 	
-	return metadata, nil
+	return metadata
 }
 
 // ClearCache clears the metadata cache
@@ -225,6 +353,64 @@ func (mp *MetadataProcessor) GetCacheStats() (int, []string) {
 	return mp.cache.GetStats()
 }
 
+// BuildVorbisComment builds the Vorbis comment block the processor would write for a track, without touching the file
+func (mp *MetadataProcessor) BuildVorbisComment(filePath string, track shared.Track, album *shared.Album, totalTracks int, warningCollector *shared.WarningCollector) *flacvorbis.MetaDataBlockVorbisComment {
+	return mp.buildVorbisComment(filePath, track, album, totalTracks, warningCollector)
+}
+
+// ReadVorbisComment reads the Vorbis comment block of a FLAC file, returning an empty block if there is none
+func ReadVorbisComment(filePath string) (*flacvorbis.MetaDataBlockVorbisComment, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	defer file.Close()
+
+	f, err := flac.ParseMetadata(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FLAC metadata: %w", err)
+	}
+
+	for _, block := range f.Meta {
+		if block.Type == flac.VorbisComment {
+			comment, err := flacvorbis.ParseFromMetaDataBlock(*block)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Vorbis comment: %w", err)
+			}
+			return comment, nil
+		}
+	}
+	return flacvorbis.New(), nil
+}
+
+// ReplaceVorbisComment swaps the Vorbis comment block of a FLAC file, keeping pictures, other blocks and audio frames as they are
+func (mp *MetadataProcessor) ReplaceVorbisComment(filePath string, comment *flacvorbis.MetaDataBlockVorbisComment) error {
+	f, err := flac.ParseFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	vorbisCommentBlock := comment.Marshal()
+	replaced := false
+	var newMetaData []*flac.MetaDataBlock
+	for _, block := range f.Meta {
+		if block.Type == flac.VorbisComment {
+			if !replaced {
+				newMetaData = append(newMetaData, &vorbisCommentBlock)
+				replaced = true
+			}
+			continue
+		}
+		newMetaData = append(newMetaData, block)
+	}
+	if !replaced {
+		newMetaData = append(newMetaData, &vorbisCommentBlock)
+	}
+	f.Meta = newMetaData
+
+	return mp.saveFLACFile(f, filePath)
+}
+
 // ============================================================================
 // 5. Private Core Methods
 // ============================================================================
@@ -249,7 +435,7 @@ func (mp *MetadataProcessor) openAndCleanFLACFile(filePath string) (*flac.File,
 }
 
 // buildVorbisComment creates a comprehensive Vorbis comment block
-func (mp *MetadataProcessor) buildVorbisComment(track shared.Track, album *shared.Album, totalTracks int, warningCollector *shared.WarningCollector) *flacvorbis.MetaDataBlockVorbisComment {
+func (mp *MetadataProcessor) buildVorbisComment(filePath string, track shared.Track, album *shared.Album, totalTracks int, warningCollector *shared.WarningCollector) *flacvorbis.MetaDataBlockVorbisComment {
 	comment := flacvorbis.New()
 
 	// Essential metadata
@@ -265,10 +451,13 @@ func (mp *MetadataProcessor) buildVorbisComment(track shared.Track, album *share
 	mp.addExtendedMetadata(comment, track, album)
 	
 	// MusicBrainz metadata
-	mp.addMusicBrainzMetadata(comment, track, album, warningCollector)
+	mp.addMusicBrainzMetadata(comment, filePath, track, album, warningCollector)
 	
 	// Technical metadata
 	mp.addTechnicalMetadata(comment, track)
+	
+	// DAB source identifiers
+	mp.addSourceMetadata(comment, filePath, track, album)
 
 	return comment
 }
@@ -368,19 +557,33 @@ func (mp *MetadataProcessor) addTechnicalMetadata(comment *flacvorbis.MetaDataBl
 }
 
 // addMusicBrainzMetadata handles MusicBrainz metadata fetching with caching
-func (mp *MetadataProcessor) addMusicBrainzMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, track shared.Track, album *shared.Album, warningCollector *shared.WarningCollector) {
+func (mp *MetadataProcessor) addMusicBrainzMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, filePath string, track shared.Track, album *shared.Album, warningCollector *shared.WarningCollector) {
 	albumTitle := getAlbumTitle(track, album)
 	
 	// Try ISRC-based metadata first
 	if track.ISRC != "" {
-		expectedTrackCount := mp.getExpectedTrackCount(album)
-		
-		if isrcMetadata, err := mp.GetISRCMetadataWithTrackCount(track.ISRC, expectedTrackCount); err == nil {
-			mp.addISRCMetadataFields(comment, isrcMetadata)
+		if isrcMetadata, err := mp.GetISRCMetadataForAlbum(track.ISRC, track, album); err == nil {
+			mp.addRecordingMetadata(comment, isrcMetadata, track, album, warningCollector)
 			return
 		}
 	}
 	
+	// Then identify the audio itself through its AcoustID fingerprint
+	if mp.acoustIDClient != nil && filePath != "" {
+		mbTrack, score, err := mp.identifyRecording(filePath)
+		if err == nil {
+			fingerprintMetadata := mp.metadataFromRecording(mbTrack, mp.selectTrackReleaseForAlbum(mbTrack.Releases, track, album))
+			mp.addRecordingMetadata(comment, fingerprintMetadata, track, album, warningCollector)
+			if warningCollector != nil {
+				warningCollector.AddAcoustIDMatchWarning(track.Artist, track.Title, score)
+			}
+			return
+		}
+		if errors.Is(err, errAcoustIDScoreTooLow) && warningCollector != nil {
+			warningCollector.AddAcoustIDLowScoreWarning(track.Artist, track.Title, score, mp.acoustIDMinScore)
+		}
+	}
+	
 	// Fallback to traditional approach
 	mp.addTrackMetadata(comment, track, albumTitle, warningCollector)
 	
@@ -390,6 +593,14 @@ func (mp *MetadataProcessor) addMusicBrainzMetadata(comment *flacvorbis.MetaData
 	}
 }
 
+// addRecordingMetadata tags a track from an identified recording, whether it was found by ISRC or fingerprint
+func (mp *MetadataProcessor) addRecordingMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, metadata *ISRCMetadata, track shared.Track, album *shared.Album, warningCollector *shared.WarningCollector) {
+	mp.addISRCMetadataFields(comment, metadata)
+	if mp.releaseTagging {
+		mp.addFullReleaseMetadata(comment, metadata, track, album, warningCollector)
+	}
+}
+
 // addISRCMetadataFields adds MusicBrainz fields from ISRC metadata
 func (mp *MetadataProcessor) addISRCMetadataFields(comment *flacvorbis.MetaDataBlockVorbisComment, metadata *ISRCMetadata) {
 	addField(comment, "MUSICBRAINZ_TRACKID", metadata.TrackID)
@@ -399,6 +610,104 @@ func (mp *MetadataProcessor) addISRCMetadataFields(comment *flacvorbis.MetaDataB
 	addField(comment, "MUSICBRAINZ_RELEASEGROUPID", metadata.ReleaseGroupID)
 }
 
+// addFullReleaseMetadata fetches the matched release and overrides DAB values with its canonical tags
+func (mp *MetadataProcessor) addFullReleaseMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, metadata *ISRCMetadata, track shared.Track, album *shared.Album, warningCollector *shared.WarningCollector) {
+	if metadata.ReleaseID == "" {
+		return
+	}
+
+	albumArtist := getAlbumArtist(track, album)
+	albumTitle := getAlbumTitle(track, album)
+
+	release, err := mp.getReleaseByID(metadata.ReleaseID, albumArtist, albumTitle)
+	if err != nil {
+		// Keep the DAB values already written to the comment
+		if warningCollector != nil {
+			warningCollector.AddMusicBrainzReleaseWarning(albumArtist, albumTitle, err.Error())
+		}
+		return
+	}
+
+	mp.addReleaseTags(comment, release, metadata.TrackID)
+}
+
+// getReleaseByID returns the full release metadata for a release ID, using the album cache when possible
+func (mp *MetadataProcessor) getReleaseByID(releaseID, artist, albumTitle string) (*musicbrainz.Release, error) {
+	if cached := mp.cache.GetCachedRelease(artist, albumTitle); cached != nil && cached.ID == releaseID {
+		return cached, nil
+	}
+
+	release, err := mp.mbClient.GetReleaseMetadata(context.Background(), releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	mp.cache.SetCachedRelease(artist, albumTitle, release)
+	return release, nil
+}
+
+// addReleaseTags writes Picard-style tags from a full MusicBrainz release, replacing any DAB values.
+// Fields the release does not provide are left untouched so the DAB values remain as a fallback.
+func (mp *MetadataProcessor) addReleaseTags(comment *flacvorbis.MetaDataBlockVorbisComment, release *musicbrainz.Release, recordingID string) {
+	// Track artist credits come from the medium track pointing at our recording
+	medium, mediaTrack := findReleaseTrack(release, recordingID)
+	if mediaTrack != nil && len(mediaTrack.ArtistCredit) > 0 {
+		setField(comment, flacvorbis.FIELD_ARTIST, formatArtistCredit(mediaTrack.ArtistCredit))
+		setField(comment, "ARTISTS", artistCreditNames(mediaTrack.ArtistCredit)...)
+		setField(comment, "ARTISTSORT", formatArtistCreditSortName(mediaTrack.ArtistCredit))
+	}
+
+	// Release artist credits
+	if len(release.ArtistCredit) > 0 {
+		setField(comment, "ALBUMARTIST", formatArtistCredit(release.ArtistCredit))
+		setField(comment, "ALBUMARTISTSORT", formatArtistCreditSortName(release.ArtistCredit))
+	}
+
+	// Release event and classification
+	setField(comment, "RELEASECOUNTRY", release.Country)
+	setField(comment, "RELEASESTATUS", strings.ToLower(release.Status))
+
+	var releaseTypes []string
+	if release.ReleaseGroup.PrimaryType != "" {
+		releaseTypes = append(releaseTypes, strings.ToLower(release.ReleaseGroup.PrimaryType))
+	}
+	for _, secondaryType := range release.ReleaseGroup.SecondaryTypes {
+		releaseTypes = append(releaseTypes, strings.ToLower(secondaryType))
+	}
+	setField(comment, "RELEASETYPE", releaseTypes...)
+
+	// Label and catalog information
+	setField(comment, "BARCODE", release.Barcode)
+
+	var labels, catalogNumbers []string
+	for _, info := range release.LabelInfo {
+		labels = appendUnique(labels, info.Label.Name)
+		catalogNumbers = appendUnique(catalogNumbers, info.CatalogNumber)
+	}
+	setField(comment, "LABEL", labels...)
+	setField(comment, "CATALOGNUMBER", catalogNumbers...)
+
+	// Media format of the disc holding this track, or of the first disc
+	if medium == nil && len(release.Media) > 0 {
+		medium = &release.Media[0]
+	}
+	if medium != nil {
+		setField(comment, "MEDIA", medium.Format)
+	}
+
+	// Original release date comes from the release group
+	if originalDate := release.ReleaseGroup.FirstReleaseDate; originalDate != "" {
+		setField(comment, "ORIGINALDATE", originalDate)
+		if len(originalDate) >= 4 {
+			setField(comment, "ORIGINALYEAR", originalDate[:4])
+		}
+	}
+
+	// Text representation
+	setField(comment, "SCRIPT", release.TextRepresentation.Script)
+	setField(comment, "LANGUAGE", release.TextRepresentation.Language)
+}
+
 // addTrackMetadata adds track-level MusicBrainz metadata
 func (mp *MetadataProcessor) addTrackMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, track shared.Track, albumTitle string, warningCollector *shared.WarningCollector) {
 	var mbTrack *musicbrainz.Track
@@ -502,7 +811,8 @@ func (mp *MetadataProcessor) addCoverArt(f *flac.File, coverData []byte, warning
 
 // saveFLACFile saves the FLAC file with new metadata
 func (mp *MetadataProcessor) saveFLACFile(f *flac.File, filePath string) error {
-	if err := f.Save(filePath); err != nil {
+	// Rewrite through a temporary file so a crash never leaves a truncated FLAC behind
+	if err := shared.WriteFileAtomic(filePath, f.Marshal(), AudioFileMode); err != nil {
 		return fmt.Errorf("failed to save FLAC file with metadata: %w", err)
 	}
 	return nil
@@ -510,34 +820,54 @@ func (mp *MetadataProcessor) saveFLACFile(f *flac.File, filePath string) error {
 
 // selectBestTrackRelease chooses the most appropriate track release based on intelligent heuristics
 func (mp *MetadataProcessor) selectBestTrackRelease(releases []musicbrainz.TrackRelease, expectedTrackCount int) musicbrainz.TrackRelease {
+	if len(releases) == 0 {
+		return musicbrainz.TrackRelease{}
+	}
 	if len(releases) == 1 {
 		return releases[0]
 	}
 	
-	type scoredRelease struct {
-		release musicbrainz.TrackRelease
-		score   int
-	}
-	
-	var scoredReleases []scoredRelease
-	
+	return mp.rankTrackReleases(releases, expectedTrackCount)[0].release
+}
+
+// rankTrackReleases scores releases and sorts them by descending score, keeping the original order on ties
+func (mp *MetadataProcessor) rankTrackReleases(releases []musicbrainz.TrackRelease, expectedTrackCount int) []rankedTrackRelease {
+	ranked := make([]rankedTrackRelease, 0, len(releases))
 	for _, release := range releases {
-		score := mp.scoreTrackRelease(release, expectedTrackCount)
-		scoredReleases = append(scoredReleases, scoredRelease{
+		ranked = append(ranked, rankedTrackRelease{
 			release: release,
-			score:   score,
+			score:   mp.scoreTrackRelease(release, expectedTrackCount),
 		})
 	}
 	
-	// Find the release with the highest score
-	bestRelease := scoredReleases[0]
-	for _, sr := range scoredReleases[1:] {
-		if sr.score > bestRelease.score {
-			bestRelease = sr
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// selectTrackReleaseForAlbum picks the release for a track of an album, honouring a reviewed choice for the album
+func (mp *MetadataProcessor) selectTrackReleaseForAlbum(releases []musicbrainz.TrackRelease, track shared.Track, album *shared.Album) musicbrainz.TrackRelease {
+	expectedTrackCount := mp.getExpectedTrackCount(album)
+	if mp.reviewer == nil || len(releases) == 0 {
+		return mp.selectBestTrackRelease(releases, expectedTrackCount)
+	}
+	
+	albumArtist := getAlbumArtist(track, album)
+	albumTitle := getAlbumTitle(track, album)
+	ranked := mp.rankTrackReleases(releases, expectedTrackCount)
+	
+	if releaseID, chosen := mp.reviewer.Resolve(albumArtist, albumTitle, album, ranked); chosen {
+		for _, release := range releases {
+			if release.ID == releaseID {
+				mp.cache.SetCachedReleaseID(albumArtist, albumTitle, releaseID)
+				return release
+			}
 		}
+		// The recording does not appear on the chosen release (e.g. a bonus track), use the automatic pick
 	}
 	
-	return bestRelease.release
+	return ranked[0].release
 }
 
 // selectBestRelease chooses the most appropriate release based on intelligent heuristics
@@ -728,6 +1058,99 @@ func addField(comment *flacvorbis.MetaDataBlockVorbisComment, field, value strin
 	}
 }
 
+// setField replaces all values of a field, leaving the existing values in place if no non-empty value is given
+func setField(comment *flacvorbis.MetaDataBlockVorbisComment, field string, values ...string) {
+	hasValue := false
+	for _, value := range values {
+		if value != "" {
+			hasValue = true
+			break
+		}
+	}
+	if !hasValue {
+		return
+	}
+
+	prefix := strings.ToUpper(field) + "="
+	kept := comment.Comments[:0]
+	for _, existing := range comment.Comments {
+		if !strings.HasPrefix(strings.ToUpper(existing), prefix) {
+			kept = append(kept, existing)
+		}
+	}
+	comment.Comments = kept
+
+	for _, value := range values {
+		addField(comment, field, value)
+	}
+}
+
+// appendUnique appends a value to a slice if it is non-empty and not already present
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// findReleaseTrack locates the medium and track of a release that point at the given recording
+func findReleaseTrack(release *musicbrainz.Release, recordingID string) (*musicbrainz.Media, *musicbrainz.MediaTrack) {
+	if recordingID == "" {
+		return nil, nil
+	}
+	for i := range release.Media {
+		medium := &release.Media[i]
+		for j := range medium.Tracks {
+			if medium.Tracks[j].Recording.ID == recordingID {
+				return medium, &medium.Tracks[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// formatArtistCredit joins artist credits the way they are printed on the release (e.g. "A feat. B")
+func formatArtistCredit(credits []musicbrainz.ArtistCredit) string {
+	var builder strings.Builder
+	for _, credit := range credits {
+		name := credit.Name
+		if name == "" {
+			name = credit.Artist.Name
+		}
+		builder.WriteString(name)
+		builder.WriteString(credit.JoinPhrase)
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// formatArtistCreditSortName joins artist credits using the artists' sort names
+func formatArtistCreditSortName(credits []musicbrainz.ArtistCredit) string {
+	var builder strings.Builder
+	for _, credit := range credits {
+		name := credit.Artist.SortName
+		if name == "" {
+			name = credit.Artist.Name
+		}
+		builder.WriteString(name)
+		builder.WriteString(credit.JoinPhrase)
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// artistCreditNames returns the canonical name of every credited artist
+func artistCreditNames(credits []musicbrainz.ArtistCredit) []string {
+	var names []string
+	for _, credit := range credits {
+		names = appendUnique(names, credit.Artist.Name)
+	}
+	return names
+}
+
 // getAlbumTitle determines the best album title to use
 func getAlbumTitle(track shared.Track, album *shared.Album) string {
 	if album != nil && album.Title != "" {
@@ -8,6 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-flac/flacvorbis"
+
+	"dab-downloader/internal/api/musicbrainz"
 	"dab-downloader/internal/shared"
 )
 
@@ -493,4 +496,106 @@ func TestDigitalMediaPreference(t *testing.T) {
 	if !digitalMediaFound && !physicalMediaFound {
 		t.Log("No format information available in releases")
 	}
-}
\ No newline at end of file
+}
+
+func TestAddReleaseTags(t *testing.T) {
+	processor := NewMetadataProcessor()
+
+	release := &musicbrainz.Release{
+		ID:      "release-1",
+		Title:   "Honeymoon",
+		Status:  "Official",
+		Country: "XW",
+		Barcode: "00602547395734",
+		ArtistCredit: []musicbrainz.ArtistCredit{
+			{Name: "Lana Del Rey", Artist: musicbrainz.Artist{ID: "artist-1", Name: "Lana Del Rey", SortName: "Del Rey, Lana"}},
+		},
+		LabelInfo: []musicbrainz.LabelInfo{
+			{CatalogNumber: "4739573", Label: musicbrainz.Label{Name: "Polydor"}},
+			{CatalogNumber: "B0024056-02", Label: musicbrainz.Label{Name: "Interscope"}},
+		},
+		Media: []musicbrainz.Media{
+			{
+				Format: "Digital Media",
+				Tracks: []musicbrainz.MediaTrack{
+					{
+						ID:        "track-1",
+						Recording: musicbrainz.Recording{ID: "recording-1"},
+						ArtistCredit: []musicbrainz.ArtistCredit{
+							{Name: "Lana Del Rey", JoinPhrase: " feat. ", Artist: musicbrainz.Artist{Name: "Lana Del Rey", SortName: "Del Rey, Lana"}},
+							{Name: "The Weeknd", Artist: musicbrainz.Artist{Name: "The Weeknd", SortName: "Weeknd, The"}},
+						},
+					},
+				},
+			},
+		},
+		TextRepresentation: musicbrainz.TextRepresentation{Language: "eng", Script: "Latn"},
+		ReleaseGroup: musicbrainz.ReleaseGroup{
+			ID:               "group-1",
+			PrimaryType:      "Album",
+			SecondaryTypes:   []string{"Soundtrack"},
+			FirstReleaseDate: "2015-09-18",
+		},
+	}
+
+	// Start from the DAB values the processor writes before MusicBrainz tagging
+	comment := flacvorbis.New()
+	addField(comment, flacvorbis.FIELD_ARTIST, "Lana Del Rey")
+	addField(comment, "ALBUMARTIST", "Lana Del Rey")
+	addField(comment, "LABEL", "Polydor Records")
+	addField(comment, "CATALOGNUMBER", "00602547395734")
+	addField(comment, "GENRE", "Pop")
+
+	processor.addReleaseTags(comment, release, "recording-1")
+
+	expected := map[string][]string{
+		"ARTIST":          {"Lana Del Rey feat. The Weeknd"},
+		"ARTISTS":         {"Lana Del Rey", "The Weeknd"},
+		"ARTISTSORT":      {"Del Rey, Lana feat. Weeknd, The"},
+		"ALBUMARTIST":     {"Lana Del Rey"},
+		"ALBUMARTISTSORT": {"Del Rey, Lana"},
+		"RELEASECOUNTRY":  {"XW"},
+		"RELEASESTATUS":   {"official"},
+		"RELEASETYPE":     {"album", "soundtrack"},
+		"BARCODE":         {"00602547395734"},
+		"LABEL":           {"Polydor", "Interscope"},
+		"CATALOGNUMBER":   {"4739573", "B0024056-02"},
+		"MEDIA":           {"Digital Media"},
+		"ORIGINALDATE":    {"2015-09-18"},
+		"ORIGINALYEAR":    {"2015"},
+		"SCRIPT":          {"Latn"},
+		"LANGUAGE":        {"eng"},
+		"GENRE":           {"Pop"}, // Not provided by MusicBrainz, DAB value is kept
+	}
+
+	for field, want := range expected {
+		got, err := comment.Get(field)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", field, err)
+		}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("Expected %s=%v, got %v", field, want, got)
+		}
+	}
+}
+
+func TestAddReleaseTagsKeepsDABFallback(t *testing.T) {
+	processor := NewMetadataProcessor()
+
+	// A sparse release without labels, media or artist credits
+	release := &musicbrainz.Release{ID: "release-2"}
+
+	comment := flacvorbis.New()
+	addField(comment, flacvorbis.FIELD_ARTIST, "DAB Artist")
+	addField(comment, "LABEL", "DAB Label")
+	addField(comment, "CATALOGNUMBER", "123456789")
+
+	processor.addReleaseTags(comment, release, "unknown-recording")
+
+	for field, want := range map[string]string{"ARTIST": "DAB Artist", "LABEL": "DAB Label", "CATALOGNUMBER": "123456789"} {
+		got, _ := comment.Get(field)
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("Expected DAB fallback %s=%s, got %v", field, want, got)
+		}
+	}
+}
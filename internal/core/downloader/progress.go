@@ -0,0 +1,358 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/cheggaaa/pb/v3/termutil"
+	"github.com/fatih/color"
+
+	"dab-downloader/internal/shared"
+)
+
+const (
+	progressRefreshRate   = 200 * time.Millisecond
+	plainProgressInterval = 10 * time.Second
+	progressTitleWidth    = 30
+)
+
+// trackBarTemplate draws one track being downloaded
+const trackBarTemplate pb.ProgressBarTemplate = `{{string . "prefix"}} {{counters . }} {{bar . }} {{percent . }} {{speed . }}`
+
+// overallBarTemplate draws the number of finished tracks with the session throughput and ETA
+const overallBarTemplate pb.ProgressBarTemplate = `{{string . "prefix"}} {{counters . }} {{bar . }} {{percent . }}{{with string . "suffix"}} {{.}}{{end}}`
+
+// ProgressDisplay shows the progress of a download session: a bar for each track being
+// downloaded and an overall bar with throughput and ETA. On a terminal the bars are redrawn in
+// place below the log output; otherwise a plain progress line is printed periodically.
+// Downloads running at the same time, e.g. albums of a discography, share the display.
+type ProgressDisplay struct {
+	mu        sync.Mutex
+	users     int
+	terminal  bool
+	out       io.Writer // Where the display and intercepted output are written
+	output    io.Writer // color.Output before the display took it over
+	pending   []byte    // Intercepted output without a trailing newline yet
+	overall   *pb.ProgressBar
+	active    []*pb.ProgressBar
+	total     int
+	finished  int
+	bytesDone int64
+	start     time.Time
+	lines     int // Lines drawn by the last redraw
+	paused    int // Nested PauseProgress calls; nothing is drawn while above zero
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// activeDisplay is the display drawing on the terminal, so prompts can pause it
+var (
+	activeMu      sync.Mutex
+	activeDisplay *ProgressDisplay
+)
+
+// PauseProgress removes the progress bars from the terminal and hands it back, e.g. while the
+// user answers a prompt. The returned function resumes drawing.
+func PauseProgress() (resume func()) {
+	activeMu.Lock()
+	display := activeDisplay
+	activeMu.Unlock()
+	if display == nil {
+		return func() {}
+	}
+
+	display.pause()
+	return display.resume
+}
+
+// NewProgressDisplay creates a progress display. It draws nothing until Begin is called.
+func NewProgressDisplay() *ProgressDisplay {
+	return &ProgressDisplay{}
+}
+
+// Begin adds tracks to the session and starts drawing when no download is running yet
+func (p *ProgressDisplay) Begin(tracks int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.users++
+	if p.users == 1 {
+		p.terminal = shared.IsTTY()
+		p.output = color.Output
+		p.out = p.output
+		p.overall = pb.New(0).SetTemplate(overallBarTemplate).Set("prefix", fitTitle("Overall"))
+		p.active, p.pending = nil, nil
+		p.total, p.finished, p.bytesDone, p.lines, p.paused = 0, 0, 0, 0, 0
+		p.start = time.Now()
+		p.stop, p.done = make(chan struct{}), make(chan struct{})
+		if p.terminal {
+			// Log lines are printed above the bars instead of through them
+			color.Output = &displayWriter{display: p}
+			activeMu.Lock()
+			activeDisplay = p
+			activeMu.Unlock()
+		}
+		go p.run(p.stop, p.done)
+	}
+	p.total += tracks
+	p.overall.SetTotal(int64(p.total))
+}
+
+// End marks a download as finished. The last one stops the display and prints the totals.
+func (p *ProgressDisplay) End() {
+	p.mu.Lock()
+	p.users--
+	if p.users > 0 {
+		p.mu.Unlock()
+		return
+	}
+	close(p.stop)
+	done := p.done
+	p.mu.Unlock()
+	<-done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminal {
+		p.clear()
+		p.out.Write(p.pending)
+		p.pending = nil
+		color.Output = p.output
+		activeMu.Lock()
+		if activeDisplay == p {
+			activeDisplay = nil
+		}
+		activeMu.Unlock()
+	}
+	if p.total > 0 {
+		fmt.Fprintln(p.out, p.summary())
+	}
+}
+
+// Bar returns a progress bar for a track that starts downloading. The bar is drawn by the
+// display and must be passed to Done when the download ends.
+func (p *ProgressDisplay) Bar(track shared.Track) *pb.ProgressBar {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.users == 0 {
+		return nil
+	}
+
+	bar := pb.New64(0).SetTemplate(trackBarTemplate).Set("prefix", fitTitle(track.Title))
+	p.active = append(p.active, bar)
+	return bar
+}
+
+// Done counts a track as finished and removes its bar. The bar is nil for skipped tracks.
+func (p *ProgressDisplay) Done(bar *pb.ProgressBar) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.users == 0 {
+		return
+	}
+
+	for i, active := range p.active {
+		if active == bar {
+			p.active = append(p.active[:i], p.active[i+1:]...)
+			p.bytesDone += bar.Current()
+			break
+		}
+	}
+	p.finished++
+	p.overall.SetCurrent(int64(p.finished))
+}
+
+// run redraws the bars, or prints a plain progress line, until the display stops
+func (p *ProgressDisplay) run(stop, done chan struct{}) {
+	defer close(done)
+
+	interval := progressRefreshRate
+	if !p.terminal {
+		interval = plainProgressInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			if p.terminal {
+				if p.paused == 0 {
+					p.redraw()
+				}
+			} else {
+				fmt.Fprintln(p.out, p.summary())
+			}
+			p.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// redraw replaces the bars drawn last time. The caller holds the lock.
+func (p *ProgressDisplay) redraw() {
+	_, width, err := termutil.TerminalSize()
+	if err != nil || width <= 0 {
+		width = 80
+	}
+
+	var frame strings.Builder
+	if p.lines > 0 {
+		fmt.Fprintf(&frame, "\r\x1b[%dA", p.lines)
+	}
+	for _, bar := range p.active {
+		bar.SetWidth(width)
+		frame.WriteString("\x1b[0m" + bar.String() + "\x1b[K\n")
+	}
+	speed, eta := p.rates()
+	suffix := formatBytes(speed) + "/s"
+	if eta > 0 {
+		suffix += " ETA " + eta.Round(time.Second).String()
+	}
+	p.overall.Set("suffix", suffix).SetWidth(width)
+	frame.WriteString("\x1b[0m" + p.overall.String() + "\x1b[K\n\x1b[J")
+
+	io.WriteString(p.out, frame.String())
+	p.lines = len(p.active) + 1
+}
+
+// clear removes the bars from the screen. The caller holds the lock.
+func (p *ProgressDisplay) clear() {
+	if p.lines > 0 {
+		fmt.Fprintf(p.out, "\r\x1b[%dA\x1b[J", p.lines)
+		p.lines = 0
+	}
+}
+
+// rates returns the session throughput in bytes per second and the estimated time left.
+// The caller holds the lock.
+func (p *ProgressDisplay) rates() (int64, time.Duration) {
+	downloaded := p.bytesDone
+	progress := float64(p.finished)
+	for _, bar := range p.active {
+		current, total := bar.Current(), bar.Total()
+		downloaded += current
+		if total > 0 {
+			progress += float64(current) / float64(total)
+		}
+	}
+
+	elapsed := time.Since(p.start)
+	var speed int64
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		speed = int64(float64(downloaded) / seconds)
+	}
+	if progress <= 0 || p.total == 0 {
+		return speed, 0
+	}
+	remaining := float64(p.total)/progress - 1
+	return speed, time.Duration(float64(elapsed) * remaining)
+}
+
+// summary describes the session progress in one line. The caller holds the lock.
+func (p *ProgressDisplay) summary() string {
+	speed, eta := p.rates()
+	downloaded := p.bytesDone
+	for _, bar := range p.active {
+		downloaded += bar.Current()
+	}
+
+	line := fmt.Sprintf("Progress: %d/%d tracks, %s, %s/s", p.finished, p.total, formatBytes(downloaded), formatBytes(speed))
+	if eta > 0 && p.finished < p.total {
+		line += ", ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+// pause clears the bars, prints held output and lets writes through until resume
+func (p *ProgressDisplay) pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused++
+	if p.paused > 1 || p.users == 0 {
+		return
+	}
+	p.clear()
+	p.out.Write(p.pending)
+	p.pending = nil
+	color.Output = p.output
+}
+
+// resume draws the bars again after the last pause ends
+func (p *ProgressDisplay) resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused == 0 {
+		return
+	}
+	p.paused--
+	if p.paused > 0 || p.users == 0 || !p.terminal {
+		return
+	}
+	// Whatever was printed during the pause stays above the bars
+	p.lines = 0
+	color.Output = &displayWriter{display: p}
+	p.redraw()
+}
+
+// write prints intercepted output above the bars
+func (p *ProgressDisplay) write(data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused > 0 {
+		p.out.Write(data)
+		return
+	}
+	p.pending = append(p.pending, data...)
+	end := bytes.LastIndexByte(p.pending, '\n')
+	if end < 0 {
+		return
+	}
+	p.clear()
+	p.out.Write(p.pending[:end+1])
+	p.pending = append([]byte(nil), p.pending[end+1:]...)
+	p.redraw()
+}
+
+// displayWriter sends output written while the display runs to the display
+type displayWriter struct {
+	display *ProgressDisplay
+}
+
+func (w *displayWriter) Write(data []byte) (int, error) {
+	w.display.write(data)
+	return len(data), nil
+}
+
+// fitTitle pads or truncates a track title for the bar prefix
+func fitTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) > progressTitleWidth {
+		return string(runes[:progressTitleWidth-1]) + "…"
+	}
+	return title + strings.Repeat(" ", progressTitleWidth-len(runes))
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/fatih/color"
+
+	"dab-downloader/internal/shared"
+)
+
+func TestProgressDisplaySession(t *testing.T) {
+	var out bytes.Buffer
+	output := color.Output
+	color.Output = &out
+	defer func() { color.Output = output }()
+
+	display := NewProgressDisplay()
+	display.Begin(2)
+	display.Begin(1) // A second album joins the session
+
+	bar := display.Bar(shared.Track{Title: "First"})
+	if bar == nil {
+		t.Fatal("expected a progress bar while the display runs")
+	}
+	bar.SetTotal(200)
+	bar.Add(100)
+	if speed, eta := display.rates(); speed <= 0 || eta <= 0 {
+		t.Errorf("expected throughput and ETA, got %d and %v", speed, eta)
+	}
+
+	display.Done(bar)
+	display.Done(nil)
+	display.End()
+	if out.Len() != 0 {
+		t.Fatalf("display stopped while a download was running: %q", out.String())
+	}
+
+	display.Done(nil)
+	display.End()
+	if summary := out.String(); !strings.Contains(summary, "Progress: 3/3 tracks, 100 B") {
+		t.Errorf("unexpected summary: %q", summary)
+	}
+	if display.Bar(shared.Track{Title: "Late"}) != nil {
+		t.Error("no bars should be handed out after the session ended")
+	}
+}
+
+func TestProgressDisplayPrintsLogAboveBars(t *testing.T) {
+	var out bytes.Buffer
+	display := &ProgressDisplay{users: 1, terminal: true, out: &out, start: time.Now(), total: 1}
+	display.overall = pb.New(1).SetTemplate(overallBarTemplate)
+
+	display.write([]byte("\x1b[36m[INFO] partial"))
+	if out.Len() != 0 {
+		t.Fatalf("incomplete line was written: %q", out.String())
+	}
+	display.write([]byte(" line\n"))
+	display.write([]byte("\x1b[36m[INFO] second\n"))
+
+	written := out.String()
+	first := strings.Index(written, "[INFO] partial line\n")
+	second := strings.Index(written, "[INFO] second\n")
+	if first < 0 || second < first {
+		t.Fatalf("log lines missing or out of order: %q", written)
+	}
+	// The bars drawn after the first line are cleared before the second one
+	if !strings.Contains(written[first:second], "\x1b[1A\x1b[J") {
+		t.Errorf("bars were not cleared before the next log line: %q", written)
+	}
+}
+
+func TestPauseProgressShowsPrompt(t *testing.T) {
+	var out bytes.Buffer
+	output := color.Output
+	defer func() { color.Output = output }()
+
+	display := &ProgressDisplay{users: 1, terminal: true, out: &out, output: &out, start: time.Now(), total: 1}
+	display.overall = pb.New(1).SetTemplate(overallBarTemplate)
+	color.Output = &displayWriter{display: display}
+	activeDisplay = display
+	defer func() { activeDisplay = nil }()
+
+	display.write([]byte("[INFO] held"))
+	display.redraw()
+
+	resume := PauseProgress()
+	if !strings.HasSuffix(out.String(), "[INFO] held") {
+		t.Fatalf("held output was not flushed when pausing: %q", out.String())
+	}
+	if color.Output != io.Writer(&out) {
+		t.Fatal("color output still goes through the display while paused")
+	}
+
+	// A prompt without a trailing newline is shown right away
+	before := out.Len()
+	shared.ColorPrompt.Print("Choose release: ")
+	display.write([]byte("late log line without newline"))
+	if written := out.String()[before:]; !strings.Contains(written, "Choose release: ") || !strings.Contains(written, "late log line") {
+		t.Fatalf("output was held while paused: %q", written)
+	}
+
+	resume()
+	if _, ok := color.Output.(*displayWriter); !ok {
+		t.Error("color output was not handed back to the display after resuming")
+	}
+	if display.paused != 0 || display.lines == 0 {
+		t.Errorf("bars were not redrawn after resuming: paused %d, lines %d", display.paused, display.lines)
+	}
+}
+
+func TestFitTitle(t *testing.T) {
+	if got := fitTitle("Short"); len([]rune(got)) != progressTitleWidth {
+		t.Errorf("short title not padded: %q", got)
+	}
+	if got := fitTitle(strings.Repeat("é", 40)); len([]rune(got)) != progressTitleWidth || !strings.HasSuffix(got, "…") {
+		t.Errorf("long title not truncated: %q", got)
+	}
+}
@@ -0,0 +1,325 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"dab-downloader/internal/api/musicbrainz"
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Constants and Types
+// ============================================================================
+
+// Release review modes
+const (
+	ReleaseReviewOff         = "off"
+	ReleaseReviewInteractive = "interactive"
+	ReleaseReviewReport      = "report"
+)
+
+const (
+	DefaultReleaseReviewMargin     = 20
+	DefaultReleaseReviewCandidates = 5
+	DefaultReleaseReviewReport     = "config/release-review.json"
+)
+
+// ReleaseCandidate describes one MusicBrainz release considered for an album
+type ReleaseCandidate struct {
+	ReleaseID  string `json:"release_id"`
+	Title      string `json:"title"`
+	Date       string `json:"date,omitempty"`
+	Country    string `json:"country,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Format     string `json:"format,omitempty"`
+	TrackCount int    `json:"track_count,omitempty"`
+	Score      int    `json:"score"`
+}
+
+// ReleaseReviewEntry records the review state of a single album.
+// Report mode leaves SelectedReleaseID empty; filling it in (or setting Skipped) resolves the album on the next run.
+type ReleaseReviewEntry struct {
+	Artist            string             `json:"artist"`
+	Album             string             `json:"album"`
+	DABTrackCount     int                `json:"dab_track_count,omitempty"`
+	DABReleaseDate    string             `json:"dab_release_date,omitempty"`
+	Candidates        []ReleaseCandidate `json:"candidates,omitempty"`
+	SelectedReleaseID string             `json:"selected_release_id"`
+	Skipped           bool               `json:"skipped"` // Keep the automatic pick without asking again
+}
+
+// ReleaseReviewer asks the user to confirm the release when the automatic MusicBrainz selection is ambiguous
+type ReleaseReviewer struct {
+	mode          string
+	margin        int
+	maxCandidates int
+	reportPath    string
+	entries       map[string]*ReleaseReviewEntry
+	loaded        bool
+	mu            sync.Mutex // Serializes prompts and report writes across parallel downloads
+}
+
+// rankedTrackRelease pairs a release with its heuristic score
+type rankedTrackRelease struct {
+	release musicbrainz.TrackRelease
+	score   int
+}
+
+// ============================================================================
+// 2. Constructor
+// ============================================================================
+
+// NewReleaseReviewer creates a release reviewer, returning nil when the mode is off
+func NewReleaseReviewer(mode string, margin, maxCandidates int, reportPath string) *ReleaseReviewer {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode != ReleaseReviewInteractive && mode != ReleaseReviewReport {
+		return nil
+	}
+	if margin <= 0 {
+		margin = DefaultReleaseReviewMargin
+	}
+	if maxCandidates < 2 {
+		maxCandidates = DefaultReleaseReviewCandidates
+	}
+	if reportPath == "" {
+		reportPath = DefaultReleaseReviewReport
+	}
+
+	return &ReleaseReviewer{
+		mode:          mode,
+		margin:        margin,
+		maxCandidates: maxCandidates,
+		reportPath:    reportPath,
+		entries:       make(map[string]*ReleaseReviewEntry),
+	}
+}
+
+// ============================================================================
+// 3. Public API Methods
+// ============================================================================
+
+// Resolve returns the release chosen for an album, if any. Ranked releases must be sorted by descending score.
+// An ambiguous ranking is either presented to the user or written to the report, depending on the mode.
+func (rr *ReleaseReviewer) Resolve(artist, albumTitle string, album *shared.Album, ranked []rankedTrackRelease) (string, bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.load()
+
+	key := getCacheKey(artist, albumTitle)
+	if entry, exists := rr.entries[key]; exists {
+		// Remembered choice, explicit skip, or still waiting for resolution in the report
+		return entry.SelectedReleaseID, entry.SelectedReleaseID != ""
+	}
+
+	if !rr.isAmbiguous(ranked) {
+		return "", false
+	}
+
+	entry := &ReleaseReviewEntry{
+		Artist:     artist,
+		Album:      albumTitle,
+		Candidates: rr.buildCandidates(ranked),
+	}
+	if album != nil {
+		entry.DABTrackCount = album.TotalTracks
+		if entry.DABTrackCount == 0 {
+			entry.DABTrackCount = len(album.Tracks)
+		}
+		entry.DABReleaseDate = album.ReleaseDate
+	}
+	rr.entries[key] = entry
+
+	if rr.mode == ReleaseReviewInteractive && shared.IsTTY() {
+		rr.prompt(entry)
+	} else {
+		shared.ColorWarning.Printf("⚠️ Ambiguous MusicBrainz release for %s - %s, candidates written to %s\n", artist, albumTitle, rr.reportPath)
+	}
+
+	if err := rr.save(); err != nil {
+		shared.ColorWarning.Printf("⚠️ Failed to save release review report: %v\n", err)
+	}
+
+	return entry.SelectedReleaseID, entry.SelectedReleaseID != ""
+}
+
+// GetEntries returns a copy of all review entries
+func (rr *ReleaseReviewer) GetEntries() []ReleaseReviewEntry {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	entries := make([]ReleaseReviewEntry, 0, len(rr.entries))
+	for _, entry := range rr.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return getCacheKey(entries[i].Artist, entries[i].Album) < getCacheKey(entries[j].Artist, entries[j].Album)
+	})
+	return entries
+}
+
+// ============================================================================
+// 4. Private Methods
+// ============================================================================
+
+// isAmbiguous reports whether the top two candidates are closer than the configured margin
+func (rr *ReleaseReviewer) isAmbiguous(ranked []rankedTrackRelease) bool {
+	if len(ranked) < 2 {
+		return false
+	}
+	return ranked[0].score-ranked[1].score < rr.margin
+}
+
+// buildCandidates converts the top ranked releases into review candidates
+func (rr *ReleaseReviewer) buildCandidates(ranked []rankedTrackRelease) []ReleaseCandidate {
+	var candidates []ReleaseCandidate
+	for i, rankedRelease := range ranked {
+		if i >= rr.maxCandidates {
+			break
+		}
+		release := rankedRelease.release
+		candidate := ReleaseCandidate{
+			ReleaseID:  release.ID,
+			Title:      release.Title,
+			Date:       release.Date,
+			Country:    release.Country,
+			Status:     release.Status,
+			TrackCount: releaseTrackCount(release),
+			Score:      rankedRelease.score,
+		}
+		if len(release.Media) > 0 {
+			candidate.Format = release.Media[0].Format
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// prompt shows the candidates and stores the user's pick in the entry
+func (rr *ReleaseReviewer) prompt(entry *ReleaseReviewEntry) {
+	// The progress bars would hide the prompt, which has no trailing newline
+	resume := PauseProgress()
+	defer resume()
+
+	shared.ColorWarning.Printf("\n⚠️ Ambiguous MusicBrainz release for %s - %s", entry.Artist, entry.Album)
+	if entry.DABTrackCount > 0 || entry.DABReleaseDate != "" {
+		shared.ColorWarning.Printf(" (DAB: %d tracks, %s)", entry.DABTrackCount, valueOrUnknown(entry.DABReleaseDate))
+	}
+	fmt.Println()
+
+	for i, candidate := range entry.Candidates {
+		shared.ColorInfo.Printf("%d. %s [score %d]\n", i+1, candidate.Title, candidate.Score)
+		fmt.Printf("   %s\n", formatCandidateDifferences(candidate, entry.DABTrackCount))
+	}
+
+	for {
+		input := shared.GetUserInput(fmt.Sprintf("Choose release (1-%d, or s to skip)", len(entry.Candidates)), "1")
+		if strings.EqualFold(input, "s") {
+			entry.Skipped = true
+			return
+		}
+		choice, err := strconv.Atoi(input)
+		if err == nil && choice >= 1 && choice <= len(entry.Candidates) {
+			entry.SelectedReleaseID = entry.Candidates[choice-1].ReleaseID
+			return
+		}
+		shared.ColorError.Println("Invalid choice, please try again.")
+	}
+}
+
+// load reads previously saved review entries once
+func (rr *ReleaseReviewer) load() {
+	if rr.loaded {
+		return
+	}
+	rr.loaded = true
+
+	data, err := os.ReadFile(rr.reportPath)
+	if err != nil {
+		return
+	}
+
+	var entries []ReleaseReviewEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		shared.ColorWarning.Printf("⚠️ Ignoring unreadable release review report %s: %v\n", rr.reportPath, err)
+		return
+	}
+	for i := range entries {
+		entry := entries[i]
+		rr.entries[getCacheKey(entry.Artist, entry.Album)] = &entry
+	}
+}
+
+// save writes all review entries to the report file
+func (rr *ReleaseReviewer) save() error {
+	entries := make([]ReleaseReviewEntry, 0, len(rr.entries))
+	for _, entry := range rr.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return getCacheKey(entries[i].Artist, entries[i].Album) < getCacheKey(entries[j].Artist, entries[j].Album)
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal release review report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(rr.reportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(rr.reportPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write release review report: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// 5. Helper Functions
+// ============================================================================
+
+// releaseTrackCount returns the total number of tracks on a release
+func releaseTrackCount(release musicbrainz.TrackRelease) int {
+	count := 0
+	for _, media := range release.Media {
+		count += media.TrackCount
+	}
+	if count == 0 {
+		count = release.TrackCount
+	}
+	return count
+}
+
+// formatCandidateDifferences summarizes the fields that usually distinguish editions
+func formatCandidateDifferences(candidate ReleaseCandidate, dabTrackCount int) string {
+	trackCount := "? tracks"
+	if candidate.TrackCount > 0 {
+		trackCount = fmt.Sprintf("%d tracks", candidate.TrackCount)
+		if dabTrackCount > 0 && candidate.TrackCount != dabTrackCount {
+			trackCount += fmt.Sprintf(" (%+d)", candidate.TrackCount-dabTrackCount)
+		}
+	}
+
+	return strings.Join([]string{
+		trackCount,
+		valueOrUnknown(candidate.Country),
+		valueOrUnknown(candidate.Date),
+		valueOrUnknown(candidate.Format),
+		valueOrUnknown(candidate.Status),
+		candidate.ReleaseID,
+	}, " | ")
+}
+
+// valueOrUnknown returns a placeholder for empty values
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "?"
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package downloader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dab-downloader/internal/api/musicbrainz"
+	"dab-downloader/internal/shared"
+)
+
+// ambiguousReleases returns a standard and a deluxe edition that score the same
+func ambiguousReleases() []musicbrainz.TrackRelease {
+	return []musicbrainz.TrackRelease{
+		{ID: "standard", Title: "Honeymoon", Date: "2015-09-18", Country: "XW", Status: "Official", Media: []musicbrainz.Media{{Format: "Digital Media", TrackCount: 14}}},
+		{ID: "deluxe", Title: "Honeymoon", Date: "2015-09-18", Country: "XE", Status: "Official", Media: []musicbrainz.Media{{Format: "Digital Media", TrackCount: 18}}},
+	}
+}
+
+func TestNewReleaseReviewerOff(t *testing.T) {
+	for _, mode := range []string{"", "off", "bogus"} {
+		if NewReleaseReviewer(mode, 0, 0, "") != nil {
+			t.Errorf("Expected no reviewer for mode %q", mode)
+		}
+	}
+}
+
+func TestReleaseReviewReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "release-review.json")
+	processor := NewMetadataProcessor()
+	processor.SetReleaseReviewer(NewReleaseReviewer(ReleaseReviewReport, 20, 5, reportPath))
+
+	album := &shared.Album{Title: "Honeymoon", Artist: "Lana Del Rey", ReleaseDate: "2015-09-18", TotalTracks: 14}
+	track := shared.Track{Title: "Music to Watch Boys To", Artist: "Lana Del Rey"}
+
+	// Without a decision the automatic pick is kept
+	selected := processor.selectTrackReleaseForAlbum(ambiguousReleases(), track, album)
+	if selected.ID != "standard" {
+		t.Errorf("Expected automatic pick 'standard', got %s", selected.ID)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Expected report to be written: %v", err)
+	}
+	var entries []ReleaseReviewEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].Candidates) != 2 {
+		t.Fatalf("Expected one album with two candidates, got %+v", entries)
+	}
+	if entries[0].DABTrackCount != 14 || entries[0].Candidates[1].TrackCount != 18 {
+		t.Errorf("Expected track counts to be reported, got %+v", entries[0])
+	}
+
+	// Resolve the album in the report, a new run should use the choice
+	entries[0].SelectedReleaseID = "deluxe"
+	data, _ = json.Marshal(entries)
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		t.Fatalf("Failed to update report: %v", err)
+	}
+
+	processor = NewMetadataProcessor()
+	processor.SetReleaseReviewer(NewReleaseReviewer(ReleaseReviewReport, 20, 5, reportPath))
+	selected = processor.selectTrackReleaseForAlbum(ambiguousReleases(), track, album)
+	if selected.ID != "deluxe" {
+		t.Errorf("Expected remembered choice 'deluxe', got %s", selected.ID)
+	}
+	if cachedID := processor.cache.GetCachedReleaseID(album.Artist, album.Title); cachedID != "deluxe" {
+		t.Errorf("Expected chosen release to be cached for the album, got %q", cachedID)
+	}
+}
+
+func TestReleaseReviewClearWinner(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "release-review.json")
+	reviewer := NewReleaseReviewer(ReleaseReviewReport, 20, 5, reportPath)
+
+	ranked := []rankedTrackRelease{
+		{release: musicbrainz.TrackRelease{ID: "album"}, score: 160},
+		{release: musicbrainz.TrackRelease{ID: "single"}, score: 60},
+	}
+	if _, chosen := reviewer.Resolve("Artist", "Album", nil, ranked); chosen {
+		t.Error("Expected no choice for a clear winner")
+	}
+	if len(reviewer.GetEntries()) != 0 {
+		t.Error("Expected no review entry for a clear winner")
+	}
+	if _, err := os.Stat(reportPath); !os.IsNotExist(err) {
+		t.Error("Expected no report for a clear winner")
+	}
+}
+
+func TestFormatCandidateDifferences(t *testing.T) {
+	candidate := ReleaseCandidate{ReleaseID: "deluxe", Country: "XE", Date: "2015", Format: "CD", TrackCount: 18}
+	expected := "18 tracks (+4) | XE | 2015 | CD | ? | deluxe"
+	if got := formatCandidateDifferences(candidate, 14); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,471 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-flac/flacvorbis"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Constants and Types
+// ============================================================================
+
+const (
+	DefaultRetagParallelism = 4
+	retagSearchLimit        = 10
+)
+
+// RetagAPI is the subset of the DAB API needed to look up tracks of existing files
+type RetagAPI interface {
+	GetAlbum(ctx context.Context, albumID string) (*shared.Album, error)
+	GetTrack(ctx context.Context, trackID string) (*shared.Track, error)
+	Search(ctx context.Context, query, searchType string, limit int, debug bool) (*shared.SearchResults, error)
+}
+
+// RetagOptions controls a retag run
+type RetagOptions struct {
+	DryRun      bool
+	Parallelism int
+	Debug       bool
+}
+
+// TagChange describes the change of a single Vorbis comment field
+type TagChange struct {
+	Field     string
+	OldValues []string
+	NewValues []string
+}
+
+// RetagResult holds the outcome of retagging a single file
+type RetagResult struct {
+	FilePath string
+	Track    string // "Artist - Title" of the matched DAB track
+	Changes  []TagChange
+	Err      error
+}
+
+// Retagger rewrites the tags of FLAC files already on disk using fresh DAB and MusicBrainz data
+type Retagger struct {
+	api              RetagAPI
+	processor        *MetadataProcessor
+	options          RetagOptions
+	warningCollector *shared.WarningCollector
+	albums           map[string]*retagAlbum
+	lookupReleases   bool // Look up MusicBrainz releases of fetched albums
+	mu               sync.Mutex
+}
+
+// retagAlbum caches an album lookup shared by all files of that album
+type retagAlbum struct {
+	once  sync.Once
+	album *shared.Album
+	err   error
+}
+
+// managedTags and managedTagPrefixes are fields the metadata processor owns. Old values of these fields
+// are dropped when the processor no longer writes them; every other field is carried over.
+var managedTags = []string{
+	"TITLE", "ARTIST", "ARTISTS", "ARTISTSORT", "ALBUM", "ALBUMARTIST", "ALBUMARTISTSORT", "GENRE",
+	"TRACKNUMBER", "TOTALTRACKS", "DISCNUMBER", "TOTALDISCS", "DATE", "YEAR", "ORIGINALDATE", "ORIGINALYEAR",
+	"COMPOSER", "PRODUCER", "ISRC", "COPYRIGHT", "LABEL", "CATALOGNUMBER", "UPC", "BARCODE", "MEDIA",
+	"SCRIPT", "LANGUAGE", "ENCODER", "ENCODING", "SOURCE", "LENGTH",
+}
+
+var managedTagPrefixes = []string{"RELEASE", "MUSICBRAINZ_", "DAB_"}
+
+// ============================================================================
+// 2. Constructor
+// ============================================================================
+
+// NewRetagger creates a retagger using the metadata settings from the config
+func NewRetagger(api RetagAPI, cfg *config.Config, options RetagOptions, warningCollector *shared.WarningCollector) *Retagger {
+	processor := NewMetadataProcessor()
+	processor.ApplyConfig(cfg)
+	processor.SetDebugMode(options.Debug)
+
+	if options.Parallelism <= 0 {
+		options.Parallelism = DefaultRetagParallelism
+	}
+
+	return &Retagger{
+		api:              api,
+		processor:        processor,
+		options:          options,
+		warningCollector: warningCollector,
+		albums:           make(map[string]*retagAlbum),
+		lookupReleases:   true,
+	}
+}
+
+// ============================================================================
+// 3. Public API Methods
+// ============================================================================
+
+// FindFLACFiles returns all FLAC files below a path, or the path itself if it is a file
+func FindFLACFiles(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".flac") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// RetagFiles retags files in parallel, calling onResult as each file completes. Results are returned in input order.
+func (r *Retagger) RetagFiles(ctx context.Context, files []string, onResult func(RetagResult)) []RetagResult {
+	results := make([]RetagResult, len(files))
+	jobs := make(chan int, len(files))
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	var callbackMu sync.Mutex
+	for w := 0; w < r.options.Parallelism && w < len(files); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] = RetagResult{FilePath: files[i], Err: ctx.Err()}
+				} else {
+					results[i] = r.RetagFile(ctx, files[i])
+				}
+				if onResult != nil {
+					callbackMu.Lock()
+					onResult(results[i])
+					callbackMu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// RetagFile recomputes the tags of a single FLAC file and writes them unless running in dry-run mode
+func (r *Retagger) RetagFile(ctx context.Context, filePath string) RetagResult {
+	result := RetagResult{FilePath: filePath}
+
+	oldComment, err := ReadVorbisComment(filePath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	oldTags := VorbisCommentFields(oldComment)
+
+	track, album, err := r.resolveTrack(ctx, oldTags)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Track = fmt.Sprintf("%s - %s", track.Artist, track.Title)
+
+	totalTracks := 0
+	if album != nil {
+		totalTracks = len(album.Tracks)
+	}
+	newComment := r.processor.BuildVorbisComment(filePath, *track, album, totalTracks, r.warningCollector)
+	if oldComment.Vendor != "" {
+		newComment.Vendor = oldComment.Vendor
+	}
+	carryOverUnmanagedFields(oldComment, newComment)
+	// Retagging is not a download, keep when and at which quality the audio was fetched
+	restoreField(oldComment, newComment, TagDABDownloaded)
+	restoreField(oldComment, newComment, TagDABQuality)
+
+	result.Changes = DiffTags(oldTags, VorbisCommentFields(newComment))
+	if r.options.DryRun || len(result.Changes) == 0 {
+		return result
+	}
+
+	if err := r.processor.ReplaceVorbisComment(filePath, newComment); err != nil {
+		result.Err = err
+	}
+	return result
+}
+
+// ============================================================================
+// 4. Private Methods
+// ============================================================================
+
+// resolveTrack recovers the DAB track and album of a file from its existing tags
+func (r *Retagger) resolveTrack(ctx context.Context, tags map[string][]string) (*shared.Track, *shared.Album, error) {
+	ids := sourceIDsFromTags(tags)
+	trackID, albumID := ids.TrackID, ids.AlbumID
+
+	var found *shared.Track
+	if albumID == "" {
+		var err error
+		found, err = r.findTrack(ctx, tags, trackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		trackID = shared.IdToString(found.ID)
+		albumID = found.AlbumID
+	}
+
+	if albumID != "" {
+		album, err := r.getAlbum(ctx, albumID)
+		if err == nil {
+			if track := matchAlbumTrack(album, trackID, tags); track != nil {
+				return track, album, nil
+			}
+		} else if r.warningCollector != nil {
+			r.warningCollector.AddAlbumFetchWarning(firstTag(tags, "TITLE"), albumID, err.Error())
+		}
+	}
+
+	if found == nil {
+		// The tagged album no longer lists this track, search for it instead
+		var err error
+		if found, err = r.findTrack(ctx, tags, trackID); err != nil {
+			return nil, nil, err
+		}
+	}
+	// No usable album, tag from the track alone
+	return found, nil, nil
+}
+
+// findTrack locates the DAB track of a file by ID or by searching for its artist and title
+func (r *Retagger) findTrack(ctx context.Context, tags map[string][]string, trackID string) (*shared.Track, error) {
+	if trackID != "" {
+		track, err := r.api.GetTrack(ctx, trackID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get track %s: %w", trackID, err)
+		}
+		return track, nil
+	}
+
+	title := firstTag(tags, "TITLE")
+	artist := firstTag(tags, "ARTIST")
+	if title == "" {
+		return nil, fmt.Errorf("file has no DAB IDs and no title to search for")
+	}
+
+	results, err := r.api.Search(ctx, strings.TrimSpace(artist+" "+title), "track", retagSearchLimit, r.options.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for %s - %s: %w", artist, title, err)
+	}
+
+	if track := bestSearchMatch(results.Tracks, tags); track != nil {
+		return track, nil
+	}
+	return nil, fmt.Errorf("no DAB track matches %s - %s", artist, title)
+}
+
+// getAlbum fetches an album once and shares it between all files of that album
+func (r *Retagger) getAlbum(ctx context.Context, albumID string) (*shared.Album, error) {
+	r.mu.Lock()
+	entry, exists := r.albums[albumID]
+	if !exists {
+		entry = &retagAlbum{}
+		r.albums[albumID] = entry
+	}
+	r.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.album, entry.err = r.api.GetAlbum(ctx, albumID)
+		if entry.err == nil && r.lookupReleases {
+			r.processor.FindReleaseIDFromISRC(entry.album.Tracks, entry.album.Artist, entry.album.Title)
+		}
+	})
+	return entry.album, entry.err
+}
+
+// ============================================================================
+// 5. Helper Functions
+// ============================================================================
+
+// VorbisCommentFields returns the fields of a Vorbis comment keyed by upper case field name
+func VorbisCommentFields(comment *flacvorbis.MetaDataBlockVorbisComment) map[string][]string {
+	fields := make(map[string][]string)
+	for _, entry := range comment.Comments {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToUpper(key)
+		fields[key] = append(fields[key], value)
+	}
+	return fields
+}
+
+// DiffTags compares two tag sets field by field, sorted by field name
+func DiffTags(oldTags, newTags map[string][]string) []TagChange {
+	fieldSet := make(map[string]bool)
+	for field := range oldTags {
+		fieldSet[field] = true
+	}
+	for field := range newTags {
+		fieldSet[field] = true
+	}
+
+	var changes []TagChange
+	for field := range fieldSet {
+		oldValues, newValues := oldTags[field], newTags[field]
+		if equalValues(oldValues, newValues) {
+			continue
+		}
+		changes = append(changes, TagChange{Field: field, OldValues: oldValues, NewValues: newValues})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// carryOverUnmanagedFields keeps fields the processor does not write, such as ReplayGain values
+func carryOverUnmanagedFields(oldComment, newComment *flacvorbis.MetaDataBlockVorbisComment) {
+	for _, entry := range oldComment.Comments {
+		key, _, ok := strings.Cut(entry, "=")
+		if !ok || isManagedTag(strings.ToUpper(key)) {
+			continue
+		}
+		newComment.Comments = append(newComment.Comments, entry)
+	}
+}
+
+// restoreField replaces the values of a field in the new comment with those of the old one
+func restoreField(oldComment, newComment *flacvorbis.MetaDataBlockVorbisComment, field string) {
+	var comments []string
+	for _, entry := range newComment.Comments {
+		if key, _, ok := strings.Cut(entry, "="); !ok || !strings.EqualFold(key, field) {
+			comments = append(comments, entry)
+		}
+	}
+	for _, entry := range oldComment.Comments {
+		if key, _, ok := strings.Cut(entry, "="); ok && strings.EqualFold(key, field) {
+			comments = append(comments, entry)
+		}
+	}
+	newComment.Comments = comments
+}
+
+// isManagedTag reports whether a field is written by the metadata processor
+func isManagedTag(field string) bool {
+	for _, tag := range managedTags {
+		if field == tag {
+			return true
+		}
+	}
+	for _, prefix := range managedTagPrefixes {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAlbumTrack finds the track of a file within its album by ID, ISRC, or disc/track number and title
+func matchAlbumTrack(album *shared.Album, trackID string, tags map[string][]string) *shared.Track {
+	if trackID != "" {
+		for i := range album.Tracks {
+			if shared.IdToString(album.Tracks[i].ID) == trackID {
+				return &album.Tracks[i]
+			}
+		}
+	}
+
+	if isrc := firstTag(tags, "ISRC"); isrc != "" {
+		for i := range album.Tracks {
+			if strings.EqualFold(album.Tracks[i].ISRC, isrc) {
+				return &album.Tracks[i]
+			}
+		}
+	}
+
+	title := firstTag(tags, "TITLE")
+	trackNumber, _ := strconv.Atoi(firstTag(tags, "TRACKNUMBER"))
+	discNumber, _ := strconv.Atoi(firstTag(tags, "DISCNUMBER"))
+	for i := range album.Tracks {
+		track := &album.Tracks[i]
+		if !strings.EqualFold(track.Title, title) {
+			continue
+		}
+		if trackNumber == 0 || (track.TrackNumber == trackNumber && (discNumber <= 1 || track.DiscNumber == discNumber)) {
+			return track
+		}
+	}
+	return nil
+}
+
+// bestSearchMatch picks the search result matching the file's ISRC, or its title, artist and album
+func bestSearchMatch(tracks []shared.Track, tags map[string][]string) *shared.Track {
+	if isrc := firstTag(tags, "ISRC"); isrc != "" {
+		for i := range tracks {
+			if strings.EqualFold(tracks[i].ISRC, isrc) {
+				return &tracks[i]
+			}
+		}
+	}
+
+	title := firstTag(tags, "TITLE")
+	artist := firstTag(tags, "ARTIST")
+	albumTitle := firstTag(tags, "ALBUM")
+
+	var titleMatch *shared.Track
+	for i := range tracks {
+		track := &tracks[i]
+		if !strings.EqualFold(track.Title, title) || !strings.EqualFold(track.Artist, artist) {
+			continue
+		}
+		if albumTitle == "" || strings.EqualFold(track.Album, albumTitle) || strings.EqualFold(track.AlbumTitle, albumTitle) {
+			return track
+		}
+		if titleMatch == nil {
+			titleMatch = track
+		}
+	}
+	return titleMatch
+}
+
+// equalValues reports whether two field value lists are identical
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstTag returns the first value of a field, or an empty string
+func firstTag(tags map[string][]string, field string) string {
+	if values := tags[field]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/shared"
+)
+
+// fakeAudioFrames stands in for the audio data following the metadata blocks
+var fakeAudioFrames = []byte{0xFF, 0xF8, 0x69, 0x08, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05}
+
+// writeTestFLAC writes a minimal FLAC file with a STREAMINFO block, the given Vorbis comments and fake audio frames
+func writeTestFLAC(t *testing.T, path string, comments ...string) {
+	t.Helper()
+
+	streamInfo := &flac.MetaDataBlock{Type: flac.StreamInfo, Data: make([]byte, 34)}
+	f := &flac.File{Meta: []*flac.MetaDataBlock{streamInfo}, Frames: fakeAudioFrames}
+	if len(comments) > 0 {
+		comment := flacvorbis.New()
+		comment.Comments = append(comment.Comments, comments...)
+		block := comment.Marshal()
+		f.Meta = append(f.Meta, &block)
+	}
+	f.Meta = append(f.Meta, &flac.MetaDataBlock{Type: flac.Picture, Data: []byte("picture")})
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Failed to write test FLAC: %v", err)
+	}
+}
+
+// fakeRetagAPI serves a single album
+type fakeRetagAPI struct {
+	album    shared.Album
+	searches int
+}
+
+func (api *fakeRetagAPI) GetAlbum(ctx context.Context, albumID string) (*shared.Album, error) {
+	if albumID != api.album.ID {
+		return nil, fmt.Errorf("album %s not found", albumID)
+	}
+	album := api.album
+	return &album, nil
+}
+
+func (api *fakeRetagAPI) GetTrack(ctx context.Context, trackID string) (*shared.Track, error) {
+	for _, track := range api.album.Tracks {
+		if shared.IdToString(track.ID) == trackID {
+			return &track, nil
+		}
+	}
+	return nil, fmt.Errorf("track %s not found", trackID)
+}
+
+func (api *fakeRetagAPI) Search(ctx context.Context, query, searchType string, limit int, debug bool) (*shared.SearchResults, error) {
+	api.searches++
+	return &shared.SearchResults{Tracks: api.album.Tracks}, nil
+}
+
+// newTestRetagger creates a retagger whose MusicBrainz lookups hit a local stub that finds nothing
+func newTestRetagger(t *testing.T, api RetagAPI, dryRun bool) *Retagger {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	retagger := NewRetagger(api, nil, RetagOptions{DryRun: dryRun, Parallelism: 2}, nil)
+	mbConfig := retagger.processor.mbClient.GetConfig()
+	mbConfig.BaseURL = server.URL + "/ws/2/"
+	mbConfig.RateLimit = time.Millisecond
+	mbConfig.MaxRetries = 1
+	retagger.processor.mbClient.UpdateConfig(mbConfig)
+	return retagger
+}
+
+func testRetagAlbum() shared.Album {
+	return shared.Album{
+		ID:          "album-1",
+		Title:       "Honeymoon",
+		Artist:      "Lana Del Rey",
+		ReleaseDate: "2015-09-18",
+		Tracks: []shared.Track{
+			{ID: 101, Title: "Honeymoon", Artist: "Lana Del Rey", AlbumID: "album-1", TrackNumber: 1},
+			{ID: 102, Title: "Music to Watch Boys To", Artist: "Lana Del Rey", AlbumID: "album-1", TrackNumber: 2},
+		},
+	}
+}
+
+func TestDiffTags(t *testing.T) {
+	oldTags := map[string][]string{"TITLE": {"Old"}, "GENRE": {"Pop"}, "REMOVED": {"x"}}
+	newTags := map[string][]string{"TITLE": {"New"}, "GENRE": {"Pop"}, "ADDED": {"a", "b"}}
+
+	changes := DiffTags(oldTags, newTags)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	expectedFields := []string{"ADDED", "REMOVED", "TITLE"}
+	for i, change := range changes {
+		if change.Field != expectedFields[i] {
+			t.Errorf("Expected change %d to be %s, got %s", i, expectedFields[i], change.Field)
+		}
+	}
+}
+
+func TestIsManagedTag(t *testing.T) {
+	for _, field := range []string{"TITLE", "MUSICBRAINZ_TRACKID", "RELEASETYPE", "DAB_TRACKID"} {
+		if !isManagedTag(field) {
+			t.Errorf("Expected %s to be managed", field)
+		}
+	}
+	for _, field := range []string{"REPLAYGAIN_TRACK_GAIN", "COMMENT", "TITLESORT"} {
+		if isManagedTag(field) {
+			t.Errorf("Expected %s to be carried over", field)
+		}
+	}
+}
+
+func TestRetagFileDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "01 - Honeymoon.flac")
+	writeTestFLAC(t, path, "TITLE=Honeymoon", "ARTIST=Lana Del Rey", "ALBUM=Honeymoon", "TRACKNUMBER=1", "GENRE=Wrong")
+	before, _ := os.ReadFile(path)
+
+	api := &fakeRetagAPI{album: testRetagAlbum()}
+	result := newTestRetagger(t, api, true).RetagFile(context.Background(), path)
+	if result.Err != nil {
+		t.Fatalf("Retag failed: %v", result.Err)
+	}
+	if api.searches != 1 {
+		t.Errorf("Expected the track to be found by search, got %d searches", api.searches)
+	}
+
+	changed := make(map[string]TagChange)
+	for _, change := range result.Changes {
+		changed[change.Field] = change
+	}
+	if change, ok := changed["GENRE"]; !ok || len(change.NewValues) != 0 {
+		t.Errorf("Expected GENRE to be removed, got %+v", change)
+	}
+	if change, ok := changed["DATE"]; !ok || change.NewValues[0] != "2015-09-18" {
+		t.Errorf("Expected DATE to be added, got %+v", change)
+	}
+	if _, ok := changed["TITLE"]; ok {
+		t.Error("Expected unchanged TITLE not to be reported")
+	}
+
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Error("Dry run must not modify the file")
+	}
+}
+
+func TestRetagFilesRewritesTagsOnly(t *testing.T) {
+	root := t.TempDir()
+	paths := []string{
+		filepath.Join(root, "Lana Del Rey", "Honeymoon", "01 - Honeymoon.flac"),
+		filepath.Join(root, "Lana Del Rey", "Honeymoon", "02 - Music to Watch Boys To.flac"),
+	}
+	writeTestFLAC(t, paths[0], "TITLE=Honeymoon", "ARTIST=Lana Del Rey", "REPLAYGAIN_TRACK_GAIN=-6.50 dB", TagDABTrackID+"=101", TagDABAlbumID+"=album-1", TagDABDownloaded+"=2025-01-02T03:04:05Z")
+	writeTestFLAC(t, paths[1], "TITLE=Music to Watch Boys To", "ARTIST=Lana Del Rey", TagDABTrackID+"=102", TagDABAlbumID+"=album-1")
+
+	files, err := FindFLACFiles(root)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected 2 FLAC files, got %v (%v)", files, err)
+	}
+
+	api := &fakeRetagAPI{album: testRetagAlbum()}
+	results := newTestRetagger(t, api, false).RetagFiles(context.Background(), files, nil)
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("Retag of %s failed: %v", result.FilePath, result.Err)
+		}
+	}
+	if api.searches != 0 {
+		t.Errorf("Expected DAB IDs to avoid searching, got %d searches", api.searches)
+	}
+
+	f, err := flac.ParseFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to parse retagged file: %v", err)
+	}
+	if !bytes.Equal(f.Frames, fakeAudioFrames) {
+		t.Error("Audio frames must be left untouched")
+	}
+	hasPicture := false
+	for _, block := range f.Meta {
+		if block.Type == flac.Picture {
+			hasPicture = true
+		}
+	}
+	if !hasPicture {
+		t.Error("Expected the picture block to be kept")
+	}
+
+	comment, err := ReadVorbisComment(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read tags: %v", err)
+	}
+	tags := VorbisCommentFields(comment)
+	if firstTag(tags, "TOTALTRACKS") != "2" || firstTag(tags, "ALBUM") != "Honeymoon" {
+		t.Errorf("Expected album tags to be written, got %v", tags)
+	}
+	if firstTag(tags, "REPLAYGAIN_TRACK_GAIN") != "-6.50 dB" {
+		t.Errorf("Expected unmanaged fields to be carried over, got %v", tags)
+	}
+	if firstTag(tags, TagDABDownloaded) != "2025-01-02T03:04:05Z" || firstTag(tags, TagDABQuality) != "" {
+		t.Errorf("Expected the original download time and quality to be kept, got %v", tags)
+	}
+	if firstTag(tags, TagDABTrackID) != "101" || len(tags[TagDABTrackID]) != 1 {
+		t.Errorf("Expected a single DAB track ID, got %v", tags[TagDABTrackID])
+	}
+}
@@ -0,0 +1,146 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-flac/flacvorbis"
+
+	"dab-downloader/internal/shared"
+)
+
+// Source tags map a file back to the DAB track it was downloaded from. They are written as Vorbis
+// comments and carried into converted formats by ffmpeg's -map_metadata (TXXX frames in MP3).
+const (
+	TagDABTrackID    = "DAB_TRACKID"
+	TagDABAlbumID    = "DAB_ALBUMID"
+	TagDABArtistID   = "DAB_ARTISTID"
+	TagDABDownloaded = "DAB_DOWNLOADED" // RFC 3339 UTC timestamp of the download
+	TagDABQuality    = "DAB_QUALITY"    // Delivered bit depth and sample rate in kHz, e.g. "24/96"
+)
+
+// SourceIDs holds the DAB identifiers stored in a downloaded file
+type SourceIDs struct {
+	TrackID      string
+	AlbumID      string
+	ArtistID     string
+	DownloadedAt time.Time // Zero when the file has no download timestamp
+	Quality      string
+}
+
+// HasTrackID reports whether the file can be mapped back to a DAB track
+func (ids *SourceIDs) HasTrackID() bool {
+	return ids != nil && ids.TrackID != ""
+}
+
+// addSourceMetadata adds the DAB identifiers, download time and delivered quality
+func (mp *MetadataProcessor) addSourceMetadata(comment *flacvorbis.MetaDataBlockVorbisComment, filePath string, track shared.Track, album *shared.Album) {
+	if track.ID != nil {
+		addField(comment, TagDABTrackID, shared.IdToString(track.ID))
+	}
+
+	albumID := track.AlbumID
+	if albumID == "" && album != nil {
+		albumID = album.ID
+	}
+	addField(comment, TagDABAlbumID, albumID)
+
+	if track.ArtistId != nil {
+		addField(comment, TagDABArtistID, shared.IdToString(track.ArtistId))
+	}
+
+	addField(comment, TagDABDownloaded, time.Now().UTC().Format(time.RFC3339))
+	addField(comment, TagDABQuality, deliveredQuality(filePath, track))
+}
+
+// deliveredQuality describes the audio DAB delivered as "bit depth/sample rate", read from the FLAC
+// STREAMINFO. The quality DAB reports for the track is used when the file has none.
+func deliveredQuality(filePath string, track shared.Track) string {
+	quality := track.AudioQuality
+	if _, _, streamQuality, err := ReadTags(filePath); err == nil && streamQuality != nil {
+		quality = *streamQuality
+	}
+	if quality.MaximumBitDepth <= 0 || quality.MaximumSamplingRate <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s", quality.MaximumBitDepth, strconv.FormatFloat(quality.MaximumSamplingRate, 'f', -1, 64))
+}
+
+// ReadSourceIDs extracts the DAB source identifiers from a downloaded file
+func ReadSourceIDs(filePath string) (*SourceIDs, error) {
+	tags, _, _, err := ReadTags(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return sourceIDsFromTags(tags), nil
+}
+
+// sourceIDsFromTags builds source identifiers from upper case tag fields
+func sourceIDsFromTags(tags map[string][]string) *SourceIDs {
+	ids := &SourceIDs{
+		TrackID:  firstTag(tags, TagDABTrackID),
+		AlbumID:  firstTag(tags, TagDABAlbumID),
+		ArtistID: firstTag(tags, TagDABArtistID),
+		Quality:  firstTag(tags, TagDABQuality),
+	}
+	if downloaded := firstTag(tags, TagDABDownloaded); downloaded != "" {
+		if downloadedAt, err := time.Parse(time.RFC3339, downloaded); err == nil {
+			ids.DownloadedAt = downloadedAt
+		}
+	}
+	return ids
+}
+
+// audioProbe holds the tags and duration ffprobe reports for an audio file
+type audioProbe struct {
+	Tags     map[string][]string // Upper case field names
+	Duration float64             // Seconds
+}
+
+// probeAudioFile reads container and stream tags of any audio file using ffprobe
+func probeAudioFile(filePath string) (*audioProbe, error) {
+	output, err := exec.Command("ffprobe", "-v", "quiet", "-print_format", "json", "-show_entries", "format=duration:format_tags:stream_tags", filePath).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags with ffprobe: %w", err)
+	}
+	return parseFFprobeOutput(output)
+}
+
+// parseFFprobeOutput parses ffprobe JSON output. MP3 keeps the tags on the container, Ogg and Opus on the stream.
+func parseFFprobeOutput(output []byte) (*audioProbe, error) {
+	var probe struct {
+		Format struct {
+			Duration string            `json:"duration"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+		Streams []struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	result := &audioProbe{Tags: make(map[string][]string)}
+	add := func(source map[string]string) {
+		for key, value := range source {
+			key = strings.ToUpper(key)
+			if _, exists := result.Tags[key]; !exists {
+				result.Tags[key] = []string{value}
+			}
+		}
+	}
+	add(probe.Format.Tags)
+	for _, stream := range probe.Streams {
+		add(stream.Tags)
+	}
+
+	if probe.Format.Duration != "" {
+		result.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package downloader
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/shared"
+)
+
+func TestAddSourceMetadata(t *testing.T) {
+	processor := NewMetadataProcessor()
+	comment := flacvorbis.New()
+
+	// The test file has an empty STREAMINFO, so the quality DAB reported is used
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path)
+	track := shared.Track{ID: 12345, ArtistId: 678, Title: "Honeymoon", AudioQuality: shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96}}
+	album := &shared.Album{ID: "album-1"}
+	processor.addSourceMetadata(comment, path, track, album)
+
+	ids := sourceIDsFromTags(VorbisCommentFields(comment))
+	if ids.TrackID != "12345" || ids.AlbumID != "album-1" || ids.ArtistID != "678" {
+		t.Errorf("Unexpected source IDs: %+v", ids)
+	}
+	if ids.Quality != "24/96" {
+		t.Errorf("Expected quality 24/96, got %s", ids.Quality)
+	}
+	if time.Since(ids.DownloadedAt) > time.Minute {
+		t.Errorf("Expected a current download timestamp, got %v", ids.DownloadedAt)
+	}
+}
+
+func TestDeliveredQualityFromStreamInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path)
+
+	// Rewrite STREAMINFO as 44.1 kHz, stereo, 16 bit
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse test FLAC: %v", err)
+	}
+	data := f.Meta[0].Data
+	sampleRate, channels, bitDepth := 44100, 2, 16
+	data[10] = byte(sampleRate >> 12)
+	data[11] = byte(sampleRate >> 4)
+	data[12] = byte(sampleRate&0xf)<<4 | byte(channels-1)<<1 | byte(bitDepth-1)>>4
+	data[13] = byte(bitDepth-1) << 4
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Failed to save test FLAC: %v", err)
+	}
+
+	// The file wins over a hi-res quality reported for the track
+	track := shared.Track{AudioQuality: shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 192}}
+	if got := deliveredQuality(path, track); got != "16/44.1" {
+		t.Errorf("Expected quality 16/44.1, got %s", got)
+	}
+}
+
+func TestReadSourceIDsFLAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path, "TITLE=Honeymoon", "dab_trackid=12345", "DAB_ALBUMID=album-1", "DAB_DOWNLOADED=2025-01-02T03:04:05Z")
+
+	ids, err := ReadSourceIDs(path)
+	if err != nil {
+		t.Fatalf("Failed to read source IDs: %v", err)
+	}
+	if !ids.HasTrackID() || ids.TrackID != "12345" || ids.AlbumID != "album-1" {
+		t.Errorf("Unexpected source IDs: %+v", ids)
+	}
+	if !ids.DownloadedAt.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected download time: %v", ids.DownloadedAt)
+	}
+
+	untagged := filepath.Join(t.TempDir(), "untagged.flac")
+	writeTestFLAC(t, untagged)
+	if ids, err := ReadSourceIDs(untagged); err != nil || ids.HasTrackID() {
+		t.Errorf("Expected no source IDs for an untagged file, got %+v (%v)", ids, err)
+	}
+}
+
+func TestParseFFprobeOutput(t *testing.T) {
+	// MP3 stores the tags on the container, Opus on the stream
+	mp3 := []byte(`{"format":{"duration":"215.480000","tags":{"title":"Honeymoon","DAB_TRACKID":"12345"}},"streams":[{}]}`)
+	opus := []byte(`{"format":{},"streams":[{"tags":{"TITLE":"Honeymoon","dab_trackid":"12345","DAB_QUALITY":"24/96"}}]}`)
+
+	for name, output := range map[string][]byte{"mp3": mp3, "opus": opus} {
+		probe, err := parseFFprobeOutput(output)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", name, err)
+		}
+		if ids := sourceIDsFromTags(probe.Tags); ids.TrackID != "12345" {
+			t.Errorf("%s: expected track ID 12345, got %+v", name, ids)
+		}
+	}
+
+	probe, _ := parseFFprobeOutput(mp3)
+	if probe.Duration != 215.48 {
+		t.Errorf("Expected duration 215.48, got %v", probe.Duration)
+	}
+}
@@ -0,0 +1,245 @@
+package downloader
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Types
+// ============================================================================
+
+// FieldMismatch describes a tag whose value differs from the expected track
+type FieldMismatch struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// MetadataMismatchError is returned when a file's tags do not match the expected track
+type MetadataMismatchError struct {
+	FilePath   string
+	Mismatches []FieldMismatch
+}
+
+func (e *MetadataMismatchError) Error() string {
+	parts := make([]string, 0, len(e.Mismatches))
+	for _, mismatch := range e.Mismatches {
+		parts = append(parts, fmt.Sprintf("%s (expected %q, got %q)", mismatch.Field, mismatch.Expected, mismatch.Actual))
+	}
+	return fmt.Sprintf("metadata mismatch in %s: %s", filepath.Base(e.FilePath), strings.Join(parts, ", "))
+}
+
+// ============================================================================
+// 2. Reading Tags
+// ============================================================================
+
+// ReadTags reads the tags of an audio file keyed by upper case field name, along with its duration in seconds.
+// FLAC files are read natively, other formats through ffprobe.
+func ReadTags(filePath string) (map[string][]string, float64, *shared.AudioQuality, error) {
+	if !strings.EqualFold(filepath.Ext(filePath), ".flac") {
+		probe, err := probeAudioFile(filePath)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return probe.Tags, probe.Duration, nil, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	defer file.Close()
+
+	f, err := flac.ParseMetadata(file)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to parse FLAC metadata: %w", err)
+	}
+
+	tags := make(map[string][]string)
+	for _, block := range f.Meta {
+		if block.Type == flac.VorbisComment {
+			comment, err := flacvorbis.ParseFromMetaDataBlock(*block)
+			if err != nil {
+				return nil, 0, nil, fmt.Errorf("failed to parse Vorbis comment: %w", err)
+			}
+			tags = VorbisCommentFields(comment)
+			break
+		}
+	}
+
+	var duration float64
+	var quality *shared.AudioQuality
+	if streamInfo, err := f.GetStreamInfo(); err == nil && streamInfo.SampleRate > 0 {
+		duration = float64(streamInfo.SampleCount) / float64(streamInfo.SampleRate)
+		quality = &shared.AudioQuality{
+			MaximumBitDepth:     streamInfo.BitDepth,
+			MaximumSamplingRate: float64(streamInfo.SampleRate) / 1000,
+			IsHiRes:             streamInfo.BitDepth > 16 || streamInfo.SampleRate > 48000,
+		}
+	}
+	return tags, duration, quality, nil
+}
+
+// ExtractTrackMetadata reads the tags of an audio file into a track
+func ExtractTrackMetadata(filePath string) (*shared.Track, error) {
+	tags, duration, quality, err := ReadTags(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	track := trackFromTags(tags)
+	if duration > 0 {
+		track.Duration = int(math.Round(duration))
+	}
+	if quality != nil {
+		track.AudioQuality = *quality
+	}
+	return track, nil
+}
+
+// ============================================================================
+// 3. Writing Tags
+// ============================================================================
+
+// AddLossyMetadata writes the same tags AddMetadata writes to FLAC into an MP3, Ogg or Opus file.
+// Cover art is only embedded in FLAC files.
+func (mp *MetadataProcessor) AddLossyMetadata(filePath string, track shared.Track, album *shared.Album, totalTracks int, warningCollector *shared.WarningCollector) error {
+	comment := mp.buildVorbisComment(filePath, track, album, totalTracks, warningCollector)
+	return WriteTags(filePath, VorbisCommentFields(comment))
+}
+
+// ============================================================================
+// 4. Validation
+// ============================================================================
+
+// ValidateTrackMetadata checks that the tags of a file match the track they were written from
+func ValidateTrackMetadata(filePath string, expected shared.Track, album *shared.Album) error {
+	tags, _, _, err := ReadTags(filePath)
+	if err != nil {
+		return err
+	}
+
+	mismatches := diffTrackTags(tags, expected, album)
+	if len(mismatches) > 0 {
+		return &MetadataMismatchError{FilePath: filePath, Mismatches: mismatches}
+	}
+	return nil
+}
+
+// diffTrackTags compares the fields the downloader always writes from DAB data
+func diffTrackTags(tags map[string][]string, expected shared.Track, album *shared.Album) []FieldMismatch {
+	var mismatches []FieldMismatch
+	check := func(field, expectedValue string, matches func(actual string) bool) {
+		if expectedValue == "" {
+			return
+		}
+		actual := firstTag(tags, field)
+		if !matches(actual) {
+			mismatches = append(mismatches, FieldMismatch{Field: field, Expected: expectedValue, Actual: actual})
+		}
+	}
+	exact := func(expectedValue string) func(string) bool {
+		return func(actual string) bool { return actual == expectedValue }
+	}
+
+	check(flacvorbis.FIELD_TITLE, expected.Title, exact(expected.Title))
+	check(flacvorbis.FIELD_ARTIST, expected.Artist, func(actual string) bool { return artistMatches(actual, expected.Artist) })
+	albumTitle := expected.Album
+	if album != nil && album.Title != "" {
+		albumTitle = album.Title
+	}
+	check(flacvorbis.FIELD_ALBUM, albumTitle, exact(albumTitle))
+
+	trackNumber := strconv.Itoa(max(expected.TrackNumber, 1))
+	check(flacvorbis.FIELD_TRACKNUMBER, trackNumber, exact(trackNumber))
+	discNumber := strconv.Itoa(max(expected.DiscNumber, 1))
+	check("DISCNUMBER", discNumber, exact(discNumber))
+
+	check("ISRC", expected.ISRC, func(actual string) bool { return strings.EqualFold(actual, expected.ISRC) })
+	if expected.ID != nil {
+		trackID := shared.IdToString(expected.ID)
+		check(TagDABTrackID, trackID, exact(trackID))
+	}
+
+	return mismatches
+}
+
+// ============================================================================
+// 5. Helper Functions
+// ============================================================================
+
+// trackFromTags maps tag fields back onto a track
+func trackFromTags(tags map[string][]string) *shared.Track {
+	track := &shared.Track{
+		Title:         firstTag(tags, flacvorbis.FIELD_TITLE),
+		Artist:        firstTag(tags, flacvorbis.FIELD_ARTIST),
+		Album:         firstTag(tags, flacvorbis.FIELD_ALBUM),
+		AlbumTitle:    firstTag(tags, flacvorbis.FIELD_ALBUM),
+		AlbumArtist:   firstTag(tags, "ALBUMARTIST"),
+		Genre:         firstTag(tags, "GENRE"),
+		Composer:      firstTag(tags, "COMPOSER"),
+		Producer:      firstTag(tags, "PRODUCER"),
+		ISRC:          firstTag(tags, "ISRC"),
+		Copyright:     firstTag(tags, "COPYRIGHT"),
+		ReleaseDate:   firstTag(tags, flacvorbis.FIELD_DATE),
+		Year:          firstTag(tags, "YEAR"),
+		AlbumID:       firstTag(tags, TagDABAlbumID),
+		MusicBrainzID: firstTag(tags, "MUSICBRAINZ_TRACKID"),
+	}
+
+	if trackID := firstTag(tags, TagDABTrackID); trackID != "" {
+		track.ID = trackID
+	}
+	if artistID := firstTag(tags, TagDABArtistID); artistID != "" {
+		track.ArtistId = artistID
+	}
+	if track.Year == "" && len(track.ReleaseDate) >= 4 {
+		track.Year = track.ReleaseDate[:4]
+	}
+
+	track.TrackNumber = parseNumberTag(firstTag(tags, flacvorbis.FIELD_TRACKNUMBER))
+	track.DiscNumber = parseNumberTag(firstTag(tags, "DISCNUMBER"))
+	track.Duration = parseNumberTag(firstTag(tags, "LENGTH"))
+
+	return track
+}
+
+// artistMatches compares artists loosely. MusicBrainz release tags may rewrite the credit with
+// different join phrases, so only the first credited artist has to appear in the tag.
+func artistMatches(actual, expected string) bool {
+	actual, expected = strings.ToLower(actual), strings.ToLower(expected)
+	if actual == "" {
+		return false
+	}
+	if strings.Contains(actual, expected) || strings.Contains(expected, actual) {
+		return true
+	}
+
+	primary := expected
+	for _, separator := range []string{",", "&", ";", " feat", " ft.", " with ", " x "} {
+		if index := strings.Index(primary, separator); index > 0 {
+			primary = primary[:index]
+		}
+	}
+	return strings.Contains(actual, strings.TrimSpace(primary))
+}
+
+// parseNumberTag parses numbers such as "3" or "3/12", returning 0 when the tag is not numeric
+func parseNumberTag(value string) int {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), "/")
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return number
+}
@@ -0,0 +1,122 @@
+package downloader
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/shared"
+)
+
+// setTestStreamInfo rewrites the STREAMINFO block of a test FLAC with the given audio properties
+func setTestStreamInfo(t *testing.T, path string, sampleRate, channels, bitDepth int, samples int64) {
+	t.Helper()
+
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse test FLAC: %v", err)
+	}
+	data := make([]byte, 34)
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(bitDepth-1)<<36 | uint64(samples)
+	binary.BigEndian.PutUint64(data[10:18], packed)
+	f.Meta[0].Data = data
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Failed to save test FLAC: %v", err)
+	}
+}
+
+func taggedTestTrack() shared.Track {
+	return shared.Track{ID: 42, Title: "Cinnamon Girl", Artist: "Lana Del Rey", Album: "Norman Fucking Rockwell!", TrackNumber: 13, DiscNumber: 1, ISRC: "USUM71910000"}
+}
+
+func TestExtractTrackMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.flac")
+	writeTestFLAC(t, path,
+		"TITLE=Cinnamon Girl", "ARTIST=Lana Del Rey", "ALBUM=Norman Fucking Rockwell!", "ALBUMARTIST=Lana Del Rey",
+		"TRACKNUMBER=13", "DISCNUMBER=1/2", "DATE=2019-08-30", "ISRC=USUM71910000", "GENRE=Pop",
+		"DAB_TRACKID=42", "DAB_ALBUMID=album-7", "DAB_ARTISTID=99", "MUSICBRAINZ_TRACKID=mb-recording", "LENGTH=300")
+	setTestStreamInfo(t, path, 96000, 2, 24, 96000*180)
+
+	track, err := ExtractTrackMetadata(path)
+	if err != nil {
+		t.Fatalf("Failed to extract metadata: %v", err)
+	}
+
+	if track.Title != "Cinnamon Girl" || track.Artist != "Lana Del Rey" || track.Album != "Norman Fucking Rockwell!" {
+		t.Errorf("Unexpected essential fields: %+v", track)
+	}
+	if track.TrackNumber != 13 || track.DiscNumber != 1 {
+		t.Errorf("Expected track 13 disc 1, got %d/%d", track.TrackNumber, track.DiscNumber)
+	}
+	if track.Year != "2019" || track.ReleaseDate != "2019-08-30" {
+		t.Errorf("Expected year from date, got %q/%q", track.Year, track.ReleaseDate)
+	}
+	if shared.IdToString(track.ID) != "42" || track.AlbumID != "album-7" || shared.IdToString(track.ArtistId) != "99" {
+		t.Errorf("Expected DAB identifiers, got %v/%s/%v", track.ID, track.AlbumID, track.ArtistId)
+	}
+	if track.MusicBrainzID != "mb-recording" {
+		t.Errorf("Expected MusicBrainz ID, got %q", track.MusicBrainzID)
+	}
+	// The stream duration wins over the LENGTH tag
+	if track.Duration != 180 {
+		t.Errorf("Expected duration 180 from STREAMINFO, got %d", track.Duration)
+	}
+	if track.AudioQuality.MaximumBitDepth != 24 || track.AudioQuality.MaximumSamplingRate != 96 || !track.AudioQuality.IsHiRes {
+		t.Errorf("Unexpected audio quality: %+v", track.AudioQuality)
+	}
+}
+
+func TestValidateTrackMetadata(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.flac")
+	writeTestFLAC(t, valid,
+		"TITLE=Cinnamon Girl", "ARTIST=Lana Del Rey", "ALBUM=Norman Fucking Rockwell!",
+		"TRACKNUMBER=13", "DISCNUMBER=1", "ISRC=USUM71910000", "DAB_TRACKID=42")
+	if err := ValidateTrackMetadata(valid, taggedTestTrack(), nil); err != nil {
+		t.Errorf("Expected matching tags to validate, got %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.flac")
+	writeTestFLAC(t, invalid,
+		"TITLE=Cinnamon Girl", "ARTIST=Lana Del Rey", "ALBUM=Norman Fucking Rockwell!",
+		"TRACKNUMBER=12", "DISCNUMBER=1", "DAB_TRACKID=42")
+	err := ValidateTrackMetadata(invalid, taggedTestTrack(), nil)
+
+	var mismatchErr *MetadataMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("Expected a MetadataMismatchError, got %v", err)
+	}
+	if len(mismatchErr.Mismatches) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %+v", mismatchErr.Mismatches)
+	}
+	if mismatch := mismatchErr.Mismatches[0]; mismatch.Field != "TRACKNUMBER" || mismatch.Expected != "13" || mismatch.Actual != "12" {
+		t.Errorf("Unexpected track number mismatch: %+v", mismatch)
+	}
+	if mismatch := mismatchErr.Mismatches[1]; mismatch.Field != "ISRC" || mismatch.Actual != "" {
+		t.Errorf("Unexpected ISRC mismatch: %+v", mismatch)
+	}
+}
+
+func TestArtistMatches(t *testing.T) {
+	tests := []struct {
+		actual   string
+		expected string
+		matches  bool
+	}{
+		{"Lana Del Rey", "Lana Del Rey", true},
+		{"Lana Del Rey feat. Father John Misty", "Lana Del Rey", true},
+		{"Calvin Harris & Dua Lipa", "Calvin Harris, Dua Lipa", true},
+		{"Taylor Swift", "Lana Del Rey", false},
+		{"", "Lana Del Rey", false},
+	}
+
+	for _, test := range tests {
+		if got := artistMatches(test.actual, test.expected); got != test.matches {
+			t.Errorf("artistMatches(%q, %q) = %v, expected %v", test.actual, test.expected, got, test.matches)
+		}
+	}
+}
@@ -0,0 +1,594 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Constants and Types
+// ============================================================================
+
+// audioExtensions are the file types the upgrade scan looks at
+var audioExtensions = []string{".flac", ".mp3", ".ogg", ".opus", ".m4a"}
+
+// losslessCodecs are the ffprobe codec names of lossless formats
+var losslessCodecs = []string{"flac", "alac", "wavpack", "ape", "tta"}
+
+// audioTags describe the audio stream rather than the recording. They are always taken from the
+// new download when a file is upgraded.
+var audioTags = []string{TagDABDownloaded, TagDABQuality, "ENCODER", "ENCODING", "LENGTH"}
+
+// ffmpegTagNames maps the names ffprobe reports for tags of lossy files to Vorbis comment fields
+var ffmpegTagNames = map[string]string{
+	"TRACK":        "TRACKNUMBER",
+	"DISC":         "DISCNUMBER",
+	"ALBUM_ARTIST": "ALBUMARTIST",
+	"TSRC":         "ISRC",
+}
+
+// UpgradeAPI is the subset of the DAB API needed to find better streams for existing files
+type UpgradeAPI interface {
+	RetagAPI
+	DownloadCover(ctx context.Context, coverURL string) ([]byte, error)
+}
+
+// TrackFetcher downloads a tagged FLAC track, as TrackDownloader does
+type TrackFetcher interface {
+	DownloadTrack(ctx context.Context, track shared.Track, album *shared.Album, options DownloadOptions, coverData []byte, progressBar *pb.ProgressBar, warningCollector *shared.WarningCollector) (*DownloadResult, error)
+}
+
+// UpgradeOptions controls an upgrade run
+type UpgradeOptions struct {
+	Parallelism int
+	Debug       bool
+}
+
+// FileQuality is the audio quality of a file on disk
+type FileQuality struct {
+	Codec      string // ffprobe codec name, e.g. "flac" or "mp3"
+	Lossless   bool
+	BitDepth   int     // Zero for lossy codecs
+	SampleRate float64 // kHz
+	Bitrate    int     // kbps, only known for lossy codecs
+}
+
+// UpgradeCandidate is a file checked against the quality DAB offers for its album
+type UpgradeCandidate struct {
+	FilePath  string
+	Track     string // "Artist - Title" of the matched DAB track
+	Current   FileQuality
+	Available shared.AudioQuality
+	Better    bool // Available is strictly better than Current
+	Err       error
+
+	track *shared.Track
+	album *shared.Album
+}
+
+// UpgradeResult holds the outcome of replacing a single file
+type UpgradeResult struct {
+	FilePath  string
+	NewPath   string // Differs from FilePath when a lossy file was replaced by FLAC
+	Track     string
+	Previous  FileQuality
+	Delivered FileQuality
+	Err       error
+}
+
+// Upgrader finds files that DAB offers in a better quality and replaces them
+type Upgrader struct {
+	api              UpgradeAPI
+	fetcher          TrackFetcher
+	resolver         *Retagger
+	options          UpgradeOptions
+	verify           bool
+	warningCollector *shared.WarningCollector
+	covers           map[string][]byte
+	mu               sync.Mutex
+}
+
+// ============================================================================
+// 2. Constructor
+// ============================================================================
+
+// NewUpgrader creates an upgrader that downloads replacements with the given fetcher
+func NewUpgrader(api UpgradeAPI, fetcher TrackFetcher, cfg *config.Config, options UpgradeOptions, warningCollector *shared.WarningCollector) *Upgrader {
+	if options.Parallelism <= 0 {
+		options.Parallelism = DefaultRetagParallelism
+	}
+
+	// Tracks are matched the same way retag does; MusicBrainz data is not needed for that
+	resolver := NewRetagger(api, cfg, RetagOptions{Parallelism: options.Parallelism, Debug: options.Debug}, warningCollector)
+	resolver.lookupReleases = false
+
+	return &Upgrader{
+		api:              api,
+		fetcher:          fetcher,
+		resolver:         resolver,
+		options:          options,
+		verify:           cfg != nil && cfg.VerifyDownloads,
+		warningCollector: warningCollector,
+		covers:           make(map[string][]byte),
+	}
+}
+
+// ============================================================================
+// 3. Public API Methods
+// ============================================================================
+
+// FindAudioFiles returns all audio files below a path, or the path itself if it is a file.
+// Temporary files of downloads in progress are left out.
+func FindAudioFiles(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !shared.IsTempFile(d.Name()) && isAudioFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// CheckFiles compares files with the quality DAB offers in parallel, calling onResult as each file completes.
+// Results are returned in input order.
+func (u *Upgrader) CheckFiles(ctx context.Context, files []string, onResult func(UpgradeCandidate)) []UpgradeCandidate {
+	candidates := make([]UpgradeCandidate, len(files))
+	var callbackMu sync.Mutex
+	u.forEach(ctx, len(files), func(i int) {
+		if ctx.Err() != nil {
+			candidates[i] = UpgradeCandidate{FilePath: files[i], Err: ctx.Err()}
+		} else {
+			candidates[i] = u.CheckFile(ctx, files[i])
+		}
+		if onResult != nil {
+			callbackMu.Lock()
+			onResult(candidates[i])
+			callbackMu.Unlock()
+		}
+	})
+	return candidates
+}
+
+// CheckFile reads the quality of a file and looks up the quality DAB offers for its album
+func (u *Upgrader) CheckFile(ctx context.Context, filePath string) UpgradeCandidate {
+	candidate := UpgradeCandidate{FilePath: filePath}
+
+	tags, quality, err := readFileQuality(filePath)
+	if err != nil {
+		candidate.Err = err
+		return candidate
+	}
+	candidate.Current = quality
+
+	track, album, err := u.resolver.resolveTrack(ctx, tags)
+	if err != nil {
+		candidate.Err = err
+		return candidate
+	}
+	candidate.Track = fmt.Sprintf("%s - %s", track.Artist, track.Title)
+	candidate.track, candidate.album = track, album
+
+	candidate.Available = track.AudioQuality
+	if album != nil && album.AudioQuality.MaximumBitDepth > 0 {
+		candidate.Available = album.AudioQuality
+	}
+	candidate.Better = IsBetterQuality(candidate.Current, candidate.Available)
+	return candidate
+}
+
+// UpgradeFiles replaces the files of the candidates that have a better stream, showing download progress.
+// onResult is called as each file completes; results are returned in input order.
+func (u *Upgrader) UpgradeFiles(ctx context.Context, candidates []UpgradeCandidate, onResult func(UpgradeResult)) []UpgradeResult {
+	var upgrades []UpgradeCandidate
+	for _, candidate := range candidates {
+		if candidate.Better && candidate.Err == nil {
+			upgrades = append(upgrades, candidate)
+		}
+	}
+
+	display := NewProgressDisplay()
+	display.Begin(len(upgrades))
+	defer display.End()
+
+	results := make([]UpgradeResult, len(upgrades))
+	var callbackMu sync.Mutex
+	u.forEach(ctx, len(upgrades), func(i int) {
+		if ctx.Err() != nil {
+			results[i] = UpgradeResult{FilePath: upgrades[i].FilePath, Track: upgrades[i].Track, Err: ctx.Err()}
+			display.Done(nil)
+		} else {
+			bar := display.Bar(*upgrades[i].track)
+			results[i] = u.UpgradeFile(ctx, upgrades[i], bar)
+			display.Done(bar)
+		}
+		if onResult != nil {
+			callbackMu.Lock()
+			onResult(results[i])
+			callbackMu.Unlock()
+		}
+	})
+	return results
+}
+
+// UpgradeFile downloads the better stream of a candidate and swaps it in for the file on disk. The tags
+// of the old file are kept and only the fields describing the audio are refreshed. The old file stays
+// untouched unless the new one is complete and strictly better.
+func (u *Upgrader) UpgradeFile(ctx context.Context, candidate UpgradeCandidate, progressBar *pb.ProgressBar) UpgradeResult {
+	result := UpgradeResult{FilePath: candidate.FilePath, Track: candidate.Track, Previous: candidate.Current}
+	if candidate.track == nil {
+		result.Err = fmt.Errorf("no DAB track matched")
+		return result
+	}
+
+	oldComment, pictures, err := readKeptMetadata(candidate.FilePath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	target := strings.TrimSuffix(candidate.FilePath, filepath.Ext(candidate.FilePath)) + ".flac"
+	staging, err := shared.CreateTempFile(target)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create temporary file: %w", err)
+		return result
+	}
+	staging.Close()
+	stagingPath := staging.Name()
+	defer os.Remove(stagingPath) // Already gone once the upgrade is committed
+
+	var coverData []byte
+	if len(pictures) == 0 {
+		coverData = u.coverData(ctx, candidate.album)
+	}
+	options := DownloadOptions{
+		OutputPath:      stagingPath,
+		Format:          "flac",
+		Debug:           u.options.Debug,
+		VerifyDownloads: true,
+		VerifyMetadata:  u.verify,
+	}
+	if _, err := u.fetcher.DownloadTrack(ctx, *candidate.track, candidate.album, options, coverData, progressBar, u.warningCollector); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// The album quality is the best of its tracks, so check what was actually delivered
+	_, delivered, err := readFileQuality(stagingPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Delivered = delivered
+	if !IsBetterQuality(candidate.Current, shared.AudioQuality{MaximumBitDepth: delivered.BitDepth, MaximumSamplingRate: delivered.SampleRate}) {
+		result.Err = fmt.Errorf("DAB delivered %s, which is not better than %s", delivered, candidate.Current)
+		return result
+	}
+
+	newComment, err := ReadVorbisComment(stagingPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	merged := mergeUpgradeTags(oldComment, newComment, strings.EqualFold(filepath.Ext(candidate.FilePath), ".flac"))
+	if err := u.resolver.processor.replaceUpgradeMetadata(stagingPath, merged, pictures); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if err := shared.CommitFile(stagingPath, target, AudioFileMode); err != nil {
+		result.Err = err
+		return result
+	}
+	result.NewPath = target
+	if target != candidate.FilePath {
+		if err := os.Remove(candidate.FilePath); err != nil {
+			result.Err = fmt.Errorf("upgraded to %s but failed to remove the old file: %w", filepath.Base(target), err)
+		}
+	}
+	return result
+}
+
+// IsBetterQuality reports whether the available quality is strictly better than the quality of a file:
+// lossless instead of lossy, or a higher bit depth or sampling rate without being lower in the other.
+// An unknown available quality is never better.
+func IsBetterQuality(current FileQuality, available shared.AudioQuality) bool {
+	if available.MaximumBitDepth == 0 || available.MaximumSamplingRate == 0 {
+		return false
+	}
+	if !current.Lossless {
+		return true
+	}
+
+	const tolerance = 0.05 // Sampling rates are rounded to 0.1 kHz
+	notWorse := available.MaximumBitDepth >= current.BitDepth && available.MaximumSamplingRate > current.SampleRate-tolerance
+	better := available.MaximumBitDepth > current.BitDepth || available.MaximumSamplingRate > current.SampleRate+tolerance
+	return notWorse && better
+}
+
+// String formats the quality like "FLAC 16/44.1" or "MP3 320 kbps"
+func (q FileQuality) String() string {
+	codec := strings.ToUpper(q.Codec)
+	switch {
+	case q.Lossless && q.BitDepth > 0:
+		return fmt.Sprintf("%s %d/%s", codec, q.BitDepth, strconv.FormatFloat(q.SampleRate, 'f', -1, 64))
+	case !q.Lossless && q.Bitrate > 0:
+		return fmt.Sprintf("%s %d kbps", codec, q.Bitrate)
+	default:
+		return codec
+	}
+}
+
+// ============================================================================
+// 4. Private Methods
+// ============================================================================
+
+// forEach runs fn for the indexes 0 to n-1 on the configured number of workers
+func (u *Upgrader) forEach(ctx context.Context, n int, fn func(i int)) {
+	jobs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < u.options.Parallelism && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// coverData downloads the cover of an album once for all of its files
+func (u *Upgrader) coverData(ctx context.Context, album *shared.Album) []byte {
+	if album == nil || album.Cover == "" {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if data, exists := u.covers[album.ID]; exists {
+		return data
+	}
+	data, err := u.api.DownloadCover(ctx, album.Cover)
+	if err != nil {
+		if u.options.Debug {
+			shared.ColorDebug.Printf("Failed to download cover of %s: %v\n", album.Title, err)
+		}
+		data = nil
+	}
+	u.covers[album.ID] = data
+	return data
+}
+
+// replaceUpgradeMetadata swaps the Vorbis comment of a FLAC file and, when pictures are given, its pictures
+func (mp *MetadataProcessor) replaceUpgradeMetadata(filePath string, comment *flacvorbis.MetaDataBlockVorbisComment, pictures []*flac.MetaDataBlock) error {
+	f, err := flac.ParseFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	var meta []*flac.MetaDataBlock
+	for _, block := range f.Meta {
+		if block.Type == flac.VorbisComment || (block.Type == flac.Picture && len(pictures) > 0) {
+			continue
+		}
+		meta = append(meta, block)
+	}
+	commentBlock := comment.Marshal()
+	f.Meta = append(append(meta, &commentBlock), pictures...)
+
+	return mp.saveFLACFile(f, filePath)
+}
+
+// ============================================================================
+// 5. Helper Functions
+// ============================================================================
+
+// readFileQuality reads the tags and audio quality of a file, from STREAMINFO for FLAC and through ffprobe otherwise
+func readFileQuality(filePath string) (map[string][]string, FileQuality, error) {
+	tags, _, quality, err := ReadTags(filePath)
+	if err != nil {
+		return nil, FileQuality{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(filePath), ".flac") {
+		if quality == nil {
+			return nil, FileQuality{}, fmt.Errorf("%s has no stream info", filepath.Base(filePath))
+		}
+		return tags, FileQuality{Codec: "flac", Lossless: true, BitDepth: quality.MaximumBitDepth, SampleRate: quality.MaximumSamplingRate}, nil
+	}
+
+	output, err := exec.Command("ffprobe", "-v", "quiet", "-print_format", "json", "-select_streams", "a:0",
+		"-show_entries", "stream=codec_name,sample_rate,bits_per_sample,bits_per_raw_sample,bit_rate:format=bit_rate", filePath).Output()
+	if err != nil {
+		return nil, FileQuality{}, fmt.Errorf("failed to read stream info with ffprobe: %w", err)
+	}
+	fileQuality, err := parseStreamProbe(output)
+	if err != nil {
+		return nil, FileQuality{}, err
+	}
+	return tags, fileQuality, nil
+}
+
+// parseStreamProbe parses the ffprobe JSON output of the first audio stream
+func parseStreamProbe(output []byte) (FileQuality, error) {
+	var probe struct {
+		Format struct {
+			BitRate string `json:"bit_rate"`
+		} `json:"format"`
+		Streams []struct {
+			CodecName        string `json:"codec_name"`
+			SampleRate       string `json:"sample_rate"`
+			BitsPerSample    int    `json:"bits_per_sample"`
+			BitsPerRawSample string `json:"bits_per_raw_sample"`
+			BitRate          string `json:"bit_rate"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return FileQuality{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	if len(probe.Streams) == 0 {
+		return FileQuality{}, fmt.Errorf("no audio stream found")
+	}
+
+	stream := probe.Streams[0]
+	quality := FileQuality{Codec: stream.CodecName}
+	if sampleRate, err := strconv.Atoi(stream.SampleRate); err == nil {
+		quality.SampleRate = float64(sampleRate) / 1000
+	}
+
+	quality.Lossless = strings.HasPrefix(stream.CodecName, "pcm_")
+	for _, codec := range losslessCodecs {
+		quality.Lossless = quality.Lossless || stream.CodecName == codec
+	}
+	if quality.Lossless {
+		quality.BitDepth = stream.BitsPerSample
+		if bits, err := strconv.Atoi(stream.BitsPerRawSample); err == nil && bits > 0 {
+			quality.BitDepth = bits
+		}
+		return quality, nil
+	}
+
+	bitRate := stream.BitRate
+	if bitRate == "" {
+		bitRate = probe.Format.BitRate
+	}
+	if bps, err := strconv.Atoi(bitRate); err == nil {
+		quality.Bitrate = bps / 1000
+	}
+	return quality, nil
+}
+
+// readKeptMetadata reads the tags and embedded pictures of a file that is about to be replaced
+func readKeptMetadata(filePath string) (*flacvorbis.MetaDataBlockVorbisComment, []*flac.MetaDataBlock, error) {
+	if !strings.EqualFold(filepath.Ext(filePath), ".flac") {
+		tags, _, _, err := ReadTags(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return lossyTagsComment(tags), nil, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	defer file.Close()
+
+	f, err := flac.ParseMetadata(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse FLAC metadata: %w", err)
+	}
+
+	comment := flacvorbis.New()
+	var pictures []*flac.MetaDataBlock
+	for _, block := range f.Meta {
+		switch block.Type {
+		case flac.VorbisComment:
+			if comment, err = flacvorbis.ParseFromMetaDataBlock(*block); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse Vorbis comment: %w", err)
+			}
+		case flac.Picture:
+			pictures = append(pictures, block)
+		}
+	}
+	return comment, pictures, nil
+}
+
+// lossyTagsComment turns the tags ffprobe reports for a lossy file into a Vorbis comment
+func lossyTagsComment(tags map[string][]string) *flacvorbis.MetaDataBlockVorbisComment {
+	comment := flacvorbis.New()
+	fields := make([]string, 0, len(tags))
+	for field := range tags {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		name := field
+		if mapped, exists := ffmpegTagNames[field]; exists {
+			name = mapped
+		}
+		for _, value := range tags[field] {
+			comment.Comments = append(comment.Comments, name+"="+value)
+		}
+	}
+	return comment
+}
+
+// mergeUpgradeTags combines the tags of the file being replaced with those of the new download. Fields
+// describing the audio come from the download. When keepManaged is set the old file's values win for
+// every other field; otherwise, for lossy files whose tags went through ffmpeg, only the fields the
+// metadata processor does not write are carried over. Fields the old file lacks are added either way.
+func mergeUpgradeTags(oldComment, newComment *flacvorbis.MetaDataBlockVorbisComment, keepManaged bool) *flacvorbis.MetaDataBlockVorbisComment {
+	merged := flacvorbis.New()
+	merged.Vendor = newComment.Vendor
+
+	kept := make(map[string]bool)
+	for _, entry := range oldComment.Comments {
+		key, _, ok := strings.Cut(entry, "=")
+		field := strings.ToUpper(key)
+		if !ok || containsField(audioTags, field) || (!keepManaged && isManagedTag(field)) {
+			continue
+		}
+		merged.Comments = append(merged.Comments, entry)
+		kept[field] = true
+	}
+	for _, entry := range newComment.Comments {
+		if key, _, ok := strings.Cut(entry, "="); ok && !kept[strings.ToUpper(key)] {
+			merged.Comments = append(merged.Comments, entry)
+		}
+	}
+	return merged
+}
+
+// isAudioFile reports whether a path has one of the extensions the upgrade scan looks at
+func isAudioFile(path string) bool {
+	return containsField(audioExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/shared"
+)
+
+// fakeUpgradeAPI serves a single album without cover art
+type fakeUpgradeAPI struct {
+	fakeRetagAPI
+}
+
+func (api *fakeUpgradeAPI) DownloadCover(ctx context.Context, coverURL string) ([]byte, error) {
+	return nil, nil
+}
+
+// fakeFetcher writes a tagged FLAC of the given quality instead of downloading
+type fakeFetcher struct {
+	t          *testing.T
+	sampleRate int
+	bitDepth   int
+	downloads  int
+}
+
+func (f *fakeFetcher) DownloadTrack(ctx context.Context, track shared.Track, album *shared.Album, options DownloadOptions, coverData []byte, progressBar *pb.ProgressBar, warningCollector *shared.WarningCollector) (*DownloadResult, error) {
+	f.downloads++
+	writeTestFLAC(f.t, options.OutputPath, "TITLE="+track.Title, "LABEL=Fresh Label", "DAB_TRACKID="+shared.IdToString(track.ID), "DAB_DOWNLOADED=2026-10-18T00:00:00Z")
+	setTestStreamInfo(f.t, options.OutputPath, f.sampleRate, 2, f.bitDepth, int64(f.sampleRate)*180)
+	return &DownloadResult{FilePath: options.OutputPath, Format: "flac"}, nil
+}
+
+func newTestUpgradeAPI() *fakeUpgradeAPI {
+	return &fakeUpgradeAPI{fakeRetagAPI{album: shared.Album{
+		ID:           "a1",
+		Title:        "Album",
+		Artist:       "Artist",
+		AudioQuality: shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96, IsHiRes: true},
+		Tracks:       []shared.Track{{ID: "t1", Title: "Song", Artist: "Artist", TrackNumber: 1}},
+	}}}
+}
+
+func TestIsBetterQuality(t *testing.T) {
+	cd := FileQuality{Codec: "flac", Lossless: true, BitDepth: 16, SampleRate: 44.1}
+	tests := []struct {
+		name      string
+		current   FileQuality
+		available shared.AudioQuality
+		want      bool
+	}{
+		{"lossy to CD", FileQuality{Codec: "mp3", Bitrate: 320}, shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}, true},
+		{"CD to hi-res", cd, shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96}, true},
+		{"deeper at same rate", cd, shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 44.1}, true},
+		{"same quality", cd, shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}, false},
+		{"higher rate but shallower", FileQuality{Codec: "flac", Lossless: true, BitDepth: 24, SampleRate: 48}, shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 96}, false},
+		{"unknown quality", FileQuality{Codec: "mp3"}, shared.AudioQuality{}, false},
+	}
+
+	for _, test := range tests {
+		if got := IsBetterQuality(test.current, test.available); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseStreamProbe(t *testing.T) {
+	mp3, err := parseStreamProbe([]byte(`{"streams":[{"codec_name":"mp3","sample_rate":"44100","bit_rate":"320000"}],"format":{"bit_rate":"321000"}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if mp3.Lossless || mp3.Bitrate != 320 || mp3.SampleRate != 44.1 || mp3.String() != "MP3 320 kbps" {
+		t.Errorf("Unexpected MP3 quality %+v", mp3)
+	}
+
+	alac, err := parseStreamProbe([]byte(`{"streams":[{"codec_name":"alac","sample_rate":"96000","bits_per_sample":0,"bits_per_raw_sample":"24"}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if !alac.Lossless || alac.BitDepth != 24 || alac.String() != "ALAC 24/96" {
+		t.Errorf("Unexpected ALAC quality %+v", alac)
+	}
+
+	if _, err := parseStreamProbe([]byte(`{"streams":[]}`)); err == nil {
+		t.Error("Expected an error without audio streams")
+	}
+}
+
+func TestUpgradeFileKeepsTags(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "01 - Song.flac")
+	writeTestFLAC(t, path, "TITLE=Song (my edit)", "COMMENT=ripped by me", "DAB_TRACKID=t1", "DAB_ALBUMID=a1", "DAB_DOWNLOADED=2020-01-01T00:00:00Z")
+	setTestStreamInfo(t, path, 44100, 2, 16, 44100*180)
+
+	fetcher := &fakeFetcher{t: t, sampleRate: 96000, bitDepth: 24}
+	upgrader := NewUpgrader(newTestUpgradeAPI(), fetcher, nil, UpgradeOptions{Parallelism: 1}, nil)
+
+	candidate := upgrader.CheckFile(context.Background(), path)
+	if candidate.Err != nil || !candidate.Better {
+		t.Fatalf("Expected an upgrade, got %+v", candidate)
+	}
+	if candidate.Current.String() != "FLAC 16/44.1" {
+		t.Errorf("Unexpected current quality %s", candidate.Current)
+	}
+
+	result := upgrader.UpgradeFile(context.Background(), candidate, nil)
+	if result.Err != nil {
+		t.Fatalf("Upgrade failed: %v", result.Err)
+	}
+	if result.NewPath != path || result.Delivered.BitDepth != 24 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	tags, _, quality, err := ReadTags(path)
+	if err != nil {
+		t.Fatalf("Failed to read upgraded file: %v", err)
+	}
+	if quality == nil || quality.MaximumBitDepth != 24 || quality.MaximumSamplingRate != 96 {
+		t.Errorf("File was not replaced, quality %+v", quality)
+	}
+	expected := map[string]string{
+		"TITLE":          "Song (my edit)",
+		"COMMENT":        "ripped by me",
+		"LABEL":          "Fresh Label",
+		"DAB_DOWNLOADED": "2026-10-18T00:00:00Z",
+	}
+	for field, value := range expected {
+		if values := tags[field]; len(values) != 1 || values[0] != value {
+			t.Errorf("%s: got %v, want %q", field, values, value)
+		}
+	}
+
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pictures := 0
+	for _, block := range f.Meta {
+		if block.Type == flac.Picture && string(block.Data) == "picture" {
+			pictures++
+		}
+	}
+	if pictures != 1 {
+		t.Errorf("Expected the original picture to be kept, found %d", pictures)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestUpgradeFileKeepsOriginalWhenNotBetter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "01 - Song.flac")
+	writeTestFLAC(t, path, "TITLE=Song", "DAB_TRACKID=t1", "DAB_ALBUMID=a1")
+	setTestStreamInfo(t, path, 44100, 2, 16, 44100*180)
+	before, _ := os.ReadFile(path)
+
+	// The album claims hi-res but the stream is CD quality
+	fetcher := &fakeFetcher{t: t, sampleRate: 44100, bitDepth: 16}
+	upgrader := NewUpgrader(newTestUpgradeAPI(), fetcher, nil, UpgradeOptions{Parallelism: 1}, nil)
+
+	candidate := upgrader.CheckFile(context.Background(), path)
+	result := upgrader.UpgradeFile(context.Background(), candidate, nil)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "not better") {
+		t.Fatalf("Expected the upgrade to be refused, got %v", result.Err)
+	}
+
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Error("Original file was changed")
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestUpgradeFilesSkipsUpToDateFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "01 - Song.flac")
+	writeTestFLAC(t, path, "TITLE=Song", "DAB_TRACKID=t1", "DAB_ALBUMID=a1")
+	setTestStreamInfo(t, path, 96000, 2, 24, 96000*180)
+
+	fetcher := &fakeFetcher{t: t, sampleRate: 96000, bitDepth: 24}
+	upgrader := NewUpgrader(newTestUpgradeAPI(), fetcher, nil, UpgradeOptions{Parallelism: 2}, nil)
+
+	candidates := upgrader.CheckFiles(context.Background(), []string{path}, nil)
+	if len(candidates) != 1 || candidates[0].Better {
+		t.Fatalf("Expected no upgrade, got %+v", candidates)
+	}
+	if results := upgrader.UpgradeFiles(context.Background(), candidates, nil); len(results) != 0 || fetcher.downloads != 0 {
+		t.Errorf("Up to date file was downloaded again")
+	}
+}
+
+func TestFindAudioFilesSkipsTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.flac", "b.MP3", "c.opus", "cover.jpg", ".d.dab-tmp-1.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := FindAudioFiles(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected 3 audio files, got %v", files)
+	}
+}
+
+// assertNoTempFiles fails when a temporary file was left in a directory
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if shared.IsTempFile(entry.Name()) {
+			t.Errorf("Temporary file %s left behind", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package library
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dab-downloader/internal/core/downloader"
+	"dab-downloader/internal/shared"
+)
+
+// File is an audio file in the library with the track read back from its tags
+type File struct {
+	Path  string
+	Track shared.Track
+}
+
+// Index holds the tags of every audio file below a library root
+type Index struct {
+	Root  string
+	Files []File
+
+	byAlbumID map[string][]File // Files by DAB album ID
+	byTitle   map[string][]File // Files without DAB tags by normalized album title
+}
+
+// ArtistFolder is a top-level folder of the library and the DAB artist its files belong to
+type ArtistFolder struct {
+	Name     string
+	ArtistID string // Empty when no file in the folder has a DAB_ARTISTID tag
+}
+
+// Scan reads the tags of all audio files below root. Files whose tags cannot be read are passed
+// to onError, which may be nil, and left out of the index.
+func Scan(root string, onError func(path string, err error)) (*Index, error) {
+	files, err := downloader.FindAudioFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{Root: root}
+	for _, path := range files {
+		track, err := downloader.ExtractTrackMetadata(path)
+		if err != nil {
+			if onError != nil {
+				onError(path, err)
+			}
+			continue
+		}
+		index.Files = append(index.Files, File{Path: path, Track: *track})
+	}
+	return index, nil
+}
+
+// ArtistFolders returns the top-level folders of the library sorted by name. The artist of a folder
+// is the DAB artist ID found on most of its files.
+func (idx *Index) ArtistFolders() []ArtistFolder {
+	counts := make(map[string]map[string]int)
+	for _, file := range idx.Files {
+		folder := idx.topFolder(file.Path)
+		if folder == "" {
+			continue
+		}
+		if counts[folder] == nil {
+			counts[folder] = make(map[string]int)
+		}
+		if artistID := shared.IdToString(file.Track.ArtistId); artistID != "" {
+			counts[folder][artistID]++
+		}
+	}
+
+	folders := make([]ArtistFolder, 0, len(counts))
+	for name, artistCounts := range counts {
+		folder := ArtistFolder{Name: name}
+		for artistID, count := range artistCounts {
+			best := artistCounts[folder.ArtistID]
+			if count > best || (count == best && artistID < folder.ArtistID) {
+				folder.ArtistID = artistID
+			}
+		}
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	return folders
+}
+
+// buildLookups indexes the files by album once
+func (idx *Index) buildLookups() {
+	if idx.byAlbumID != nil {
+		return
+	}
+	idx.byAlbumID = make(map[string][]File)
+	idx.byTitle = make(map[string][]File)
+	for _, file := range idx.Files {
+		if file.Track.AlbumID != "" {
+			idx.byAlbumID[file.Track.AlbumID] = append(idx.byAlbumID[file.Track.AlbumID], file)
+		} else {
+			title := normalizeTitle(file.Track.Album)
+			idx.byTitle[title] = append(idx.byTitle[title], file)
+		}
+	}
+}
+
+// topFolder returns the name of the top-level folder a file is in, or "" for files directly in the root
+func (idx *Index) topFolder(path string) string {
+	relative, err := filepath.Rel(idx.Root, path)
+	if err != nil {
+		return ""
+	}
+	folder, _, found := strings.Cut(filepath.ToSlash(relative), "/")
+	if !found {
+		return ""
+	}
+	return folder
+}
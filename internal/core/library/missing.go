@@ -0,0 +1,258 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/discography"
+	"dab-downloader/internal/shared"
+)
+
+// Release statuses of a completeness report
+const (
+	StatusOwned   = "owned"
+	StatusPartial = "partial"
+	StatusMissing = "missing"
+)
+
+// ReleaseTypes is the order release types are reported in
+var ReleaseTypes = []string{"album", "ep", "single"}
+
+// ArtistAPI is the subset of the DAB API needed to compare a discography with the library
+type ArtistAPI interface {
+	GetArtist(ctx context.Context, artistID string, cfg *config.Config, debug bool) (*shared.Artist, error)
+	GetAlbum(ctx context.Context, albumID string) (*shared.Album, error)
+}
+
+// Release is a release of the artist and how much of it is in the library
+type Release struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Type          string   `json:"type"` // One of ReleaseTypes
+	Year          string   `json:"year,omitempty"`
+	Status        string   `json:"status"`
+	Tracks        int      `json:"tracks,omitempty"` // Zero when unknown
+	OwnedTracks   int      `json:"ownedTracks"`
+	MissingTracks []string `json:"missingTracks,omitempty"` // Track numbers, "disc-track" on multi-disc releases
+	Error         string   `json:"error,omitempty"`         // Why the track list could not be compared
+}
+
+// Report is the completeness of an artist's discography in the library
+type Report struct {
+	ArtistID string    `json:"artistId"`
+	Artist   string    `json:"artist"`
+	Owned    int       `json:"owned"`
+	Partial  int       `json:"partial"`
+	Missing  int       `json:"missing"`
+	Releases []Release `json:"releases"`
+}
+
+// BuildReport compares the discography of an artist on DAB with the files in the index. Releases are
+// matched by the DAB_ALBUMID tag, or by album title and artist for files without DAB tags, and their
+// tracks by DAB track ID, ISRC or disc and track number. Editions of the same release are collapsed
+// as with the DuplicatePolicy setting, so owning any edition counts for the release.
+func BuildReport(ctx context.Context, api ArtistAPI, artistID string, index *Index, cfg *config.Config, debug bool) (*Report, error) {
+	artist, err := api.GetArtist(ctx, artistID, cfg, debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist %s: %w", artistID, err)
+	}
+
+	policy := ""
+	if cfg != nil {
+		policy = cfg.DuplicatePolicy
+	}
+
+	report := &Report{ArtistID: artistID, Artist: artist.Name, Releases: []Release{}}
+	for _, group := range discography.GroupDuplicates(artist.Albums, policy) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		release := compareEditions(ctx, api, group, index, artist.Name)
+		switch release.Status {
+		case StatusOwned:
+			report.Owned++
+		case StatusPartial:
+			report.Partial++
+		default:
+			report.Missing++
+		}
+		report.Releases = append(report.Releases, release)
+	}
+
+	sort.SliceStable(report.Releases, func(i, j int) bool {
+		a, b := report.Releases[i], report.Releases[j]
+		if a.Type != b.Type {
+			return typeOrder(a.Type) < typeOrder(b.Type)
+		}
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+	return report, nil
+}
+
+// ReleaseType normalizes the type of an album to one of ReleaseTypes. Unknown types count as albums.
+func ReleaseType(album shared.Album) string {
+	switch strings.ToLower(album.Type) {
+	case "ep":
+		return "ep"
+	case "single":
+		return "single"
+	default:
+		return "album"
+	}
+}
+
+// compareEditions compares the editions of a release with the library. The edition the library holds
+// the most of is reported, or the preferred one when no edition has files.
+func compareEditions(ctx context.Context, api ArtistAPI, group discography.Group, index *Index, artistName string) Release {
+	release := compareRelease(ctx, api, group.Preferred, index.releaseFiles(group.Preferred, artistName))
+	for _, edition := range group.Duplicates {
+		if release.Status == StatusOwned {
+			break
+		}
+		candidate := compareRelease(ctx, api, edition, index.releaseFiles(edition, artistName))
+		if candidate.Status == StatusOwned || candidate.OwnedTracks > release.OwnedTracks {
+			release = candidate
+		}
+	}
+	return release
+}
+
+// compareRelease works out how much of a release the library holds
+func compareRelease(ctx context.Context, api ArtistAPI, album shared.Album, files []File) Release {
+	release := Release{
+		ID:     album.ID,
+		Title:  album.Title,
+		Type:   ReleaseType(album),
+		Year:   releaseYear(album),
+		Status: StatusMissing,
+		Tracks: album.TotalTracks,
+	}
+	if len(files) == 0 {
+		return release
+	}
+
+	// The discography usually lists releases without their tracks
+	tracks := album.Tracks
+	if len(tracks) == 0 {
+		full, err := api.GetAlbum(ctx, album.ID)
+		if err == nil {
+			tracks = full.Tracks
+		} else {
+			release.Error = err.Error()
+		}
+	}
+
+	if len(tracks) == 0 {
+		// Without a track list only the number of files can be compared
+		release.OwnedTracks = len(files)
+		release.Status = StatusOwned
+		if release.Tracks > len(files) {
+			release.Status = StatusPartial
+		}
+		return release
+	}
+
+	release.Tracks = len(tracks)
+	multiDisc := false
+	for _, track := range tracks {
+		multiDisc = multiDisc || track.DiscNumber > 1
+	}
+	for i, track := range tracks {
+		if hasTrack(files, track, i+1) {
+			release.OwnedTracks++
+			continue
+		}
+		release.MissingTracks = append(release.MissingTracks, trackLabel(track, i+1, multiDisc))
+	}
+
+	if len(release.MissingTracks) == 0 {
+		release.Status = StatusOwned
+	} else {
+		release.Status = StatusPartial
+	}
+	return release
+}
+
+// releaseFiles returns the files of a release: those tagged with its DAB album ID, and files without DAB
+// tags that carry its title and artist
+func (idx *Index) releaseFiles(album shared.Album, artistName string) []File {
+	idx.buildLookups()
+	files := append([]File(nil), idx.byAlbumID[album.ID]...)
+
+	artistName = strings.ToLower(artistName)
+	for _, file := range idx.byTitle[normalizeTitle(album.Title)] {
+		fileArtist := strings.ToLower(file.Track.AlbumArtist)
+		if fileArtist == "" {
+			fileArtist = strings.ToLower(file.Track.Artist)
+		}
+		if fileArtist != "" && strings.Contains(fileArtist, artistName) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// hasTrack reports whether one of the files is the given track of the release
+func hasTrack(files []File, track shared.Track, position int) bool {
+	trackID := shared.IdToString(track.ID)
+	trackNumber := track.TrackNumber
+	if trackNumber == 0 {
+		trackNumber = position
+	}
+	for _, file := range files {
+		owned := file.Track
+		if trackID != "" && shared.IdToString(owned.ID) == trackID {
+			return true
+		}
+		if track.ISRC != "" && strings.EqualFold(owned.ISRC, track.ISRC) {
+			return true
+		}
+		if owned.TrackNumber == trackNumber && max(owned.DiscNumber, 1) == max(track.DiscNumber, 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackLabel names a track by its number, prefixed with the disc on multi-disc releases
+func trackLabel(track shared.Track, position int, multiDisc bool) string {
+	number := track.TrackNumber
+	if number == 0 {
+		number = position
+	}
+	if multiDisc {
+		return fmt.Sprintf("%d-%d", max(track.DiscNumber, 1), number)
+	}
+	return strconv.Itoa(number)
+}
+
+// normalizeTitle lowercases a title and collapses its whitespace for comparison
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+func releaseYear(album shared.Album) string {
+	if album.Year != "" {
+		return album.Year
+	}
+	if len(album.ReleaseDate) >= 4 {
+		return album.ReleaseDate[:4]
+	}
+	return ""
+}
+
+func typeOrder(releaseType string) int {
+	for i, t := range ReleaseTypes {
+		if t == releaseType {
+			return i
+		}
+	}
+	return len(ReleaseTypes)
+}
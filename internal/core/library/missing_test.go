@@ -0,0 +1,203 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/discography"
+	"dab-downloader/internal/shared"
+)
+
+// fakeArtistAPI serves one artist and the track lists of its albums
+type fakeArtistAPI struct {
+	artist    shared.Artist
+	albums    map[string]shared.Album
+	albumGets int
+}
+
+func (api *fakeArtistAPI) GetArtist(ctx context.Context, artistID string, cfg *config.Config, debug bool) (*shared.Artist, error) {
+	if artistID != shared.IdToString(api.artist.ID) {
+		return nil, fmt.Errorf("artist %s not found", artistID)
+	}
+	artist := api.artist
+	return &artist, nil
+}
+
+func (api *fakeArtistAPI) GetAlbum(ctx context.Context, albumID string) (*shared.Album, error) {
+	api.albumGets++
+	album, exists := api.albums[albumID]
+	if !exists {
+		return nil, fmt.Errorf("album %s not found", albumID)
+	}
+	return &album, nil
+}
+
+func newTestArtistAPI() *fakeArtistAPI {
+	tracks := func(albumID string, count, discs int) []shared.Track {
+		var list []shared.Track
+		for disc := 1; disc <= discs; disc++ {
+			for number := 1; number <= count; number++ {
+				list = append(list, shared.Track{ID: fmt.Sprintf("%s-%d-%d", albumID, disc, number), TrackNumber: number, DiscNumber: disc})
+			}
+		}
+		return list
+	}
+
+	return &fakeArtistAPI{
+		artist: shared.Artist{ID: "ar1", Name: "Artist", Albums: []shared.Album{
+			{ID: "al1", Title: "Complete", Type: "album", ReleaseDate: "2012-01-01"},
+			{ID: "al2", Title: "Half", Type: "album", ReleaseDate: "2010-05-01"},
+			{ID: "al3", Title: "Gone", Type: "ep", Year: "2015"},
+			{ID: "al4", Title: "Untagged", Type: "single", Year: "2016"},
+		}},
+		albums: map[string]shared.Album{
+			"al1": {ID: "al1", Tracks: tracks("al1", 2, 1)},
+			"al2": {ID: "al2", Tracks: tracks("al2", 2, 2)},
+			"al4": {ID: "al4", Tracks: tracks("al4", 1, 1)},
+		},
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	api := newTestArtistAPI()
+	index := &Index{Files: []File{
+		{Path: "a/1.flac", Track: shared.Track{ID: "al1-1-1", AlbumID: "al1", TrackNumber: 1}},
+		{Path: "a/2.flac", Track: shared.Track{ID: "al1-1-2", AlbumID: "al1", TrackNumber: 2}},
+		{Path: "b/1.flac", Track: shared.Track{AlbumID: "al2", TrackNumber: 1, DiscNumber: 1}},
+		{Path: "b/2.flac", Track: shared.Track{AlbumID: "al2", TrackNumber: 1, DiscNumber: 2}},
+		{Path: "c/1.flac", Track: shared.Track{Album: "untagged ", AlbumArtist: "Artist", TrackNumber: 1}},
+		{Path: "d/1.flac", Track: shared.Track{Album: "Gone", Artist: "Someone Else", TrackNumber: 1}},
+	}}
+
+	report, err := BuildReport(context.Background(), api, "ar1", index, nil, false)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	if report.Artist != "Artist" || report.Owned != 2 || report.Partial != 1 || report.Missing != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+
+	var order []string
+	for _, release := range report.Releases {
+		order = append(order, release.ID+":"+release.Status)
+	}
+	if want := []string{"al2:partial", "al1:owned", "al3:missing", "al4:owned"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Got releases %v, want %v", order, want)
+	}
+
+	half := report.Releases[0]
+	if half.Tracks != 4 || half.OwnedTracks != 2 || !reflect.DeepEqual(half.MissingTracks, []string{"1-2", "2-2"}) {
+		t.Errorf("Unexpected partial release %+v", half)
+	}
+	if api.albumGets != 3 {
+		t.Errorf("Expected track lists only for releases with files, fetched %d", api.albumGets)
+	}
+}
+
+func TestBuildReportWithoutTrackList(t *testing.T) {
+	api := newTestArtistAPI()
+	api.artist.Albums = []shared.Album{{ID: "al9", Title: "Unknown", TotalTracks: 3}}
+	index := &Index{Files: []File{{Path: "x/1.flac", Track: shared.Track{AlbumID: "al9", TrackNumber: 1}}}}
+
+	report, err := BuildReport(context.Background(), api, "ar1", index, nil, false)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	release := report.Releases[0]
+	if release.Status != StatusPartial || release.OwnedTracks != 1 || release.Error == "" {
+		t.Errorf("Unexpected release %+v", release)
+	}
+}
+
+func TestScanAndArtistFolders(t *testing.T) {
+	root := t.TempDir()
+	writeTaggedFLAC(t, filepath.Join(root, "Artist", "Album", "01.flac"), "ALBUM=Album", "TRACKNUMBER=1/10", "DAB_ALBUMID=al1", "DAB_ARTISTID=ar1")
+	writeTaggedFLAC(t, filepath.Join(root, "Artist", "Album", "02.flac"), "ALBUM=Album", "TRACKNUMBER=2", "DAB_ALBUMID=al1", "DAB_ARTISTID=ar1")
+	writeTaggedFLAC(t, filepath.Join(root, "Artist", "Album", "03.flac"), "ALBUM=Album", "TRACKNUMBER=3", "DAB_ARTISTID=ar2")
+	writeTaggedFLAC(t, filepath.Join(root, "Other", "Song.flac"), "ALBUM=Song")
+	if err := os.WriteFile(filepath.Join(root, "Other", "broken.flac"), []byte("not flac"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var failed []string
+	index, err := Scan(root, func(path string, err error) { failed = append(failed, filepath.Base(path)) })
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(index.Files) != 4 || !reflect.DeepEqual(failed, []string{"broken.flac"}) {
+		t.Errorf("Got %d files, failed %v", len(index.Files), failed)
+	}
+	if index.Files[0].Track.TrackNumber != 1 || index.Files[0].Track.AlbumID != "al1" {
+		t.Errorf("Tags not read: %+v", index.Files[0].Track)
+	}
+
+	folders := index.ArtistFolders()
+	want := []ArtistFolder{{Name: "Artist", ArtistID: "ar1"}, {Name: "Other"}}
+	if !reflect.DeepEqual(folders, want) {
+		t.Errorf("Got folders %+v, want %+v", folders, want)
+	}
+}
+
+// writeTaggedFLAC writes a minimal FLAC file with the given Vorbis comments
+func writeTaggedFLAC(t *testing.T, path string, comments ...string) {
+	t.Helper()
+
+	comment := flacvorbis.New()
+	comment.Comments = append(comment.Comments, comments...)
+	block := comment.Marshal()
+	f := &flac.File{
+		Meta:   []*flac.MetaDataBlock{{Type: flac.StreamInfo, Data: make([]byte, 34)}, &block},
+		Frames: []byte{0xFF, 0xF8, 0x69, 0x08, 0x00},
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Failed to write test FLAC: %v", err)
+	}
+}
+
+func TestBuildReportCollapsesEditions(t *testing.T) {
+	api := newTestArtistAPI()
+	api.artist.Albums = []shared.Album{
+		{ID: "explicit", Title: "Album", ReleaseDate: "2018-01-01", ParentalWarning: true, TotalTracks: 2},
+		{ID: "clean", Title: "Album (Clean)", ReleaseDate: "2018-01-01", TotalTracks: 2},
+		{ID: "deluxe", Title: "Album (Deluxe Edition)", ReleaseDate: "2019-01-01", TotalTracks: 4},
+		{ID: "other", Title: "Other", ReleaseDate: "2020-01-01", TotalTracks: 2},
+	}
+	api.albums["clean"] = shared.Album{ID: "clean", Tracks: []shared.Track{{ID: "c1", TrackNumber: 1}, {ID: "c2", TrackNumber: 2}}}
+	index := &Index{Files: []File{
+		{Path: "a/1.flac", Track: shared.Track{ID: "c1", AlbumID: "clean", TrackNumber: 1}},
+		{Path: "a/2.flac", Track: shared.Track{ID: "c2", AlbumID: "clean", TrackNumber: 2}},
+	}}
+
+	report, err := BuildReport(context.Background(), api, "ar1", index, &config.Config{}, false)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+
+	var releases []string
+	for _, release := range report.Releases {
+		releases = append(releases, release.ID+":"+release.Status)
+	}
+	if want := []string{"clean:owned", "other:missing"}; !reflect.DeepEqual(releases, want) {
+		t.Errorf("Got releases %v, want %v", releases, want)
+	}
+
+	report, err = BuildReport(context.Background(), api, "ar1", index, &config.Config{DuplicatePolicy: discography.PolicyKeepAll}, false)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	if len(report.Releases) != 4 || report.Missing != 3 {
+		t.Errorf("Expected every edition with keep-all, got %+v", report)
+	}
+}
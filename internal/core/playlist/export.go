@@ -0,0 +1,102 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportEntry is one track of a playlist written to disk
+type ExportEntry struct {
+	Title    string
+	Artist   string
+	Duration int    // Seconds, 0 when unknown
+	Path     string // Final file path, empty when the track was not downloaded
+}
+
+// WriteM3U8 writes an extended M3U playlist. Paths are written relative to baseDir, or as
+// absolute paths when baseDir is empty. Tracks without a path are kept as comments so the
+// playlist still shows what is missing.
+func WriteM3U8(w io.Writer, name string, entries []ExportEntry, baseDir string) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "#EXTM3U")
+	if name != "" {
+		fmt.Fprintf(writer, "#PLAYLIST:%s\n", oneLine(name))
+	}
+
+	for _, entry := range entries {
+		display := oneLine(entry.Title)
+		if entry.Artist != "" {
+			display = oneLine(entry.Artist) + " - " + display
+		}
+
+		if entry.Path == "" {
+			fmt.Fprintf(writer, "# MISSING: %s\n", display)
+			continue
+		}
+
+		location, err := exportLocation(entry.Path, baseDir)
+		if err != nil {
+			return err
+		}
+		duration := entry.Duration
+		if duration <= 0 {
+			duration = -1
+		}
+		fmt.Fprintf(writer, "#EXTINF:%d,%s\n%s\n", duration, display, location)
+	}
+	return writer.Flush()
+}
+
+// SaveM3U8 writes the playlist to path, creating its folder. With absolute false the track
+// paths are relative to the playlist's folder.
+func SaveM3U8(path, name string, entries []ExportEntry, absolute bool) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create playlist folder: %w", err)
+	}
+
+	baseDir := filepath.Dir(path)
+	if absolute {
+		baseDir = ""
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist: %w", err)
+	}
+	if err := WriteM3U8(file, name, entries, baseDir); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write playlist: %w", err)
+	}
+	return file.Close()
+}
+
+// exportLocation returns the path as written to the playlist. Relative paths use forward
+// slashes so the playlist works on every platform.
+func exportLocation(path, baseDir string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if baseDir == "" {
+		return path, nil
+	}
+
+	relative, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return path, nil // Different volume, fall back to the absolute path
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+// oneLine replaces line breaks, which would break the playlist format
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
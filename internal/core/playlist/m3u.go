@@ -0,0 +1,79 @@
+package playlist
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseM3U reads an M3U or M3U8 playlist. #EXTINF lines give the duration and "Artist - Title";
+// #EXTALB, #EXTART and #PLAYLIST are used when present. Plain entries without #EXTINF are named
+// after their file.
+func ParseM3U(r io.Reader) (*Playlist, error) {
+	playlist := &Playlist{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var pending *Entry
+	var album, albumArtist string
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "" || line == "#EXTM3U":
+			continue
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			playlist.Name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#EXTALB:"):
+			album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+		case strings.HasPrefix(line, "#EXTART:"):
+			albumArtist = strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			entry := parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+			pending = &entry
+		case strings.HasPrefix(line, "#"):
+			continue // Other directives and comments
+		default:
+			var entry Entry
+			if pending != nil {
+				entry = *pending
+				entry.Location = line
+			} else {
+				entry = entryFromFileName(line)
+			}
+			if entry.Title == "" {
+				fromName := entryFromFileName(line)
+				entry.Title, entry.Artist = fromName.Title, fromName.Artist
+			}
+			if entry.Album == "" {
+				entry.Album = album
+			}
+			if entry.Artist == "" {
+				entry.Artist = albumArtist
+			}
+			playlist.Entries = append(playlist.Entries, entry)
+			pending, album, albumArtist = nil, "", ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// parseExtInf parses the part after "#EXTINF:", e.g. `215 tvg-id="x",Artist - Title`
+func parseExtInf(value string) Entry {
+	info, display, _ := strings.Cut(value, ",")
+
+	var entry Entry
+	duration := info
+	if index := strings.IndexByte(info, ' '); index >= 0 {
+		duration = info[:index]
+	}
+	if seconds, err := strconv.ParseFloat(strings.TrimSpace(duration), 64); err == nil && seconds > 0 {
+		entry.Duration = int(seconds + 0.5)
+	}
+
+	entry.Artist, entry.Title = splitArtistTitle(display)
+	return entry
+}
@@ -0,0 +1,136 @@
+package playlist
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dab-downloader/internal/shared"
+)
+
+// Entry is one track of an imported playlist
+type Entry struct {
+	Title    string
+	Artist   string
+	Album    string
+	ISRC     string
+	Duration int    // Seconds, 0 when unknown
+	Location string // File path or URL from the playlist, if any
+}
+
+// Playlist is a playlist read from a local file
+type Playlist struct {
+	Name    string
+	Entries []Entry
+}
+
+// Track converts the entry to the shared track type used for matching
+func (e Entry) Track() shared.Track {
+	return shared.Track{
+		Title:    e.Title,
+		Artist:   e.Artist,
+		Album:    e.Album,
+		ISRC:     e.ISRC,
+		Duration: e.Duration,
+	}
+}
+
+// Tracks converts every entry to a shared track
+func (p *Playlist) Tracks() []shared.Track {
+	tracks := make([]shared.Track, len(p.Entries))
+	for i, entry := range p.Entries {
+		tracks[i] = entry.Track()
+	}
+	return tracks
+}
+
+// ParseFile reads a playlist file, choosing the format from its extension: .m3u and .m3u8,
+// .xspf, .csv and .json are supported. Entries without a title are dropped. The playlist is
+// named after the file when the file does not name it.
+func ParseFile(path string) (*Playlist, error) {
+	var parse func(io.Reader) (*Playlist, error)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".m3u", ".m3u8":
+		parse = ParseM3U
+	case ".xspf":
+		parse = ParseXSPF
+	case ".csv":
+		parse = ParseCSV
+	case ".json":
+		parse = ParseJSON
+	default:
+		return nil, fmt.Errorf("unsupported playlist format %q (supported: .m3u, .m3u8, .xspf, .csv, .json)", ext)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open playlist: %w", err)
+	}
+	defer file.Close()
+
+	playlist, err := parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	if playlist.Name == "" {
+		playlist.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	entries := playlist.Entries[:0]
+	for _, entry := range playlist.Entries {
+		if entry.Title != "" {
+			entries = append(entries, entry)
+		}
+	}
+	playlist.Entries = entries
+	return playlist, nil
+}
+
+// entryFromFileName guesses the artist and title from a file name such as
+// "01 - Artist - Title.flac" or "Artist - Title.mp3"
+func entryFromFileName(location string) Entry {
+	name := location
+	if index := strings.LastIndexAny(name, `/\`); index >= 0 {
+		name = name[index+1:]
+	}
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	parts := strings.Split(name, " - ")
+	if len(parts) > 1 && isTrackNumber(parts[0]) {
+		parts = parts[1:]
+	}
+
+	entry := Entry{Location: location}
+	if len(parts) >= 2 {
+		entry.Artist = strings.TrimSpace(parts[0])
+		entry.Title = strings.TrimSpace(strings.Join(parts[1:], " - "))
+	} else {
+		entry.Title = strings.TrimSpace(name)
+	}
+	return entry
+}
+
+// splitArtistTitle splits "Artist - Title" display strings
+func splitArtistTitle(display string) (string, string) {
+	if artist, title, ok := strings.Cut(display, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return "", strings.TrimSpace(display)
+}
+
+// isTrackNumber reports whether a string is only digits
+func isTrackNumber(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
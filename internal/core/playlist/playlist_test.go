@@ -0,0 +1,210 @@
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseM3U(t *testing.T) {
+	data := "\ufeff#EXTM3U\n" +
+		"#PLAYLIST:Road Trip\n" +
+		"#EXTINF:215,Calvin Harris, Dua Lipa - One Kiss\n" +
+		"Music/Calvin Harris/One Kiss.flac\n" +
+		"#EXTINF:-1 tvg-id=\"x\",Avicii - Levels\n" +
+		"#EXTALB:True\n" +
+		"http://example.com/levels.mp3\n" +
+		"# a comment\n" +
+		"/music/Queen/01 - Queen - Don't Stop Me Now.mp3\n"
+
+	playlist, err := ParseM3U(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseM3U failed: %v", err)
+	}
+	if playlist.Name != "Road Trip" || len(playlist.Entries) != 3 {
+		t.Fatalf("Expected 3 entries in Road Trip, got %q with %+v", playlist.Name, playlist.Entries)
+	}
+
+	expected := []Entry{
+		{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", Duration: 215, Location: "Music/Calvin Harris/One Kiss.flac"},
+		{Title: "Levels", Artist: "Avicii", Album: "True", Location: "http://example.com/levels.mp3"},
+		{Title: "Don't Stop Me Now", Artist: "Queen", Location: "/music/Queen/01 - Queen - Don't Stop Me Now.mp3"},
+	}
+	for i, entry := range expected {
+		if playlist.Entries[i] != entry {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, entry, playlist.Entries[i])
+		}
+	}
+}
+
+func TestParseXSPF(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Favourites</title>
+  <trackList>
+    <track>
+      <location>file:///music/heroes.flac</location>
+      <identifier>isrc:gbaye7700012</identifier>
+      <title>Heroes</title>
+      <creator>David Bowie</creator>
+      <album>"Heroes"</album>
+      <duration>371400</duration>
+    </track>
+    <track>
+      <location>file:///music/Blondie%20-%20Atomic.mp3</location>
+    </track>
+  </trackList>
+</playlist>`
+
+	playlist, err := ParseXSPF(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseXSPF failed: %v", err)
+	}
+	if playlist.Name != "Favourites" || len(playlist.Entries) != 2 {
+		t.Fatalf("Unexpected playlist: %+v", playlist)
+	}
+	first := playlist.Entries[0]
+	if first.Title != "Heroes" || first.Artist != "David Bowie" || first.Album != "\"Heroes\"" || first.ISRC != "GBAYE7700012" || first.Duration != 371 {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	if second := playlist.Entries[1]; second.Title != "Atomic" || second.Artist != "Blondie" {
+		t.Errorf("Expected the entry to be named after its location, got %+v", second)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	// Exportify style export
+	exportify := "\"Track URI\",\"Track Name\",\"Artist Name(s)\",\"Album Name\",\"Duration (ms)\",\"ISRC\"\n" +
+		"\"spotify:track:1\",\"One Kiss (with Dua Lipa)\",\"Calvin Harris,Dua Lipa\",\"One Kiss\",\"214846\",\"gbarl1800368\"\n" +
+		"\"spotify:track:2\",\"\",\"Nobody\",\"\",\"\",\"\"\n"
+	playlist, err := ParseCSV(strings.NewReader(exportify))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	entry := playlist.Entries[0]
+	if entry.Title != "One Kiss (with Dua Lipa)" || entry.Artist != "Calvin Harris,Dua Lipa" || entry.Album != "One Kiss" || entry.Duration != 215 || entry.ISRC != "GBARL1800368" {
+		t.Errorf("Unexpected Exportify entry: %+v", entry)
+	}
+
+	// TuneMyMusic style export with semicolons and a playlist column
+	tuneMyMusic := "Track name;Artist name;Album;Playlist name;Type;ISRC\n" +
+		"Levels;Avicii;Levels;Club Night;Playlist;\n"
+	playlist, err = ParseCSV(strings.NewReader(tuneMyMusic))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if playlist.Name != "Club Night" || len(playlist.Entries) != 1 || playlist.Entries[0].Artist != "Avicii" {
+		t.Errorf("Unexpected TuneMyMusic playlist: %+v", playlist)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("foo,bar\n1,2\n")); err == nil {
+		t.Error("Expected an error for a CSV without a title column")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	// Spotify API style
+	spotifyStyle := `{"name": "Mix", "tracks": {"items": [
+		{"track": {"name": "One Kiss", "artists": [{"name": "Calvin Harris"}, {"name": "Dua Lipa"}],
+		 "album": {"name": "One Kiss"}, "duration_ms": 214846, "external_ids": {"isrc": "GBARL1800368"}}}
+	]}}`
+	playlist, err := ParseJSON(strings.NewReader(spotifyStyle))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	expected := Entry{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", Album: "One Kiss", ISRC: "GBARL1800368", Duration: 215}
+	if playlist.Name != "Mix" || len(playlist.Entries) != 1 || playlist.Entries[0] != expected {
+		t.Errorf("Unexpected playlist: %+v", playlist)
+	}
+
+	// Flat array
+	flat := `[{"title": "Levels", "artist": "Avicii", "duration": "3:19"}]`
+	playlist, err = ParseJSON(strings.NewReader(flat))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if len(playlist.Entries) != 1 || playlist.Entries[0].Duration != 199 || playlist.Entries[0].Artist != "Avicii" {
+		t.Errorf("Unexpected flat playlist: %+v", playlist)
+	}
+
+	if _, err := ParseJSON(strings.NewReader(`{"foo": 1}`)); err == nil {
+		t.Error("Expected an error without a track list")
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Summer Hits.m3u8")
+	if err := os.WriteFile(path, []byte("#EXTM3U\n#EXTINF:199,Avicii - Levels\nlevels.mp3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	playlist, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if playlist.Name != "Summer Hits" || len(playlist.Tracks()) != 1 || playlist.Tracks()[0].Title != "Levels" {
+		t.Errorf("Unexpected playlist: %+v", playlist)
+	}
+
+	if _, err := ParseFile(filepath.Join(dir, "list.txt")); err == nil {
+		t.Error("Expected an error for an unknown extension")
+	}
+}
+
+func TestWriteM3U8(t *testing.T) {
+	dir := t.TempDir()
+	entries := []ExportEntry{
+		{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", Duration: 215, Path: filepath.Join(dir, "Calvin Harris", "One Kiss", "01 - One Kiss.mp3")},
+		{Title: "Levels", Artist: "Avicii"},
+		{Title: "Untitled\nDemo", Path: filepath.Join(dir, "Demo.flac")},
+	}
+
+	var relative strings.Builder
+	if err := WriteM3U8(&relative, "Road Trip", entries, dir); err != nil {
+		t.Fatalf("WriteM3U8 failed: %v", err)
+	}
+	expected := "#EXTM3U\n" +
+		"#PLAYLIST:Road Trip\n" +
+		"#EXTINF:215,Calvin Harris, Dua Lipa - One Kiss\n" +
+		"Calvin Harris/One Kiss/01 - One Kiss.mp3\n" +
+		"# MISSING: Avicii - Levels\n" +
+		"#EXTINF:-1,Untitled Demo\n" +
+		"Demo.flac\n"
+	if relative.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, relative.String())
+	}
+
+	var absolute strings.Builder
+	if err := WriteM3U8(&absolute, "", entries[:1], ""); err != nil {
+		t.Fatalf("WriteM3U8 failed: %v", err)
+	}
+	if !strings.Contains(absolute.String(), "\n"+entries[0].Path+"\n") || strings.Contains(absolute.String(), "#PLAYLIST") {
+		t.Errorf("Expected an absolute path and no playlist name, got:\n%s", absolute.String())
+	}
+}
+
+func TestSaveM3U8RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Playlists", "Road Trip.m3u8")
+	entries := []ExportEntry{
+		{Title: "One Kiss", Artist: "Calvin Harris", Duration: 215, Path: filepath.Join(dir, "Calvin Harris", "One Kiss.opus")},
+		{Title: "Levels", Artist: "Avicii"},
+	}
+	if err := SaveM3U8(path, "Road Trip", entries, false); err != nil {
+		t.Fatalf("SaveM3U8 failed: %v", err)
+	}
+
+	playlist, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if playlist.Name != "Road Trip" || len(playlist.Entries) != 1 {
+		t.Fatalf("Expected the downloaded track only, got %q with %+v", playlist.Name, playlist.Entries)
+	}
+	expected := Entry{Title: "One Kiss", Artist: "Calvin Harris", Duration: 215, Location: "../Calvin Harris/One Kiss.opus"}
+	if playlist.Entries[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, playlist.Entries[0])
+	}
+}
@@ -0,0 +1,239 @@
+package playlist
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Column names used by common migration tools (Exportify, TuneMyMusic, Soundiiz and others),
+// compared after lowercasing and removing everything but letters and digits
+var (
+	titleColumns    = []string{"title", "trackname", "track", "name", "song", "songname", "tracktitle"}
+	artistColumns   = []string{"artist", "artistname", "artistnames", "artists", "creator", "performer"}
+	albumColumns    = []string{"album", "albumname", "albumtitle", "release"}
+	isrcColumns     = []string{"isrc"}
+	durationColumns = []string{"durationms", "duration", "durationseconds", "length", "time"}
+	playlistColumns = []string{"playlist", "playlistname"}
+)
+
+// ParseCSV reads a CSV export with a header row. Commas and semicolons are both accepted as
+// separators. Columns are found by name, so their order and any extra columns do not matter.
+func ParseCSV(r io.Reader) (*Playlist, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	headerLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(headerLine, []byte(";")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = columnKey(name)
+	}
+	if findColumn(columns, titleColumns) < 0 {
+		return nil, fmt.Errorf("no title column found in header %v", header)
+	}
+
+	playlist := &Playlist{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]string, len(record))
+		for i, value := range record {
+			if i < len(columns) {
+				fields[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		if playlist.Name == "" {
+			playlist.Name = lookupField(fields, playlistColumns)
+		}
+		playlist.Entries = append(playlist.Entries, entryFromFields(fields))
+	}
+	return playlist, nil
+}
+
+// ParseJSON reads a JSON export: either an array of tracks or an object holding the tracks in
+// "tracks", "items" or "songs". Tracks may be wrapped in a "track" object as in the Spotify API,
+// and artists and albums may be strings, objects with a "name" or arrays of either.
+func ParseJSON(r io.Reader) (*Playlist, error) {
+	var document interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	playlist := &Playlist{}
+	var items []interface{}
+	switch value := document.(type) {
+	case []interface{}:
+		items = value
+	case map[string]interface{}:
+		for key, field := range value {
+			switch columnKey(key) {
+			case "name", "title", "playlist", "playlistname":
+				if name, ok := field.(string); ok && playlist.Name == "" {
+					playlist.Name = strings.TrimSpace(name)
+				}
+			case "tracks", "items", "songs":
+				if list, ok := field.([]interface{}); ok {
+					items = list
+				} else if object, ok := field.(map[string]interface{}); ok {
+					// Spotify API style paging object
+					items, _ = object["items"].([]interface{})
+				}
+			}
+		}
+		if items == nil {
+			return nil, fmt.Errorf("no track list found")
+		}
+	default:
+		return nil, fmt.Errorf("expected a JSON array or object")
+	}
+
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if track, ok := object["track"].(map[string]interface{}); ok {
+			object = track
+		}
+
+		fields := make(map[string]string, len(object))
+		for key, value := range object {
+			fields[columnKey(key)] = jsonText(value)
+		}
+		// Spotify API style ISRC
+		if ids, ok := object["external_ids"].(map[string]interface{}); ok && fields["isrc"] == "" {
+			fields["isrc"] = jsonText(ids["isrc"])
+		}
+		playlist.Entries = append(playlist.Entries, entryFromFields(fields))
+	}
+	return playlist, nil
+}
+
+// entryFromFields builds an entry from fields keyed by columnKey
+func entryFromFields(fields map[string]string) Entry {
+	entry := Entry{
+		Title:  lookupField(fields, titleColumns),
+		Artist: lookupField(fields, artistColumns),
+		Album:  lookupField(fields, albumColumns),
+		ISRC:   strings.ToUpper(lookupField(fields, isrcColumns)),
+	}
+	for _, column := range durationColumns {
+		if value := fields[column]; value != "" {
+			entry.Duration = parseDuration(value, strings.HasSuffix(column, "ms"))
+			break
+		}
+	}
+	return entry
+}
+
+// lookupField returns the first non-empty field among the given columns
+func lookupField(fields map[string]string, columns []string) string {
+	for _, column := range columns {
+		if value := fields[column]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// findColumn returns the index of the first column with one of the names, or -1
+func findColumn(columns []string, names []string) int {
+	for _, name := range names {
+		for i, column := range columns {
+			if column == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// columnKey normalizes a column name, e.g. "Artist Name(s)" becomes "artistnames"
+func columnKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// parseDuration parses "215", "215.4", "3:35" or "1:02:03" as seconds, or milliseconds when
+// the column says so. Plain numbers too large to be seconds are taken as milliseconds.
+func parseDuration(value string, milliseconds bool) int {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ":") {
+		seconds := 0
+		for _, part := range strings.Split(value, ":") {
+			number, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return 0
+			}
+			seconds = seconds*60 + number
+		}
+		return seconds
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 {
+		return 0
+	}
+	if milliseconds || number > 36000 {
+		number /= 1000
+	}
+	return int(math.Round(number))
+}
+
+// jsonText flattens a JSON value to text: names are taken from objects and arrays are joined
+// with ", ", which turns artist lists into a credit like "Calvin Harris, Dua Lipa"
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case map[string]interface{}:
+		if name, ok := v["name"]; ok {
+			return jsonText(name)
+		}
+		return jsonText(v["title"])
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if text := jsonText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return ""
+	}
+}
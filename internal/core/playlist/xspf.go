@@ -0,0 +1,62 @@
+package playlist
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// xspfDocument is the part of an XSPF playlist used for importing
+type xspfDocument struct {
+	Title  string `xml:"title"`
+	Tracks []struct {
+		Location   []string `xml:"location"`
+		Identifier []string `xml:"identifier"`
+		Title      string   `xml:"title"`
+		Creator    string   `xml:"creator"`
+		Album      string   `xml:"album"`
+		Duration   int      `xml:"duration"` // Milliseconds
+	} `xml:"trackList>track"`
+}
+
+// ParseXSPF reads an XSPF playlist. ISRCs are taken from identifiers such as "isrc:USUM71703861".
+func ParseXSPF(r io.Reader) (*Playlist, error) {
+	var document xspfDocument
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	playlist := &Playlist{Name: strings.TrimSpace(document.Title)}
+	for _, track := range document.Tracks {
+		entry := Entry{
+			Title:    strings.TrimSpace(track.Title),
+			Artist:   strings.TrimSpace(track.Creator),
+			Album:    strings.TrimSpace(track.Album),
+			Duration: (track.Duration + 500) / 1000,
+		}
+		if len(track.Location) > 0 {
+			entry.Location = strings.TrimSpace(track.Location[0])
+		}
+		for _, identifier := range track.Identifier {
+			identifier = strings.TrimSpace(identifier)
+			if len(identifier) > 5 && strings.EqualFold(identifier[:5], "isrc:") {
+				entry.ISRC = strings.ToUpper(identifier[5:])
+			}
+		}
+
+		if entry.Title == "" && entry.Location != "" {
+			location := entry.Location
+			if unescaped, err := url.PathUnescape(location); err == nil {
+				location = unescaped
+			}
+			fromName := entryFromFileName(location)
+			entry.Title = fromName.Title
+			if entry.Artist == "" {
+				entry.Artist = fromName.Artist
+			}
+		}
+		playlist.Entries = append(playlist.Entries, entry)
+	}
+	return playlist, nil
+}
@@ -0,0 +1,107 @@
+package playlistsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dab-downloader/internal/shared"
+)
+
+// DefaultMappingFile is where the playlist mappings are kept when none is configured
+const DefaultMappingFile = "config/playlist-sync.json"
+
+// Mapping links a Spotify playlist to the Navidrome playlist it is synced into
+type Mapping struct {
+	SpotifyPlaylistID   string    `json:"spotify_playlist_id"`
+	SpotifyURL          string    `json:"spotify_url"`
+	Name                string    `json:"name"`
+	NavidromePlaylistID string    `json:"navidrome_playlist_id"`
+	SyncedSongIDs       []string  `json:"synced_song_ids"` // Songs added by the last sync, in Spotify order
+	LastSynced          time.Time `json:"last_synced"`
+}
+
+// MappingStore persists the playlist mappings as JSON
+type MappingStore struct {
+	path     string
+	mu       sync.Mutex
+	mappings []Mapping
+}
+
+// LoadMappingStore reads the mappings from a file. A missing file is an empty store.
+func LoadMappingStore(path string) (*MappingStore, error) {
+	if path == "" {
+		path = DefaultMappingFile
+	}
+	store := &MappingStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playlist mappings: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.mappings); err != nil {
+		return nil, fmt.Errorf("failed to parse playlist mappings: %w", err)
+	}
+	return store, nil
+}
+
+// Get returns the mapping of a Spotify playlist
+func (s *MappingStore) Get(spotifyPlaylistID string) (Mapping, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, mapping := range s.mappings {
+		if mapping.SpotifyPlaylistID == spotifyPlaylistID {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// All returns a copy of every mapping
+func (s *MappingStore) All() []Mapping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Mapping(nil), s.mappings...)
+}
+
+// Put adds or replaces the mapping of a Spotify playlist and saves the store
+func (s *MappingStore) Put(mapping Mapping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replaced := false
+	for i := range s.mappings {
+		if s.mappings[i].SpotifyPlaylistID == mapping.SpotifyPlaylistID {
+			s.mappings[i] = mapping
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.mappings = append(s.mappings, mapping)
+	}
+	return s.save()
+}
+
+// save writes the mappings to disk, the caller holds the lock
+func (s *MappingStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create playlist mapping directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.mappings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode playlist mappings: %w", err)
+	}
+	if err := shared.WriteFileAtomic(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write playlist mappings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package playlistsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"dab-downloader/internal/api/navidrome"
+	"dab-downloader/internal/api/spotify"
+	"dab-downloader/internal/shared"
+)
+
+// ============================================================================
+// 1. Types
+// ============================================================================
+
+// Source provides the tracks of a Spotify playlist
+type Source interface {
+	GetPlaylistTracks(playlistURL string) ([]shared.SpotifyTrack, string, error)
+}
+
+// Library is the Navidrome side of a sync, implemented by *navidrome.NavidromeClient
+type Library interface {
+	CreatePlaylist(name string) (string, error)
+	GetPlaylistTracks(playlistID string) ([]navidrome.Song, error)
+	FindSong(track shared.Track) (*navidrome.Song, error)
+	AddTracksToPlaylist(playlistID string, songIDs []string) error
+	RemoveTracksFromPlaylist(playlistID string, indexes []int) error
+	SetPlaylistTracks(playlistID string, songIDs []string) error
+	RescanLibrary(ctx context.Context, pollInterval time.Duration) (*navidrome.ScanStatus, error)
+}
+
+// MissingTrackHandler downloads tracks that are not in the library yet and returns how many it downloaded
+type MissingTrackHandler func(ctx context.Context, tracks []shared.Track) (int, error)
+
+// Result describes what a sync changed
+type Result struct {
+	PlaylistName        string
+	NavidromePlaylistID string
+	Created             bool
+	Added               int
+	Removed             int
+	Reordered           bool
+	Downloaded          int
+	Unmatched           []shared.Track
+}
+
+// Syncer keeps Navidrome playlists in line with Spotify playlists
+type Syncer struct {
+	source           Source
+	library          Library
+	store            *MappingStore
+	downloadMissing  MissingTrackHandler
+	scanPollInterval time.Duration
+}
+
+// ============================================================================
+// 2. Constructor
+// ============================================================================
+
+// NewSyncer creates a syncer. downloadMissing may be nil to only use songs already in the library.
+func NewSyncer(source Source, library Library, store *MappingStore, downloadMissing MissingTrackHandler) *Syncer {
+	return &Syncer{
+		source:           source,
+		library:          library,
+		store:            store,
+		downloadMissing:  downloadMissing,
+		scanPollInterval: navidrome.DefaultScanPollInterval,
+	}
+}
+
+// ============================================================================
+// 3. Public API Methods
+// ============================================================================
+
+// Sync brings the Navidrome playlist mapped to a Spotify playlist up to date, creating it on the
+// first run. New tracks are added (downloaded through DAB first when missing from the library),
+// tracks removed on Spotify are removed, and the Spotify order is kept. Songs added to the
+// Navidrome playlist by hand are left at the end of the playlist.
+func (s *Syncer) Sync(ctx context.Context, playlistURL string) (*Result, error) {
+	spotifyID, err := SpotifyPlaylistID(playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	spotifyTracks, name, err := s.source.GetPlaylistTracks(playlistURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Spotify playlist: %w", err)
+	}
+	result := &Result{PlaylistName: name}
+
+	mapping, exists := s.store.Get(spotifyID)
+	mapping.SpotifyPlaylistID = spotifyID
+	mapping.SpotifyURL = playlistURL
+	mapping.Name = name
+
+	var current []string
+	if exists {
+		current, err = s.playlistSongIDs(mapping.NavidromePlaylistID)
+		var apiErr *navidrome.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == navidrome.ErrorCodeNotFound {
+			// The playlist was deleted in Navidrome, start over
+			exists = false
+			mapping.SyncedSongIDs = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if !exists {
+		mapping.NavidromePlaylistID, err = s.library.CreatePlaylist(name)
+		if err != nil {
+			return nil, err
+		}
+		current = nil
+		result.Created = true
+
+		// Remember the new playlist right away so that a failed sync does not create another one
+		mapping.SyncedSongIDs = nil
+		if err := s.store.Put(mapping); err != nil {
+			return nil, err
+		}
+	}
+	result.NavidromePlaylistID = mapping.NavidromePlaylistID
+
+	desired, err := s.resolveSongs(ctx, toTracks(spotifyTracks), result)
+	if err != nil {
+		return nil, err
+	}
+
+	target := withManualSongs(desired, current, mapping.SyncedSongIDs)
+	if err := s.apply(mapping.NavidromePlaylistID, current, target, result); err != nil {
+		return nil, err
+	}
+
+	mapping.SyncedSongIDs = desired
+	mapping.LastSynced = time.Now().UTC()
+	if err := s.store.Put(mapping); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ============================================================================
+// 4. Private Methods
+// ============================================================================
+
+// resolveSongs maps tracks to library songs in order, downloading the missing ones and rescanning once
+func (s *Syncer) resolveSongs(ctx context.Context, tracks []shared.Track, result *Result) ([]string, error) {
+	songIDs := make([]string, len(tracks))
+	var missing []int
+	for i, track := range tracks {
+		song, err := s.library.FindSong(track)
+		if err != nil {
+			return nil, err
+		}
+		if song == nil {
+			missing = append(missing, i)
+			continue
+		}
+		songIDs[i] = song.ID
+	}
+
+	if len(missing) > 0 && s.downloadMissing != nil {
+		missingTracks := make([]shared.Track, len(missing))
+		for i, index := range missing {
+			missingTracks[i] = tracks[index]
+		}
+
+		downloaded, err := s.downloadMissing(ctx, missingTracks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download missing tracks: %w", err)
+		}
+		result.Downloaded = downloaded
+
+		if downloaded > 0 {
+			if _, err := s.library.RescanLibrary(ctx, s.scanPollInterval); err != nil {
+				return nil, err
+			}
+
+			stillMissing := missing[:0]
+			for _, index := range missing {
+				song, err := s.library.FindSong(tracks[index])
+				if err != nil {
+					return nil, err
+				}
+				if song == nil {
+					stillMissing = append(stillMissing, index)
+					continue
+				}
+				songIDs[index] = song.ID
+			}
+			missing = stillMissing
+		}
+	}
+
+	for _, index := range missing {
+		result.Unmatched = append(result.Unmatched, tracks[index])
+	}
+
+	resolved := songIDs[:0]
+	for _, songID := range songIDs {
+		if songID != "" {
+			resolved = append(resolved, songID)
+		}
+	}
+	return resolved, nil
+}
+
+// apply changes the playlist from current to target with the smallest kind of edit:
+// appending, removing by position, or replacing the whole list when the order changed
+func (s *Syncer) apply(playlistID string, current, target []string, result *Result) error {
+	inCurrent := countSongs(current)
+	for _, songID := range target {
+		if inCurrent[songID] > 0 {
+			inCurrent[songID]--
+		} else {
+			result.Added++
+		}
+	}
+	inTarget := countSongs(target)
+	for _, songID := range current {
+		if inTarget[songID] > 0 {
+			inTarget[songID]--
+		} else {
+			result.Removed++
+		}
+	}
+
+	switch {
+	case equalSongs(current, target):
+		return nil
+	case len(target) > len(current) && equalSongs(current, target[:len(current)]):
+		return s.library.AddTracksToPlaylist(playlistID, target[len(current):])
+	default:
+		if indexes, ok := removalIndexes(current, target); ok {
+			return s.library.RemoveTracksFromPlaylist(playlistID, indexes)
+		}
+		result.Reordered = true
+		return s.library.SetPlaylistTracks(playlistID, target)
+	}
+}
+
+// playlistSongIDs returns the song IDs of a Navidrome playlist in order
+func (s *Syncer) playlistSongIDs(playlistID string) ([]string, error) {
+	songs, err := s.library.GetPlaylistTracks(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	songIDs := make([]string, len(songs))
+	for i, song := range songs {
+		songIDs[i] = song.ID
+	}
+	return songIDs, nil
+}
+
+// ============================================================================
+// 5. Helper Functions
+// ============================================================================
+
+// SpotifyPlaylistID extracts the playlist ID from a Spotify playlist URL or URI
+func SpotifyPlaylistID(playlistURL string) (string, error) {
+	link, err := spotify.ParseLink(playlistURL)
+	if err != nil || link.Type != spotify.PlaylistLink {
+		return "", fmt.Errorf("invalid Spotify playlist URL: %s", playlistURL)
+	}
+	return link.ID, nil
+}
+
+// toTracks converts Spotify tracks to the shared track type used for matching
+func toTracks(spotifyTracks []shared.SpotifyTrack) []shared.Track {
+	tracks := make([]shared.Track, len(spotifyTracks))
+	for i, track := range spotifyTracks {
+		tracks[i] = shared.Track{
+			Title:       track.Name,
+			Artist:      track.Artist,
+			Album:       track.AlbumName,
+			AlbumArtist: track.AlbumArtist,
+			ISRC:        track.ISRC,
+			Duration:    track.Duration,
+		}
+	}
+	return tracks
+}
+
+// withManualSongs appends the songs of the current playlist that were neither synced before
+// nor wanted now, which are songs a user added in Navidrome
+func withManualSongs(desired, current, previouslySynced []string) []string {
+	known := make(map[string]bool, len(desired)+len(previouslySynced))
+	for _, songID := range desired {
+		known[songID] = true
+	}
+	for _, songID := range previouslySynced {
+		known[songID] = true
+	}
+
+	target := append([]string(nil), desired...)
+	for _, songID := range current {
+		if !known[songID] {
+			target = append(target, songID)
+		}
+	}
+	return target
+}
+
+// removalIndexes returns the positions to remove from current to get target, if target is
+// current with some songs taken out
+func removalIndexes(current, target []string) ([]int, bool) {
+	var indexes []int
+	next := 0
+	for i, songID := range current {
+		if next < len(target) && target[next] == songID {
+			next++
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes, next == len(target)
+}
+
+// countSongs counts the occurrences of each song
+func countSongs(songIDs []string) map[string]int {
+	counts := make(map[string]int, len(songIDs))
+	for _, songID := range songIDs {
+		counts[songID]++
+	}
+	return counts
+}
+
+// equalSongs reports whether two song lists are identical
+func equalSongs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,298 @@
+package playlistsync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dab-downloader/internal/api/navidrome"
+	"dab-downloader/internal/shared"
+)
+
+var _ Library = (*navidrome.NavidromeClient)(nil)
+
+// fakeSource serves one Spotify playlist
+type fakeSource struct {
+	tracks []shared.SpotifyTrack
+}
+
+func (s *fakeSource) GetPlaylistTracks(playlistURL string) ([]shared.SpotifyTrack, string, error) {
+	return s.tracks, "Road Trip", nil
+}
+
+// fakeLibrary is an in-memory Navidrome whose songs are identified by title
+type fakeLibrary struct {
+	songs      map[string]bool     // Titles in the library
+	unscanned  map[string]bool     // Titles on disk that appear after a rescan
+	playlists  map[string][]string // Playlist ID to song titles
+	calls      []string
+	nextID     int
+	downloaded []string
+	findErr    error // Returned by FindSong when set
+}
+
+func newFakeLibrary(titles ...string) *fakeLibrary {
+	library := &fakeLibrary{songs: make(map[string]bool), unscanned: make(map[string]bool), playlists: make(map[string][]string)}
+	for _, title := range titles {
+		library.songs[title] = true
+	}
+	return library
+}
+
+func (l *fakeLibrary) CreatePlaylist(name string) (string, error) {
+	l.nextID++
+	playlistID := fmt.Sprintf("pl%d", l.nextID)
+	l.playlists[playlistID] = nil
+	l.calls = append(l.calls, "create")
+	return playlistID, nil
+}
+
+func (l *fakeLibrary) GetPlaylistTracks(playlistID string) ([]navidrome.Song, error) {
+	songIDs, ok := l.playlists[playlistID]
+	if !ok {
+		return nil, &navidrome.APIError{Code: navidrome.ErrorCodeNotFound, Message: "Playlist not found"}
+	}
+	songs := make([]navidrome.Song, len(songIDs))
+	for i, songID := range songIDs {
+		songs[i] = navidrome.Song{ID: songID}
+	}
+	return songs, nil
+}
+
+func (l *fakeLibrary) FindSong(track shared.Track) (*navidrome.Song, error) {
+	if l.findErr != nil {
+		return nil, l.findErr
+	}
+	if !l.songs[track.Title] {
+		return nil, nil
+	}
+	return &navidrome.Song{ID: track.Title, Title: track.Title}, nil
+}
+
+func (l *fakeLibrary) AddTracksToPlaylist(playlistID string, songIDs []string) error {
+	l.playlists[playlistID] = append(l.playlists[playlistID], songIDs...)
+	l.calls = append(l.calls, "add")
+	return nil
+}
+
+func (l *fakeLibrary) RemoveTracksFromPlaylist(playlistID string, indexes []int) error {
+	remove := make(map[int]bool)
+	for _, index := range indexes {
+		remove[index] = true
+	}
+	var kept []string
+	for i, songID := range l.playlists[playlistID] {
+		if !remove[i] {
+			kept = append(kept, songID)
+		}
+	}
+	l.playlists[playlistID] = kept
+	l.calls = append(l.calls, "remove")
+	return nil
+}
+
+func (l *fakeLibrary) SetPlaylistTracks(playlistID string, songIDs []string) error {
+	l.playlists[playlistID] = append([]string(nil), songIDs...)
+	l.calls = append(l.calls, "set")
+	return nil
+}
+
+func (l *fakeLibrary) RescanLibrary(ctx context.Context, pollInterval time.Duration) (*navidrome.ScanStatus, error) {
+	for title := range l.unscanned {
+		l.songs[title] = true
+	}
+	l.unscanned = make(map[string]bool)
+	l.calls = append(l.calls, "rescan")
+	return &navidrome.ScanStatus{Count: len(l.songs)}, nil
+}
+
+// download is a MissingTrackHandler that puts every missing track on disk
+func (l *fakeLibrary) download(ctx context.Context, tracks []shared.Track) (int, error) {
+	for _, track := range tracks {
+		l.unscanned[track.Title] = true
+		l.downloaded = append(l.downloaded, track.Title)
+	}
+	return len(tracks), nil
+}
+
+func spotifyTracks(titles ...string) []shared.SpotifyTrack {
+	tracks := make([]shared.SpotifyTrack, len(titles))
+	for i, title := range titles {
+		tracks[i] = shared.SpotifyTrack{Name: title, Artist: "Artist"}
+	}
+	return tracks
+}
+
+const testPlaylistURL = "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc"
+
+func newTestSyncer(t *testing.T, source *fakeSource, library *fakeLibrary, mappingPath string) *Syncer {
+	t.Helper()
+	store, err := LoadMappingStore(mappingPath)
+	if err != nil {
+		t.Fatalf("Failed to load mappings: %v", err)
+	}
+	return NewSyncer(source, library, store, library.download)
+}
+
+func TestSyncCreatesAndUpdatesPlaylist(t *testing.T) {
+	mappingPath := filepath.Join(t.TempDir(), "playlist-sync.json")
+	library := newFakeLibrary("A", "B", "C", "D")
+	source := &fakeSource{tracks: spotifyTracks("A", "B", "C")}
+
+	result, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	if !result.Created || result.Added != 3 || result.NavidromePlaylistID != "pl1" {
+		t.Errorf("Expected a new playlist with 3 tracks, got %+v", result)
+	}
+
+	// A user adds a song in Navidrome, Spotify appends D and drops B
+	library.playlists["pl1"] = append(library.playlists["pl1"], "X")
+	source.tracks = spotifyTracks("A", "C", "D")
+	library.calls = nil
+
+	// A new syncer reads the persisted mapping
+	result, err = newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.Created || result.Added != 1 || result.Removed != 1 {
+		t.Errorf("Expected 1 added and 1 removed, got %+v", result)
+	}
+	if got := strings.Join(library.playlists["pl1"], ","); got != "A,C,D,X" {
+		t.Errorf("Expected A,C,D,X keeping the manual song, got %s", got)
+	}
+
+	// Reordering on Spotify replaces the list
+	source.tracks = spotifyTracks("D", "A", "C")
+	library.calls = nil
+	result, err = newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("Third sync failed: %v", err)
+	}
+	if !result.Reordered || strings.Join(library.playlists["pl1"], ",") != "D,A,C,X" {
+		t.Errorf("Expected reordered playlist D,A,C,X, got %v (%+v)", library.playlists["pl1"], result)
+	}
+
+	// Nothing changed, nothing is sent
+	library.calls = nil
+	if _, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL); err != nil {
+		t.Fatalf("Fourth sync failed: %v", err)
+	}
+	if len(library.calls) != 0 {
+		t.Errorf("Expected no playlist edits, got %v", library.calls)
+	}
+}
+
+func TestSyncAppendsAndRemovesIncrementally(t *testing.T) {
+	mappingPath := filepath.Join(t.TempDir(), "playlist-sync.json")
+	library := newFakeLibrary("A", "B", "C")
+	source := &fakeSource{tracks: spotifyTracks("A", "B")}
+	syncer := newTestSyncer(t, source, library, mappingPath)
+
+	if _, err := syncer.Sync(context.Background(), testPlaylistURL); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	source.tracks = spotifyTracks("A", "B", "C")
+	library.calls = nil
+	if _, err := syncer.Sync(context.Background(), testPlaylistURL); err != nil {
+		t.Fatalf("Append sync failed: %v", err)
+	}
+	if strings.Join(library.calls, ",") != "add" {
+		t.Errorf("Expected a single add call, got %v", library.calls)
+	}
+
+	source.tracks = spotifyTracks("A", "C")
+	library.calls = nil
+	if _, err := syncer.Sync(context.Background(), testPlaylistURL); err != nil {
+		t.Fatalf("Removal sync failed: %v", err)
+	}
+	if strings.Join(library.calls, ",") != "remove" || strings.Join(library.playlists["pl1"], ",") != "A,C" {
+		t.Errorf("Expected a single remove call leaving A,C, got %v and %v", library.calls, library.playlists["pl1"])
+	}
+}
+
+func TestSyncDownloadsMissingTracks(t *testing.T) {
+	library := newFakeLibrary("A")
+	source := &fakeSource{tracks: spotifyTracks("A", "B")}
+	syncer := newTestSyncer(t, source, library, filepath.Join(t.TempDir(), "playlist-sync.json"))
+
+	result, err := syncer.Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Downloaded != 1 || len(result.Unmatched) != 0 {
+		t.Errorf("Expected B to be downloaded and matched, got %+v", result)
+	}
+	if got := strings.Join(library.playlists["pl1"], ","); got != "A,B" {
+		t.Errorf("Expected A,B in Spotify order, got %s", got)
+	}
+	if !strings.Contains(strings.Join(library.calls, ","), "rescan") {
+		t.Errorf("Expected a library rescan after downloading, got %v", library.calls)
+	}
+}
+
+func TestSyncRecreatesDeletedPlaylist(t *testing.T) {
+	mappingPath := filepath.Join(t.TempDir(), "playlist-sync.json")
+	library := newFakeLibrary("A")
+	source := &fakeSource{tracks: spotifyTracks("A")}
+
+	if _, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	delete(library.playlists, "pl1")
+
+	result, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if !result.Created || result.NavidromePlaylistID != "pl2" {
+		t.Errorf("Expected the deleted playlist to be recreated, got %+v", result)
+	}
+}
+
+func TestSyncKeepsPlaylistCreatedBeforeFailure(t *testing.T) {
+	mappingPath := filepath.Join(t.TempDir(), "playlist-sync.json")
+	library := newFakeLibrary("A")
+	library.findErr = fmt.Errorf("search failed")
+	source := &fakeSource{tracks: spotifyTracks("A")}
+
+	if _, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL); err == nil {
+		t.Fatal("Expected the first sync to fail")
+	}
+
+	library.findErr = nil
+	result, err := newTestSyncer(t, source, library, mappingPath).Sync(context.Background(), testPlaylistURL)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.Created || result.NavidromePlaylistID != "pl1" || len(library.playlists) != 1 {
+		t.Errorf("Expected the playlist from the failed sync to be reused, got %+v and %v", result, library.playlists)
+	}
+	if got := strings.Join(library.playlists["pl1"], ","); got != "A" {
+		t.Errorf("Expected A in the playlist, got %s", got)
+	}
+}
+
+func TestSpotifyPlaylistID(t *testing.T) {
+	tests := map[string]string{
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M":         "37i9dQZF1DXcBWIGoYBM5M",
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc":  "37i9dQZF1DXcBWIGoYBM5M",
+		"https://open.spotify.com/intl-de/playlist/37i9dQZF1DXcBWIGoYBM5M": "37i9dQZF1DXcBWIGoYBM5M",
+		"spotify:playlist:37i9dQZF1DXcBWIGoYBM5M":                          "37i9dQZF1DXcBWIGoYBM5M",
+	}
+	for playlistURL, expected := range tests {
+		if got, err := SpotifyPlaylistID(playlistURL); err != nil || got != expected {
+			t.Errorf("SpotifyPlaylistID(%q) = %q, %v; expected %q", playlistURL, got, err, expected)
+		}
+	}
+
+	if _, err := SpotifyPlaylistID("https://open.spotify.com/album/1"); err == nil {
+		t.Error("Expected an error for an album URL")
+	}
+}
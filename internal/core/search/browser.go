@@ -0,0 +1,470 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+// DefaultPageSize is the number of results per type on one page of the interactive search
+const DefaultPageSize = 10
+
+// browserHelp lists the commands of the interactive search
+const browserHelp = `Commands:
+  1,3,5-7      select items to download
+  all          in an artist or album view: select the whole artist or album
+  n / p        next / previous page
+  t <type>     search another type: artist, album, track or all
+  h            toggle hi-res only
+  y <from-to>  only releases from these years, e.g. "y 2010-2019", "y 2015-" or "y" to clear
+  a <number>   show an artist's discography
+  l <number>   show an album's tracks (or the album of a track)
+  b            back to the previous list
+  q            quit`
+
+// BrowserAPI is the part of the DAB API used by the interactive search
+type BrowserAPI interface {
+	SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error)
+	GetArtist(ctx context.Context, artistID string, config *config.Config, debug bool) (*shared.Artist, error)
+	GetAlbum(ctx context.Context, albumID string) (*shared.Album, error)
+}
+
+// Filter narrows the albums and tracks shown by the interactive search. Artists are always shown.
+type Filter struct {
+	HiResOnly bool
+	FromYear  int // 0 for no lower bound
+	ToYear    int // 0 for no upper bound
+}
+
+// Matches reports whether a release with the given year and quality passes the filter. Releases
+// without a known year pass a year range.
+func (f Filter) Matches(year string, quality shared.AudioQuality) bool {
+	if f.HiResOnly && !shared.IsHiRes(quality) {
+		return false
+	}
+	if number, err := strconv.Atoi(year); err == nil {
+		if f.FromYear > 0 && number < f.FromYear {
+			return false
+		}
+		if f.ToYear > 0 && number > f.ToYear {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the active filters, or returns "" when there are none
+func (f Filter) String() string {
+	var parts []string
+	if f.HiResOnly {
+		parts = append(parts, "hi-res only")
+	}
+	switch {
+	case f.FromYear > 0 && f.ToYear > 0:
+		parts = append(parts, fmt.Sprintf("%d-%d", f.FromYear, f.ToYear))
+	case f.FromYear > 0:
+		parts = append(parts, fmt.Sprintf("from %d", f.FromYear))
+	case f.ToYear > 0:
+		parts = append(parts, fmt.Sprintf("until %d", f.ToYear))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseYearRange parses "2010-2019", "2015-", "-2000" or "2012" as a year range
+func ParseYearRange(value string) (int, int, error) {
+	from, to, err := shared.ParseRange(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid year range %q: %w", value, err)
+	}
+	for _, year := range []int{from, to} {
+		if year != 0 && (year < 1000 || year > 9999) {
+			return 0, 0, fmt.Errorf("invalid year: %d", year)
+		}
+	}
+	return from, to, nil
+}
+
+// browserItem is one numbered line of the interactive search
+type browserItem struct {
+	value    interface{} // shared.Artist, shared.Album or shared.Track
+	itemType string      // "artist", "album" or "track"
+}
+
+// Browser is the interactive search: it pages through results, changes the search type and
+// filters, and opens artists and albums before the items to download are selected.
+type Browser struct {
+	api        BrowserAPI
+	cfg        *config.Config
+	debug      bool
+	input      func(prompt string, defaultValue string) string
+	query      string
+	searchType string
+	limit      int
+	offset     int
+	filter     Filter
+	results    *shared.SearchResults // Current page, nil until fetched
+}
+
+// NewBrowser creates an interactive search for the query that reads commands from stdin
+func NewBrowser(api BrowserAPI, query string, searchType string, cfg *config.Config, debug bool) *Browser {
+	if searchType == "" {
+		searchType = "all"
+	}
+	return &Browser{
+		api:        api,
+		cfg:        cfg,
+		debug:      debug,
+		input:      shared.GetUserInput,
+		query:      query,
+		searchType: searchType,
+		limit:      DefaultPageSize,
+	}
+}
+
+// Run shows the results until items are selected or the user quits. It returns the selected
+// items and their types ("artist", "album" or "track") like HandleSearch.
+func (b *Browser) Run(ctx context.Context) ([]interface{}, []string, error) {
+	fmt.Println(browserHelp)
+
+	for {
+		if b.results == nil {
+			results, err := b.api.SearchPage(ctx, b.query, b.searchType, b.limit, b.offset, b.debug)
+			if err != nil {
+				return nil, nil, err
+			}
+			b.results = results
+		}
+
+		items := b.resultItems(b.results)
+		b.printHeader(fmt.Sprintf("Results for '%s' (type: %s, page %d)", b.query, b.searchType, b.offset/b.limit+1))
+		if len(items) == 0 {
+			shared.ColorWarning.Println("No results found.")
+		}
+		printItems(items)
+
+		command, argument := splitCommand(b.input("\nSelect items or enter a command (? for help)", ""))
+		switch command {
+		case "", "q", "quit":
+			return nil, nil, nil
+		case "n":
+			if !hasMoreResults(b.results, b.limit) {
+				shared.ColorWarning.Println("No more results.")
+				continue
+			}
+			b.offset += b.limit
+			b.results = nil
+		case "p":
+			if b.offset == 0 {
+				shared.ColorWarning.Println("Already on the first page.")
+				continue
+			}
+			b.offset -= b.limit
+			if b.offset < 0 {
+				b.offset = 0
+			}
+			b.results = nil
+		case "t":
+			searchType := strings.ToLower(argument)
+			if searchType != "artist" && searchType != "album" && searchType != "track" && searchType != "all" {
+				shared.ColorError.Printf("Unknown type %q (artist, album, track or all)\n", argument)
+				continue
+			}
+			b.searchType, b.offset = searchType, 0
+			b.results = nil
+		case "a", "l":
+			selected, done, err := b.open(ctx, items, command, argument)
+			if err != nil {
+				shared.ColorError.Printf("❌ %v\n", err)
+				continue
+			}
+			if done {
+				return splitItems(selected)
+			}
+		default:
+			if b.applyFilterCommand(command, argument) {
+				continue
+			}
+			selected, err := selectItems(items, command)
+			if err != nil {
+				shared.ColorError.Printf("Invalid selection: %v\n", err)
+				continue
+			}
+			return splitItems(selected)
+		}
+	}
+}
+
+// open shows the artist or album of a result. done is true when the user made a selection or quit.
+func (b *Browser) open(ctx context.Context, items []browserItem, command string, argument string) ([]browserItem, bool, error) {
+	index, err := strconv.Atoi(argument)
+	if err != nil || index < 1 || index > len(items) {
+		return nil, false, fmt.Errorf("enter the number of an item, e.g. %q", command+" 1")
+	}
+
+	switch value := items[index-1].value.(type) {
+	case shared.Artist:
+		if command == "a" {
+			return b.browseArtist(ctx, value)
+		}
+	case shared.Album:
+		if command == "l" {
+			return b.browseAlbum(ctx, value)
+		}
+	case shared.Track:
+		if command == "l" && value.AlbumID != "" {
+			return b.browseAlbum(ctx, shared.Album{ID: value.AlbumID, Title: value.AlbumTitle})
+		}
+		if command == "a" {
+			if shared.IdToString(value.ArtistId) != "" {
+				return b.browseArtist(ctx, shared.Artist{ID: value.ArtistId, Name: value.Artist})
+			}
+		}
+	}
+	if command == "a" {
+		return nil, false, fmt.Errorf("item %d has no artist to show", index)
+	}
+	return nil, false, fmt.Errorf("item %d has no album to show", index)
+}
+
+// browseArtist lists the artist's discography. Selecting "all" selects the artist itself.
+func (b *Browser) browseArtist(ctx context.Context, artist shared.Artist) ([]browserItem, bool, error) {
+	details, err := b.api.GetArtist(ctx, shared.IdToString(artist.ID), b.cfg, b.debug)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get artist: %w", err)
+	}
+	if details.Name == "" {
+		details.Name = artist.Name
+	}
+	if details.ID == nil {
+		details.ID = artist.ID
+	}
+
+	for {
+		var items []browserItem
+		for _, album := range details.Albums {
+			if b.filter.Matches(releaseYear(album.Year, album.ReleaseDate), album.AudioQuality) {
+				items = append(items, browserItem{value: album, itemType: "album"})
+			}
+		}
+		b.printHeader(fmt.Sprintf("Discography of %s (%d releases)", details.Name, len(details.Albums)))
+		printItems(items)
+
+		command, argument := splitCommand(b.input("\nSelect releases, 'all' for the artist, 'l <number>' for tracks or 'b' to go back", ""))
+		switch command {
+		case "b", "":
+			return nil, false, nil
+		case "q", "quit":
+			return nil, true, nil
+		case "all":
+			return []browserItem{{value: *details, itemType: "artist"}}, true, nil
+		case "l":
+			selected, done, err := b.open(ctx, items, command, argument)
+			if err != nil {
+				shared.ColorError.Printf("❌ %v\n", err)
+				continue
+			}
+			if done {
+				return selected, true, nil
+			}
+		default:
+			if b.applyFilterCommand(command, argument) {
+				continue
+			}
+			selected, err := selectItems(items, command)
+			if err != nil {
+				shared.ColorError.Printf("Invalid selection: %v\n", err)
+				continue
+			}
+			return selected, true, nil
+		}
+	}
+}
+
+// browseAlbum lists the album's tracks. Selecting "all" selects the album itself.
+func (b *Browser) browseAlbum(ctx context.Context, album shared.Album) ([]browserItem, bool, error) {
+	details, err := b.api.GetAlbum(ctx, album.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get album: %w", err)
+	}
+
+	items := make([]browserItem, len(details.Tracks))
+	for i, track := range details.Tracks {
+		if track.AlbumTitle == "" && track.Album == "" {
+			track.AlbumTitle = details.Title
+		}
+		if track.AlbumID == "" {
+			track.AlbumID = details.ID
+		}
+		items[i] = browserItem{value: track, itemType: "track"}
+	}
+
+	for {
+		date := details.ReleaseDate
+		if date == "" {
+			date = details.Year
+		}
+		b.printHeader(fmt.Sprintf("%s - %s (%s)", details.Title, details.Artist, date))
+		printItems(items)
+
+		command, _ := splitCommand(b.input("\nSelect tracks, 'all' for the album or 'b' to go back", ""))
+		switch command {
+		case "b", "":
+			return nil, false, nil
+		case "q", "quit":
+			return nil, true, nil
+		case "all":
+			return []browserItem{{value: *details, itemType: "album"}}, true, nil
+		default:
+			selected, err := selectItems(items, command)
+			if err != nil {
+				shared.ColorError.Printf("Invalid selection: %v\n", err)
+				continue
+			}
+			return selected, true, nil
+		}
+	}
+}
+
+// applyFilterCommand handles the "h" and "y" commands and reports whether the command was one of them
+func (b *Browser) applyFilterCommand(command string, argument string) bool {
+	switch command {
+	case "h":
+		b.filter.HiResOnly = !b.filter.HiResOnly
+	case "y":
+		if argument == "" {
+			b.filter.FromYear, b.filter.ToYear = 0, 0
+			return true
+		}
+		from, to, err := ParseYearRange(argument)
+		if err != nil {
+			shared.ColorError.Printf("%v\n", err)
+			return true
+		}
+		b.filter.FromYear, b.filter.ToYear = from, to
+	case "?", "help":
+		fmt.Println(browserHelp)
+	default:
+		return false
+	}
+	return true
+}
+
+// resultItems numbers the search results that pass the filter: artists, then albums, then tracks
+func (b *Browser) resultItems(results *shared.SearchResults) []browserItem {
+	if results == nil {
+		return nil
+	}
+
+	var items []browserItem
+	for _, artist := range results.Artists {
+		items = append(items, browserItem{value: artist, itemType: "artist"})
+	}
+	for _, album := range results.Albums {
+		if b.filter.Matches(releaseYear(album.Year, album.ReleaseDate), album.AudioQuality) {
+			items = append(items, browserItem{value: album, itemType: "album"})
+		}
+	}
+	for _, track := range results.Tracks {
+		if b.filter.Matches(releaseYear(track.Year, track.ReleaseDate), track.AudioQuality) {
+			items = append(items, browserItem{value: track, itemType: "track"})
+		}
+	}
+	return items
+}
+
+// printHeader prints the title of a list with the active filters
+func (b *Browser) printHeader(title string) {
+	fmt.Println()
+	shared.ColorInfo.Printf("=== %s ===\n", title)
+	if filter := b.filter.String(); filter != "" {
+		shared.ColorWarning.Printf("Filter: %s\n", filter)
+	}
+}
+
+// printItems prints numbered items with a heading for each type
+func printItems(items []browserItem) {
+	headings := map[string]string{"artist": "Artists", "album": "Albums", "track": "Tracks"}
+	previousType := ""
+	for i, item := range items {
+		if item.itemType != previousType {
+			shared.ColorInfo.Printf("\n--- %s ---\n", headings[item.itemType])
+			previousType = item.itemType
+		}
+
+		prefix := fmt.Sprintf("%d. ", i+1)
+		switch value := item.value.(type) {
+		case shared.Artist:
+			fmt.Printf("%s%s\n", prefix, value.Name)
+		case shared.Album:
+			date := value.ReleaseDate
+			if date == "" {
+				date = value.Year
+			}
+			if value.Type != "" {
+				prefix += fmt.Sprintf("[%s] ", strings.ToUpper(value.Type))
+			}
+			fmt.Println(shared.FormatAlbumWithBitrate(prefix, value.Title, value.Artist, date, value.AudioQuality))
+		case shared.Track:
+			albumName := value.AlbumTitle
+			if albumName == "" {
+				albumName = value.Album
+			}
+			fmt.Println(shared.FormatTrackWithBitrate(prefix, value.Title, value.Artist, albumName, value.AudioQuality))
+		}
+	}
+}
+
+// selectItems parses a selection such as "1,3,5-7"
+func selectItems(items []browserItem, selection string) ([]browserItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("nothing to select")
+	}
+
+	indices, err := shared.ParseSelectionInput(selection, len(items))
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no items in range 1-%d", len(items))
+	}
+
+	selected := make([]browserItem, len(indices))
+	for i, index := range indices {
+		selected[i] = items[index-1]
+	}
+	return selected, nil
+}
+
+// splitItems converts browser items to the values and types returned by HandleSearch
+func splitItems(items []browserItem) ([]interface{}, []string, error) {
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
+
+	values := make([]interface{}, len(items))
+	types := make([]string, len(items))
+	for i, item := range items {
+		values[i], types[i] = item.value, item.itemType
+	}
+	return values, types, nil
+}
+
+// splitCommand splits input such as "t album" into the command and its argument. Selections are
+// returned whole as the command.
+func splitCommand(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.ContainsAny(input[:1], "0123456789") {
+		return input, ""
+	}
+	command, argument, _ := strings.Cut(input, " ")
+	return strings.ToLower(command), strings.TrimSpace(argument)
+}
+
+// hasMoreResults reports whether a full page was returned for any type
+func hasMoreResults(results *shared.SearchResults, limit int) bool {
+	return results != nil && (len(results.Artists) >= limit || len(results.Albums) >= limit || len(results.Tracks) >= limit)
+}
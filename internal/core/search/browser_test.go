@@ -0,0 +1,170 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+)
+
+// fakeBrowserAPI serves numbered albums so pages can be told apart
+type fakeBrowserAPI struct {
+	total   int
+	offsets []int
+	types   []string
+}
+
+func (f *fakeBrowserAPI) SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error) {
+	f.offsets = append(f.offsets, offset)
+	f.types = append(f.types, searchType)
+
+	results := &shared.SearchResults{}
+	for i := offset; i < offset+limit && i < f.total; i++ {
+		quality := shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}
+		if i%2 == 0 {
+			quality = shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 96}
+		}
+		results.Albums = append(results.Albums, shared.Album{
+			ID:           fmt.Sprintf("album-%d", i),
+			Title:        fmt.Sprintf("Album %d", i),
+			ReleaseDate:  fmt.Sprintf("%d-01-01", 2000+i),
+			AudioQuality: quality,
+		})
+	}
+	return results, nil
+}
+
+func (f *fakeBrowserAPI) GetArtist(ctx context.Context, artistID string, cfg *config.Config, debug bool) (*shared.Artist, error) {
+	return &shared.Artist{
+		ID:   artistID,
+		Name: "Artist",
+		Albums: []shared.Album{
+			{ID: "a1", Title: "Old", Year: "1990"},
+			{ID: "a2", Title: "New", Year: "2020"},
+		},
+	}, nil
+}
+
+func (f *fakeBrowserAPI) GetAlbum(ctx context.Context, albumID string) (*shared.Album, error) {
+	return &shared.Album{
+		ID:     albumID,
+		Title:  "Album " + albumID,
+		Tracks: []shared.Track{{ID: float64(1), Title: "One"}, {ID: float64(2), Title: "Two"}},
+	}, nil
+}
+
+// newTestBrowser creates a browser that reads the given inputs in order
+func newTestBrowser(api BrowserAPI, inputs ...string) *Browser {
+	browser := NewBrowser(api, "query", "album", nil, false)
+	browser.limit = 3
+	browser.input = func(prompt string, defaultValue string) string {
+		if len(inputs) == 0 {
+			return "q"
+		}
+		input := inputs[0]
+		inputs = inputs[1:]
+		return input
+	}
+	return browser
+}
+
+func TestBrowserPaging(t *testing.T) {
+	api := &fakeBrowserAPI{total: 7}
+	items, types, err := newTestBrowser(api, "n", "n", "n", "p", "2").Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// The third "n" is on the last, partial page and does not search again
+	expectedOffsets := []int{0, 3, 6, 3}
+	if fmt.Sprint(api.offsets) != fmt.Sprint(expectedOffsets) {
+		t.Errorf("Expected offsets %v, got %v", expectedOffsets, api.offsets)
+	}
+	if len(items) != 1 || types[0] != "album" || items[0].(shared.Album).ID != "album-4" {
+		t.Errorf("Expected album-4 to be selected, got %v %v", items, types)
+	}
+}
+
+func TestBrowserFilters(t *testing.T) {
+	api := &fakeBrowserAPI{total: 3}
+	items, _, err := newTestBrowser(api, "h", "1-2").Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(items) != 2 || items[0].(shared.Album).ID != "album-0" || items[1].(shared.Album).ID != "album-2" {
+		t.Errorf("Expected the hi-res albums, got %v", items)
+	}
+
+	items, _, err = newTestBrowser(api, "y 2001-", "1").Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(items) != 1 || items[0].(shared.Album).ID != "album-1" {
+		t.Errorf("Expected album-1 as the first album from 2001, got %v", items)
+	}
+	if len(api.offsets) != 2 {
+		t.Errorf("Expected filters not to search again, got %d searches", len(api.offsets))
+	}
+}
+
+func TestBrowserChangeType(t *testing.T) {
+	api := &fakeBrowserAPI{total: 5}
+	if _, _, err := newTestBrowser(api, "n", "t track", "q").Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if fmt.Sprint(api.types) != "[album album track]" || api.offsets[2] != 0 {
+		t.Errorf("Expected a new track search from the first page, got types %v offsets %v", api.types, api.offsets)
+	}
+}
+
+func TestBrowserDrillDown(t *testing.T) {
+	api := &fakeBrowserAPI{total: 2}
+
+	// Open the album, go back, open it again and pick a track
+	items, types, err := newTestBrowser(api, "l 2", "b", "l 2", "2").Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(items) != 1 || types[0] != "track" || items[0].(shared.Track).Title != "Two" || items[0].(shared.Track).AlbumID != "album-1" {
+		t.Errorf("Expected track Two of album-1, got %v %v", items, types)
+	}
+
+	// "all" in the album view selects the album
+	_, types, _ = newTestBrowser(api, "l 1", "all").Run(context.Background())
+	if fmt.Sprint(types) != "[album]" {
+		t.Errorf("Expected the album to be selected, got %v", types)
+	}
+
+	// An artist's discography honors the year filter
+	browser := newTestBrowser(api, "y 2000-", "1")
+	selected, done, err := browser.browseArtist(context.Background(), shared.Artist{ID: "artist-1", Name: "Artist"})
+	if err != nil || !done {
+		t.Fatalf("browseArtist failed: %v (done %v)", err, done)
+	}
+	if len(selected) != 1 || selected[0].value.(shared.Album).Title != "New" {
+		t.Errorf("Expected the 2020 release, got %+v", selected)
+	}
+}
+
+func TestParseYearRange(t *testing.T) {
+	tests := map[string][2]int{
+		"2010-2019": {2010, 2019},
+		"2015-":     {2015, 0},
+		"-2000":     {0, 2000},
+		"2012":      {2012, 2012},
+		"2019-2010": {2010, 2019},
+	}
+	for input, expected := range tests {
+		from, to, err := ParseYearRange(input)
+		if err != nil || from != expected[0] || to != expected[1] {
+			t.Errorf("ParseYearRange(%q) = %d, %d, %v, expected %v", input, from, to, err, expected)
+		}
+	}
+	for _, input := range []string{"", "-", "abc", "12-2000"} {
+		if _, _, err := ParseYearRange(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"dab-downloader/internal/shared"
+)
+
+const (
+	// DefaultMatchThreshold is the lowest score accepted as a match when none is configured
+	DefaultMatchThreshold = 0.75
+	// DefaultDurationTolerance is how many seconds two durations may differ and still count as equal
+	DefaultDurationTolerance = 3
+	// DefaultMatchReportFile is where the unmatched and low-confidence report is written when none is configured
+	DefaultMatchReportFile = "config/match-report.json"
+
+	// minTitleScore rejects candidates whose title is clearly a different song
+	minTitleScore = 0.5
+)
+
+// Score weights, redistributed over the components both tracks have
+const (
+	titleWeight    = 0.45
+	artistWeight   = 0.30
+	albumWeight    = 0.10
+	durationWeight = 0.15
+)
+
+// MatchStatus is the outcome of matching one track
+type MatchStatus string
+
+const (
+	MatchAccepted      MatchStatus = "matched"
+	MatchLowConfidence MatchStatus = "low_confidence"
+	MatchNotFound      MatchStatus = "unmatched"
+)
+
+// Types of matches other than tracks
+const (
+	MatchTypeAlbum  = "album"
+	MatchTypeArtist = "artist"
+)
+
+// Match is the best DAB candidate found for an external track, album or artist. Albums keep their
+// title in Title and artists their name in Artist.
+type Match struct {
+	Type      string // MatchTypeAlbum or MatchTypeArtist, empty for tracks
+	Query     shared.Track
+	Candidate *shared.Track // Best scoring candidate, nil when there was none
+	Score     float64
+	Status    MatchStatus
+}
+
+// Accepted reports whether the candidate scored above the threshold
+func (m Match) Accepted() bool {
+	return m.Status == MatchAccepted
+}
+
+// TrackSearcher runs raw DAB searches, implemented by the search service and the DAB API client
+type TrackSearcher interface {
+	Search(ctx context.Context, query string, searchType string, limit int, debug bool) (*shared.SearchResults, error)
+}
+
+// Matcher scores DAB candidates for tracks from other sources such as Spotify playlists
+type Matcher struct {
+	Threshold         float64 // Lowest accepted score (0-1)
+	DurationTolerance int     // Seconds
+	SearchLimit       int
+}
+
+// NewMatcher creates a matcher. A threshold of 0 uses DefaultMatchThreshold.
+func NewMatcher(threshold float64) *Matcher {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultMatchThreshold
+	}
+	return &Matcher{
+		Threshold:         threshold,
+		DurationTolerance: DefaultDurationTolerance,
+		SearchLimit:       10,
+	}
+}
+
+// FindTrack searches DAB for a track and returns the best scoring candidate. The title and
+// primary artist are searched first, then the title alone when that finds no accepted match.
+func (m *Matcher) FindTrack(ctx context.Context, searcher TrackSearcher, query shared.Track, debug bool) (Match, error) {
+	queries := []string{query.Title + " " + PrimaryArtist(query.Artist), query.Title}
+
+	var candidates []shared.Track
+	seen := make(map[string]bool)
+	var match Match
+	for _, searchQuery := range queries {
+		results, err := searcher.Search(ctx, searchQuery, "track", m.SearchLimit, debug)
+		if err != nil {
+			return Match{Query: query, Status: MatchNotFound}, fmt.Errorf("failed to search for %s - %s: %w", query.Artist, query.Title, err)
+		}
+		for _, track := range results.Tracks {
+			id := shared.IdToString(track.ID)
+			if !seen[id] {
+				seen[id] = true
+				candidates = append(candidates, track)
+			}
+		}
+
+		match = m.Best(query, candidates)
+		if match.Accepted() {
+			break
+		}
+	}
+	return match, nil
+}
+
+// Best scores every candidate and returns the highest scoring one
+func (m *Matcher) Best(query shared.Track, candidates []shared.Track) Match {
+	match := Match{Query: query, Status: MatchNotFound}
+	for i := range candidates {
+		score := m.Score(query, candidates[i])
+		if score > match.Score {
+			match.Score = score
+			match.Candidate = &candidates[i]
+		}
+	}
+
+	match.Status = m.status(match)
+	return match
+}
+
+// BestAlbum scores albums found for a release from another source and returns the highest
+// scoring one with the match. Edition notes such as "(Remastered)" are ignored in titles.
+func (m *Matcher) BestAlbum(title, artist string, candidates []shared.Album) (*shared.Album, Match) {
+	match := Match{Type: MatchTypeAlbum, Query: shared.Track{Title: title, Artist: artist}}
+	var best *shared.Album
+	for i, album := range candidates {
+		score := shared.TextSimilarity(normalizeTitle(title), normalizeTitle(album.Title))
+		if score < minTitleScore {
+			continue
+		}
+		if artist != "" && album.Artist != "" {
+			score = (titleWeight*score + artistWeight*artistOverlap(artist, album.Artist)) / (titleWeight + artistWeight)
+		}
+		if score > match.Score {
+			best = &candidates[i]
+			match.Score = score
+			match.Candidate = &shared.Track{ID: album.ID, Title: album.Title, Artist: album.Artist}
+		}
+	}
+	match.Status = m.status(match)
+	return best, match
+}
+
+// BestArtist returns the artist whose name matches the query and the match. Names match when they
+// are equal after normalization, other names only score their share of common words.
+func (m *Matcher) BestArtist(name string, candidates []shared.Artist) (*shared.Artist, Match) {
+	match := Match{Type: MatchTypeArtist, Query: shared.Track{Artist: name}}
+	var best *shared.Artist
+	query := normalizeArtistName(name)
+	for i, artist := range candidates {
+		candidate := normalizeArtistName(artist.Name)
+		score := shared.WordSimilarity(query, candidate)
+		if query != "" && query == candidate {
+			score = 1
+		}
+		if score > match.Score {
+			best = &candidates[i]
+			match.Score = score
+			match.Candidate = &shared.Track{ID: artist.ID, Artist: artist.Name}
+		}
+	}
+	match.Status = m.status(match)
+	return best, match
+}
+
+// status classifies a scored match
+func (m *Matcher) status(match Match) MatchStatus {
+	switch {
+	case match.Candidate == nil:
+		return MatchNotFound
+	case match.Score >= m.Threshold:
+		return MatchAccepted
+	default:
+		return MatchLowConfidence
+	}
+}
+
+// Score rates how likely a candidate is the query track, from 0 to 1. An equal ISRC is a
+// certain match; otherwise normalized title, artist overlap, album and duration are weighed.
+func (m *Matcher) Score(query, candidate shared.Track) float64 {
+	if query.ISRC != "" && strings.EqualFold(query.ISRC, candidate.ISRC) {
+		return 1
+	}
+
+	title := shared.TextSimilarity(normalizeTitle(query.Title), normalizeTitle(candidate.Title))
+	if title < minTitleScore {
+		return 0
+	}
+
+	total := titleWeight * title
+	weights := titleWeight
+
+	if query.Artist != "" && candidate.Artist != "" {
+		total += artistWeight * artistOverlap(query.Artist, candidate.Artist)
+		weights += artistWeight
+	}
+
+	queryAlbum, candidateAlbum := albumName(query), albumName(candidate)
+	if queryAlbum != "" && candidateAlbum != "" {
+		total += albumWeight * shared.TextSimilarity(normalizeTitle(queryAlbum), normalizeTitle(candidateAlbum))
+		weights += albumWeight
+	}
+
+	if query.Duration > 0 && candidate.Duration > 0 {
+		total += durationWeight * m.durationScore(query.Duration, candidate.Duration)
+		weights += durationWeight
+	}
+
+	return total / weights
+}
+
+// durationScore is 1 within the tolerance and falls to 0 at three times the tolerance
+func (m *Matcher) durationScore(a, b int) float64 {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	tolerance := m.DurationTolerance
+	if tolerance <= 0 {
+		tolerance = DefaultDurationTolerance
+	}
+	if diff <= tolerance {
+		return 1
+	}
+	score := 1 - float64(diff-tolerance)/float64(2*tolerance)
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// ============================================================================
+// Match Report
+// ============================================================================
+
+// MatchReport collects the tracks that were not matched with confidence
+type MatchReport struct {
+	Threshold float64
+	mu        sync.Mutex
+	matched   int
+	entries   []Match
+}
+
+// NewMatchReport creates an empty report for matches made with the given threshold, where 0
+// means DefaultMatchThreshold
+func NewMatchReport(threshold float64) *MatchReport {
+	return &MatchReport{Threshold: NewMatcher(threshold).Threshold}
+}
+
+// Add records the outcome of a match
+func (r *MatchReport) Add(match Match) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if match.Accepted() {
+		r.matched++
+		return
+	}
+	r.entries = append(r.entries, match)
+}
+
+// Matched returns how many tracks were matched with confidence
+func (r *MatchReport) Matched() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.matched
+}
+
+// Problems returns the unmatched and low-confidence matches in the order they were added
+func (r *MatchReport) Problems() []Match {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Match(nil), r.entries...)
+}
+
+// reportEntry is the JSON form of a match that was not accepted
+type reportEntry struct {
+	Type            string      `json:"type,omitempty"`
+	Status          MatchStatus `json:"status"`
+	Title           string      `json:"title"`
+	Artist          string      `json:"artist"`
+	Album           string      `json:"album,omitempty"`
+	ISRC            string      `json:"isrc,omitempty"`
+	Score           float64     `json:"score"`
+	CandidateID     string      `json:"candidate_id,omitempty"`
+	CandidateTitle  string      `json:"candidate_title,omitempty"`
+	CandidateArtist string      `json:"candidate_artist,omitempty"`
+	CandidateAlbum  string      `json:"candidate_album,omitempty"`
+}
+
+// Save writes the report as JSON
+func (r *MatchReport) Save(path string) error {
+	if path == "" {
+		path = DefaultMatchReportFile
+	}
+
+	report := struct {
+		Threshold float64       `json:"threshold"`
+		Matched   int           `json:"matched"`
+		Problems  []reportEntry `json:"problems"`
+	}{Threshold: r.Threshold, Matched: r.Matched(), Problems: []reportEntry{}}
+
+	for _, match := range r.Problems() {
+		entry := reportEntry{
+			Type:   match.Type,
+			Status: match.Status,
+			Title:  match.Query.Title,
+			Artist: match.Query.Artist,
+			Album:  albumName(match.Query),
+			ISRC:   match.Query.ISRC,
+			Score:  float64(int(match.Score*1000)) / 1000,
+		}
+		if match.Candidate != nil {
+			entry.CandidateID = shared.IdToString(match.Candidate.ID)
+			entry.CandidateTitle = match.Candidate.Title
+			entry.CandidateArtist = match.Candidate.Artist
+			entry.CandidateAlbum = albumName(*match.Candidate)
+		}
+		report.Problems = append(report.Problems, entry)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create match report directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode match report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write match report: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Normalization Helpers
+// ============================================================================
+
+var (
+	// versionSuffix matches " - Remastered 2011", " - 2011 Remaster", " - feat. X" and similar. Live,
+	// edit, mono and version suffixes name a different recording and are kept.
+	versionSuffix = regexp.MustCompile(`(?i)\s+-\s+.*\b(remaster(ed)?|feat\.?|ft\.?|featuring|with)\b.*$`)
+	// versionGroup matches "(Remastered)", "[feat. X]", "(2011 Remaster)" and similar
+	versionGroup = regexp.MustCompile(`(?i)\s*[\(\[][^\)\]]*\b(remaster(ed)?|feat\.?|ft\.?|featuring|with)\b[^\)\]]*[\)\]]`)
+	// featuring matches a trailing "feat. X" outside brackets
+	featuring = regexp.MustCompile(`(?i)\s+(feat\.?|ft\.?|featuring)\s+.*$`)
+	// artistSeparator splits artist credits such as "A, B & C feat. D"
+	artistSeparator = regexp.MustCompile(`(?i)\s*(,|&|;|\bfeat\.?|\bft\.?|\bfeaturing\b|\bwith\b|\bx\b|\band\b)\s*`)
+)
+
+// normalizeTitle strips remaster and featuring notes and punctuation from a title
+func normalizeTitle(title string) string {
+	title = versionGroup.ReplaceAllString(title, "")
+	title = versionSuffix.ReplaceAllString(title, "")
+	title = featuring.ReplaceAllString(title, "")
+	return shared.NormalizeText(title)
+}
+
+// splitArtists splits an artist credit into normalized names
+func splitArtists(credit string) []string {
+	var names []string
+	for _, name := range artistSeparator.Split(credit, -1) {
+		if name = shared.NormalizeText(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// artistOverlap is the share of the query's artists credited on the candidate
+func artistOverlap(query, candidate string) float64 {
+	queryArtists, candidateArtists := splitArtists(query), splitArtists(candidate)
+	if len(queryArtists) == 0 || len(candidateArtists) == 0 {
+		return 0
+	}
+
+	found := 0
+	for _, queryArtist := range queryArtists {
+		for _, candidateArtist := range candidateArtists {
+			if queryArtist == candidateArtist {
+				found++
+				break
+			}
+		}
+	}
+	overlap := float64(found) / float64(len(queryArtists))
+
+	// Sources disagree on how many guests to credit, so the primary artist alone counts for most
+	if queryArtists[0] == candidateArtists[0] && overlap < 0.8 {
+		overlap = 0.8
+	}
+	return overlap
+}
+
+// normalizeArtistName normalizes an artist name, reading "&" as "and" and ignoring a leading "The"
+func normalizeArtistName(name string) string {
+	name = shared.NormalizeText(strings.ReplaceAll(name, "&", " and "))
+	return strings.TrimPrefix(name, "the ")
+}
+
+// PrimaryArtist returns the first artist of a credit such as "Calvin Harris, Dua Lipa"
+func PrimaryArtist(credit string) string {
+	names := artistSeparator.Split(credit, 2)
+	return strings.TrimSpace(names[0])
+}
+
+// albumName returns the album of a track, which search results keep in AlbumTitle
+func albumName(track shared.Track) string {
+	if track.Album != "" {
+		return track.Album
+	}
+	return track.AlbumTitle
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dab-downloader/internal/shared"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"Here Comes the Sun - Remastered 2009": "here comes the sun",
+		"Heroes (2017 Remaster)":               "heroes",
+		"One Kiss (feat. Dua Lipa)":            "one kiss",
+		"One Kiss [with Dua Lipa]":             "one kiss",
+		"Don't Stop Me Now":                    "dont stop me now",
+		"Sweet Child O' Mine":                  "sweet child o mine",
+		"Levels - Radio Edit":                  "levels radio edit",
+		"Song - Live at Wembley":               "song live at wembley",
+		"Hello feat. Someone":                  "hello",
+		"Mr. Brightside":                       "mr brightside",
+	}
+	for title, expected := range tests {
+		if got := normalizeTitle(title); got != expected {
+			t.Errorf("normalizeTitle(%q) = %q, expected %q", title, got, expected)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	matcher := NewMatcher(0)
+	query := shared.Track{Title: "One Kiss (with Dua Lipa)", Artist: "Calvin Harris, Dua Lipa", Album: "One Kiss", Duration: 214}
+
+	tests := []struct {
+		name      string
+		candidate shared.Track
+		accept    bool
+	}{
+		{"same track", shared.Track{Title: "One Kiss", Artist: "Calvin Harris & Dua Lipa", AlbumTitle: "One Kiss", Duration: 215}, true},
+		{"primary artist only", shared.Track{Title: "One Kiss", Artist: "Calvin Harris", Duration: 214}, true},
+		{"cover version", shared.Track{Title: "One Kiss", Artist: "Piano Tribute Players", AlbumTitle: "Piano Hits", Duration: 180}, false},
+		{"different song", shared.Track{Title: "Summer", Artist: "Calvin Harris", Duration: 223}, false},
+		{"wrong edit length", shared.Track{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", AlbumTitle: "One Kiss", Duration: 400}, true},
+	}
+	for _, test := range tests {
+		score := matcher.Score(query, test.candidate)
+		if (score >= matcher.Threshold) != test.accept {
+			t.Errorf("%s: score %.2f, expected accepted=%v", test.name, score, test.accept)
+		}
+	}
+
+	// Different durations lower the score even when everything else agrees
+	exact := matcher.Score(query, shared.Track{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", AlbumTitle: "One Kiss", Duration: 214})
+	long := matcher.Score(query, shared.Track{Title: "One Kiss", Artist: "Calvin Harris, Dua Lipa", AlbumTitle: "One Kiss", Duration: 400})
+	if long >= exact {
+		t.Errorf("Expected a duration mismatch to lower the score, got %.2f and %.2f", exact, long)
+	}
+}
+
+func TestScoreRejectsOtherRecordings(t *testing.T) {
+	matcher := NewMatcher(0)
+	query := shared.Track{Title: "Song", Artist: "Band", Album: "Album", Duration: 200}
+
+	candidates := []shared.Track{
+		{ID: "live", Title: "Song - Live at Wembley", Artist: "Band", AlbumTitle: "Live at Wembley", Duration: 260},
+		{ID: "edit", Title: "Song - Radio Edit", Artist: "Band", AlbumTitle: "Hits", Duration: 170},
+	}
+	if match := matcher.Best(query, candidates); match.Accepted() {
+		t.Errorf("Expected no other recording to be accepted, got %s with score %.2f", match.Candidate.Title, match.Score)
+	}
+
+	remaster := shared.Track{Title: "Song - Remastered 2011", Artist: "Band", AlbumTitle: "Album", Duration: 201}
+	if score := matcher.Score(query, remaster); score < matcher.Threshold {
+		t.Errorf("Expected a remaster to be accepted, got %.2f", score)
+	}
+}
+
+func TestBestAlbum(t *testing.T) {
+	matcher := NewMatcher(0)
+	candidates := []shared.Album{
+		{ID: "tribute", Title: "Abbey Road", Artist: "Piano Tribute Players"},
+		{ID: "remaster", Title: "Abbey Road (Remastered 2019)", Artist: "The Beatles"},
+	}
+	album, match := matcher.BestAlbum("Abbey Road (Remastered)", "The Beatles", candidates)
+	if !match.Accepted() || album == nil || album.ID != "remaster" || match.Type != MatchTypeAlbum {
+		t.Errorf("Expected the remastered album, got %+v (%+v)", album, match)
+	}
+
+	album, match = matcher.BestAlbum("Abbey Road", "The Beatles", candidates[:1])
+	if match.Status != MatchLowConfidence || album == nil {
+		t.Errorf("Expected the tribute album as a near miss, got %+v (%+v)", album, match)
+	}
+}
+
+func TestBestArtist(t *testing.T) {
+	matcher := NewMatcher(0)
+	candidates := []shared.Artist{{ID: 1, Name: "Queen Tribute Band"}, {ID: 2, Name: "Simon and Garfunkel"}}
+
+	artist, match := matcher.BestArtist("Simon & Garfunkel", candidates)
+	if !match.Accepted() || artist == nil || shared.IdToString(artist.ID) != "2" {
+		t.Errorf("Expected Simon and Garfunkel, got %+v (%+v)", artist, match)
+	}
+
+	artist, match = matcher.BestArtist("Queen", candidates)
+	if match.Accepted() || match.Status != MatchLowConfidence {
+		t.Errorf("Expected no accepted match for Queen, got %+v (%+v)", artist, match)
+	}
+}
+
+func TestScoreISRC(t *testing.T) {
+	matcher := NewMatcher(0)
+	query := shared.Track{Title: "Heroes", Artist: "David Bowie", ISRC: "GBAYE7700012"}
+	candidate := shared.Track{Title: "\"Heroes\" - 2017 Remaster", Artist: "Bowie", ISRC: "gbaye7700012"}
+	if score := matcher.Score(query, candidate); score != 1 {
+		t.Errorf("Expected an ISRC match to score 1, got %.2f", score)
+	}
+}
+
+// fakeSearcher returns canned results per query
+type fakeSearcher struct {
+	results map[string][]shared.Track
+	queries []string
+}
+
+func (s *fakeSearcher) Search(ctx context.Context, query string, searchType string, limit int, debug bool) (*shared.SearchResults, error) {
+	s.queries = append(s.queries, query)
+	return &shared.SearchResults{Tracks: s.results[query]}, nil
+}
+
+func TestFindTrack(t *testing.T) {
+	searcher := &fakeSearcher{results: map[string][]shared.Track{
+		"Levels Avicii": {{ID: 1, Title: "Levels (Tribute)", Artist: "Cover Band"}},
+		"Levels":        {{ID: 2, Title: "Levels - Radio Edit", Artist: "Avicii", Duration: 200}},
+	}}
+	matcher := NewMatcher(0)
+
+	match, err := matcher.FindTrack(context.Background(), searcher, shared.Track{Title: "Levels", Artist: "Avicii", Duration: 199}, false)
+	if err != nil {
+		t.Fatalf("FindTrack failed: %v", err)
+	}
+	if !match.Accepted() || shared.IdToString(match.Candidate.ID) != "2" {
+		t.Errorf("Expected track 2 from the title-only search, got %+v", match)
+	}
+	if strings.Join(searcher.queries, "|") != "Levels Avicii|Levels" {
+		t.Errorf("Unexpected queries: %v", searcher.queries)
+	}
+
+	match, err = matcher.FindTrack(context.Background(), searcher, shared.Track{Title: "Unknown", Artist: "Nobody"}, false)
+	if err != nil || match.Status != MatchNotFound {
+		t.Errorf("Expected no match, got %+v, %v", match, err)
+	}
+}
+
+func TestMatchReport(t *testing.T) {
+	matcher := NewMatcher(0.9)
+	report := NewMatchReport(matcher.Threshold)
+
+	query := shared.Track{Title: "One Kiss", Artist: "Calvin Harris"}
+	report.Add(matcher.Best(query, []shared.Track{{ID: "a", Title: "One Kiss", Artist: "Calvin Harris"}}))
+	report.Add(matcher.Best(query, []shared.Track{{ID: "b", Title: "One Kiss", Artist: "Tribute Band"}}))
+	report.Add(matcher.Best(shared.Track{Title: "Missing", Artist: "Nobody"}, nil))
+
+	if report.Matched() != 1 || len(report.Problems()) != 2 {
+		t.Fatalf("Expected 1 match and 2 problems, got %d and %d", report.Matched(), len(report.Problems()))
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var saved struct {
+		Matched  int `json:"matched"`
+		Problems []struct {
+			Status      string `json:"status"`
+			CandidateID string `json:"candidate_id"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if saved.Problems[0].Status != "low_confidence" || saved.Problems[0].CandidateID != "b" || saved.Problems[1].Status != "unmatched" {
+		t.Errorf("Unexpected report contents: %s", data)
+	}
+}
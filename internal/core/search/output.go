@@ -0,0 +1,154 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"dab-downloader/internal/shared"
+)
+
+// Output formats for printing search results without prompting
+const (
+	OutputJSON  = "json"
+	OutputTSV   = "tsv"
+	OutputTable = "table"
+)
+
+// OutputFormats lists the supported output formats
+var OutputFormats = []string{OutputJSON, OutputTSV, OutputTable}
+
+// Result is one search result in a flat form for scripts. Title holds the name for artists.
+type Result struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Artist  string `json:"artist,omitempty"`
+	Album   string `json:"album,omitempty"`
+	Year    string `json:"year,omitempty"`
+	Quality string `json:"quality,omitempty"` // Bit depth/sampling rate, e.g. "24/96"
+	HiRes   bool   `json:"hiRes,omitempty"`
+	Tracks  int    `json:"tracks,omitempty"`
+}
+
+// IsOutputFormat reports whether the format is one of OutputFormats
+func IsOutputFormat(format string) bool {
+	for _, supported := range OutputFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// FlattenResults converts search results to rows: artists first, then albums, then tracks
+func FlattenResults(results *shared.SearchResults) []Result {
+	if results == nil {
+		return nil
+	}
+
+	rows := make([]Result, 0, len(results.Artists)+len(results.Albums)+len(results.Tracks))
+	for _, artist := range results.Artists {
+		rows = append(rows, Result{
+			Type:  "artist",
+			ID:    shared.IdToString(artist.ID),
+			Title: artist.Name,
+		})
+	}
+	for _, album := range results.Albums {
+		tracks := album.TotalTracks
+		if tracks == 0 {
+			tracks = len(album.Tracks)
+		}
+		rows = append(rows, Result{
+			Type:    "album",
+			ID:      album.ID,
+			Title:   album.Title,
+			Artist:  album.Artist,
+			Year:    releaseYear(album.Year, album.ReleaseDate),
+			Quality: qualityText(album.AudioQuality),
+			HiRes:   shared.IsHiRes(album.AudioQuality),
+			Tracks:  tracks,
+		})
+	}
+	for _, track := range results.Tracks {
+		albumName := track.AlbumTitle
+		if albumName == "" {
+			albumName = track.Album
+		}
+		rows = append(rows, Result{
+			Type:    "track",
+			ID:      shared.IdToString(track.ID),
+			Title:   track.Title,
+			Artist:  track.Artist,
+			Album:   albumName,
+			Year:    releaseYear(track.Year, track.ReleaseDate),
+			Quality: qualityText(track.AudioQuality),
+			HiRes:   shared.IsHiRes(track.AudioQuality),
+		})
+	}
+	return rows
+}
+
+// WriteResults prints the results in one of OutputFormats. TSV has no header and one result per
+// line, so the ID can be cut from the second column.
+func WriteResults(w io.Writer, results *shared.SearchResults, format string) error {
+	rows := FlattenResults(results)
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case OutputTSV:
+		for _, row := range rows {
+			if _, err := fmt.Fprintln(w, strings.Join(row.fields(), "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputTable:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "TYPE\tID\tTITLE\tARTIST\tALBUM\tYEAR\tQUALITY\tTRACKS")
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row.fields(), "\t"))
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: %s)", format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// fields returns the columns of the TSV and table output
+func (r Result) fields() []string {
+	tracks := ""
+	if r.Tracks > 0 {
+		tracks = strconv.Itoa(r.Tracks)
+	}
+	fields := []string{r.Type, r.ID, r.Title, r.Artist, r.Album, r.Year, r.Quality, tracks}
+	for i, field := range fields {
+		fields[i] = strings.Join(strings.Fields(field), " ") // Tabs and line breaks would break the columns
+	}
+	return fields
+}
+
+// releaseYear returns the year field, or the year of a date such as "2019-05-31"
+func releaseYear(year, releaseDate string) string {
+	if year != "" {
+		return year
+	}
+	if len(releaseDate) >= 4 {
+		return releaseDate[:4]
+	}
+	return ""
+}
+
+// qualityText formats the audio quality as "bit depth/sampling rate", e.g. "16/44.1"
+func qualityText(quality shared.AudioQuality) string {
+	if quality.MaximumBitDepth == 0 && quality.MaximumSamplingRate == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s", quality.MaximumBitDepth, strconv.FormatFloat(quality.MaximumSamplingRate, 'f', -1, 64))
+}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"dab-downloader/internal/shared"
+)
+
+func testSearchResults() *shared.SearchResults {
+	return &shared.SearchResults{
+		Artists: []shared.Artist{{ID: float64(12345678), Name: "Daft Punk"}},
+		Albums: []shared.Album{{
+			ID:           "0724384960650",
+			Title:        "Discovery",
+			Artist:       "Daft Punk",
+			ReleaseDate:  "2001-03-12",
+			TotalTracks:  14,
+			AudioQuality: shared.AudioQuality{MaximumBitDepth: 24, MaximumSamplingRate: 44.1},
+		}},
+		Tracks: []shared.Track{{
+			ID:           float64(42),
+			Title:        "One More Time\t(Edit)",
+			Artist:       "Daft Punk",
+			AlbumTitle:   "Discovery",
+			Year:         "2001",
+			AudioQuality: shared.AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1},
+		}},
+	}
+}
+
+func TestWriteResultsTSV(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteResults(&output, testSearchResults(), OutputTSV); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	expected := "artist\t12345678\tDaft Punk\t\t\t\t\t\n" +
+		"album\t0724384960650\tDiscovery\tDaft Punk\t\t2001\t24/44.1\t14\n" +
+		"track\t42\tOne More Time (Edit)\tDaft Punk\tDiscovery\t2001\t16/44.1\t\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, output.String())
+	}
+}
+
+func TestWriteResultsJSON(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteResults(&output, testSearchResults(), OutputJSON); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	var rows []Result
+	if err := json.Unmarshal(output.Bytes(), &rows); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(rows))
+	}
+	album := rows[1]
+	if album.Type != "album" || album.ID != "0724384960650" || album.Tracks != 14 || !album.HiRes {
+		t.Errorf("Unexpected album result: %+v", album)
+	}
+	if rows[2].HiRes {
+		t.Errorf("Expected CD quality track not to be hi-res: %+v", rows[2])
+	}
+}
+
+func TestWriteResultsTable(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteResults(&output, testSearchResults(), OutputTable); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "TYPE") {
+		t.Fatalf("Expected a header and 3 rows, got:\n%s", output.String())
+	}
+	if !strings.Contains(lines[2], "Discovery") || !strings.Contains(lines[2], "24/44.1") {
+		t.Errorf("Unexpected album row: %q", lines[2])
+	}
+
+	if err := WriteResults(&output, testSearchResults(), "xml"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -2,7 +2,6 @@ package search
 
 import (
 	"context"
-	"fmt"
 	
 	"dab-downloader/internal/shared"
 	"dab-downloader/internal/api/dab"
@@ -12,7 +11,7 @@ import (
 func HandleSearch(ctx context.Context, api *dab.DabAPI, query string, searchType string, debug bool, auto bool, cfg *config.Config) ([]interface{}, []string, error) {
 	shared.ColorInfo.Printf("🔎 Searching for '%s' (type: %s)...", query, searchType)
 
-	results, err := api.Search(ctx, query, searchType, 10, debug)
+	results, err := api.Search(ctx, query, searchType, DefaultPageSize, debug)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -39,76 +38,8 @@ func HandleSearch(ctx context.Context, api *dab.DabAPI, query string, searchType
 		return selectedItems, itemTypes, nil
 	}
 
-	shared.ColorInfo.Printf("Found %d results:\n", totalResults)
-
-	// Display results
-	counter := 1
-	if len(results.Artists) > 0 {
-		shared.ColorInfo.Println("\n--- Artists ---")
-		for _, artist := range results.Artists {
-			fmt.Printf("%d. %s\n", counter, artist.Name)
-			counter++
-		}
-	}
-	if len(results.Albums) > 0 {
-		shared.ColorInfo.Println("\n--- Albums ---")
-		for _, album := range results.Albums {
-			prefix := fmt.Sprintf("%d. ", counter)
-			formattedLine := shared.FormatAlbumWithBitrate(prefix, album.Title, album.Artist, "", album.AudioQuality)
-			fmt.Println(formattedLine)
-			counter++
-		}
-	}
-	if len(results.Tracks) > 0 {
-		shared.ColorInfo.Println("\n--- Tracks ---")
-		for _, track := range results.Tracks {
-			prefix := fmt.Sprintf("%d. ", counter)
-			// Use AlbumTitle if available, fallback to Album
-			albumName := track.AlbumTitle
-			if albumName == "" {
-				albumName = track.Album
-			}
-			formattedLine := shared.FormatTrackWithBitrate(prefix, track.Title, track.Artist, albumName, track.AudioQuality)
-			fmt.Println(formattedLine)
-			counter++
-		}
-	}
-
-	// Prompt for selection
-	selectionStr := shared.GetUserInput("\nEnter numbers to download (e.g., '1,3,5-7' or 'q' to quit)", "")
-	if selectionStr == "q" || selectionStr == "" {
-		return nil, nil, nil
-	}
-
-	selectedIndices, err := shared.ParseSelectionInput(selectionStr, totalResults)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid selection: %w", err)
-	}
-
-	var selectedItems []interface{}
-	var itemTypes []string
-
-	for _, selectedIndex := range selectedIndices {
-		index := selectedIndex - 1
-		if index < len(results.Artists) {
-			selectedItems = append(selectedItems, results.Artists[index])
-			itemTypes = append(itemTypes, "artist")
-		} else {
-			index -= len(results.Artists)
-			if index < len(results.Albums) {
-				selectedItems = append(selectedItems, results.Albums[index])
-				itemTypes = append(itemTypes, "album")
-			} else {
-				index -= len(results.Albums)
-				if index < len(results.Tracks) {
-					selectedItems = append(selectedItems, results.Tracks[index])
-					itemTypes = append(itemTypes, "track")
-				} else {
-					return nil, nil, fmt.Errorf("invalid index %d after parsing", selectedIndex)
-				}
-			}
-		}
-	}
-
-	return selectedItems, itemTypes, nil
-}
\ No newline at end of file
+	shared.ColorInfo.Printf("Found %d results\n", totalResults)
+	browser := NewBrowser(api, query, searchType, cfg, debug)
+	browser.results = results
+	return browser.Run(ctx)
+}
@@ -6,6 +6,7 @@ import (
 
 	"dab-downloader/internal/config"
 	"dab-downloader/internal/shared"
+	"github.com/cheggaaa/pb/v3"
 )
 
 // APIClient defines the interface for DAB API interactions
@@ -13,6 +14,9 @@ type APIClient interface {
 	// Search performs a search query and returns results
 	Search(ctx context.Context, query, searchType string, limit int, debug bool) (*shared.SearchResults, error)
 	
+	// SearchPage performs a search query starting after the first offset results
+	SearchPage(ctx context.Context, query, searchType string, limit, offset int, debug bool) (*shared.SearchResults, error)
+	
 	// GetAlbum retrieves detailed album information by ID
 	GetAlbum(ctx context.Context, albumID string) (*shared.Album, error)
 	
@@ -54,6 +58,9 @@ type DownloadService interface {
 	
 	// DownloadTracks downloads multiple tracks
 	DownloadTracks(ctx context.Context, tracks []shared.Track, album *shared.Album, config *config.Config, debug bool, format string, bitrate string) (*shared.DownloadStats, error)
+	
+	// SetProgressFunc sets the function that provides the progress bar for each track download
+	SetProgressFunc(progressFunc func(track shared.Track) *pb.ProgressBar)
 }
 
 // SearchService defines the interface for search operations
@@ -63,6 +70,9 @@ type SearchService interface {
 	
 	// Search performs a raw search without user interaction
 	Search(ctx context.Context, query string, searchType string, limit int, debug bool) (*shared.SearchResults, error)
+	
+	// SearchPage performs a raw search starting after the first offset results
+	SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error)
 }
 
 // ConfigService defines the interface for configuration management
@@ -93,6 +103,24 @@ type SpotifyService interface {
 	
 	// GetAlbumTracks retrieves tracks from a Spotify album
 	GetAlbumTracks(albumURL string) ([]shared.SpotifyTrack, string, error)
+	
+	// GetTrack retrieves a single track from a Spotify track URL
+	GetTrack(trackURL string) (shared.SpotifyTrack, error)
+	
+	// GetArtist retrieves an artist from a Spotify artist URL
+	GetArtist(artistURL string) (shared.SpotifyArtist, error)
+	
+	// AuthenticateUser logs in as a Spotify user for access to the user's library
+	AuthenticateUser(ctx context.Context) error
+	
+	// GetLikedTracks retrieves the user's Liked Songs
+	GetLikedTracks() ([]shared.SpotifyTrack, error)
+	
+	// GetSavedAlbums retrieves the albums saved in the user's library
+	GetSavedAlbums() ([]shared.SpotifyAlbum, error)
+	
+	// GetFollowedArtists retrieves the artists the user follows
+	GetFollowedArtists() ([]shared.SpotifyArtist, error)
 }
 
 // NavidromeService defines the interface for Navidrome integration
@@ -100,14 +128,17 @@ type NavidromeService interface {
 	// Authenticate authenticates with Navidrome server
 	Authenticate() error
 	
-	// CreatePlaylist creates a playlist in Navidrome
-	CreatePlaylist(name string, tracks []shared.Track) error
+	// CreatePlaylist creates a playlist in Navidrome and returns its ID
+	CreatePlaylist(name string, tracks []shared.Track) (string, error)
 	
 	// GetPlaylists retrieves all playlists from Navidrome
 	GetPlaylists() ([]shared.NavidromePlaylist, error)
 	
 	// AddTracksToPlaylist adds tracks to an existing playlist
 	AddTracksToPlaylist(playlistID string, tracks []shared.Track) error
+	
+	// FinishSession rescans the library after downloads and retries unmatched playlist tracks
+	FinishSession(ctx context.Context) error
 }
 
 // UpdaterService defines the interface for application updates
@@ -144,6 +175,9 @@ type FileSystemService interface {
 	
 	// SanitizeFileName sanitizes a filename for the file system
 	SanitizeFileName(filename string) string
+	
+	// RemoveStaleTempFiles removes temporary files left in the download location by an interrupted run
+	RemoveStaleTempFiles() (int, error)
 }
 
 // LoggerService defines the interface for logging operations
@@ -184,6 +218,9 @@ type WarningCollectorService interface {
 	// GetWarningCount returns the total number of warnings
 	GetWarningCount() int
 	
+	// Warnings returns a copy of the collected warnings
+	Warnings() []shared.Warning
+	
 	// PrintSummary prints a formatted summary of all warnings
 	PrintSummary()
 }
@@ -2,22 +2,27 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"dab-downloader/internal/api/dab"
 	"dab-downloader/internal/api/spotify"
 	"dab-downloader/internal/api/navidrome"
 	"dab-downloader/internal/config"
+	"dab-downloader/internal/core/discography"
 	"dab-downloader/internal/core/downloader"
 	"dab-downloader/internal/core/search"
 	"dab-downloader/internal/core/updater"
 	"dab-downloader/internal/interfaces"
 	"dab-downloader/internal/shared"
+	"github.com/cheggaaa/pb/v3"
 )
 
 // ============================================================================
@@ -27,6 +32,9 @@ import (
 const (
 	MaxParallelWorkers = 10
 	DefaultParallelism = 1
+	
+	// Temporary files older than this are left over from an interrupted run
+	StaleTempFileAge = time.Hour
 )
 
 // ServiceContainer holds all application services
@@ -59,14 +67,19 @@ func NewServiceContainer(cfg *config.Config, httpClient *http.Client) *ServiceCo
 	// Create API clients
 	apiClient := dab.NewDabAPI(cfg.APIURL, cfg.DownloadLocation, httpClient)
 	spotifyClient := spotify.NewSpotifyClient(cfg.SpotifyClientID, cfg.SpotifyClientSecret)
-	navidromeClient := navidrome.NewNavidromeClient(cfg.NavidromeURL, cfg.NavidromeUsername, cfg.NavidromePassword)
+	spotifyClient.RedirectURL = cfg.SpotifyRedirectURL
+	spotifyClient.TokenFile = cfg.SpotifyTokenFile
+	navidromeOptions := navidrome.DefaultClientOptions()
+	navidromeOptions.APIKey = cfg.NavidromeAPIKey
+	navidromeOptions.HTTPClient = httpClient
+	navidromeClient := navidrome.NewNavidromeClientWithOptions(cfg.NavidromeURL, cfg.NavidromeUsername, cfg.NavidromePassword, navidromeOptions)
 	
 	// Create business logic services
 	configService := NewConfigService()
 	downloadService := NewDownloadService(apiClient, fileSystem, logger, warningCollector)
 	searchService := NewSearchService(apiClient)
 	updaterService := NewUpdaterService(httpClient)
-	metadataService := NewMetadataService(warningCollector)
+	metadataService := NewMetadataService(cfg, warningCollector)
 	conversionService := NewConversionService()
 	
 	return &ServiceContainer{
@@ -75,7 +88,7 @@ func NewServiceContainer(cfg *config.Config, httpClient *http.Client) *ServiceCo
 		DownloadService:  downloadService,
 		SearchService:    searchService,
 		SpotifyService:   NewSpotifyServiceWrapper(spotifyClient),
-		NavidromeService: NewNavidromeServiceWrapper(navidromeClient),
+		NavidromeService: NewNavidromeServiceWrapper(navidromeClient, cfg),
 		UpdaterService:   updaterService,
 		FileSystem:       fileSystem,
 		Logger:           logger,
@@ -148,6 +161,9 @@ type DownloadService struct {
 	logger           interfaces.LoggerService
 	warningCollector *shared.WarningCollector
 	downloader       *downloader.TrackDownloader
+	progressFunc     func(track shared.Track) *pb.ProgressBar
+	progress         *downloader.ProgressDisplay
+	tempCleanup      sync.Once
 }
 
 func NewDownloadService(apiClient interfaces.APIClient, fileSystem interfaces.FileSystemService, logger interfaces.LoggerService, warningCollector interfaces.WarningCollectorService) *DownloadService {
@@ -164,9 +180,16 @@ func NewDownloadService(apiClient interfaces.APIClient, fileSystem interfaces.Fi
 		logger:           logger,
 		warningCollector: warningCollectorService,
 		downloader:       trackDownloader,
+		progress:         downloader.NewProgressDisplay(),
 	}
 }
 
+// SetProgressFunc sets the function that provides the progress bar for each track download.
+// The function may return nil for no progress bar.
+func (ds *DownloadService) SetProgressFunc(progressFunc func(track shared.Track) *pb.ProgressBar) {
+	ds.progressFunc = progressFunc
+}
+
 // ============================================================================
 // 4.1 Public Download Methods
 // ============================================================================
@@ -187,30 +210,44 @@ func (ds *DownloadService) DownloadArtist(ctx context.Context, artistID string,
 		return nil, fmt.Errorf("failed to get artist: %w", err)
 	}
 	
+	// Collapse explicit/clean, remastered and deluxe editions of the same release
+	policy := ""
+	if cfg != nil {
+		policy = cfg.DuplicatePolicy
+	}
+	releases := discography.GroupDuplicates(artist.Albums, policy)
+	albums := discography.Preferred(releases)
+	if collapsed := len(artist.Albums) - len(albums); collapsed > 0 {
+		ds.logger.Info("Collapsed %d duplicate editions (%s)", collapsed, policyName(policy))
+	}
+	
 	// Check if user explicitly provided a filter
 	var filteredAlbums []shared.Album
 	var usedCustomSelection bool
 	
 	// If a specific filter was provided, use it directly
 	if filter != "" && filter != "all" {
-		filteredAlbums = ds.filterAlbumsByType(artist.Albums, filter)
+		filteredAlbums, err = ds.filterAlbums(albums, filter, cfg)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		// Present menu options to user
-		selectedFilter, cancelled := ds.presentDownloadMenu(artist.Albums)
+		selectedFilter, cancelled := ds.presentDownloadMenu(releases)
 		if cancelled {
 			return &shared.DownloadStats{}, shared.ErrDownloadCancelled
 		}
 		
 		if selectedFilter == "custom" {
 			// Use existing custom selection logic
-			filteredAlbums = ds.selectCustomAlbums(artist.Albums)
+			filteredAlbums = ds.selectCustomAlbums(albums)
 			usedCustomSelection = true
 			if len(filteredAlbums) == 0 {
 				return &shared.DownloadStats{}, shared.ErrNoItemsSelected
 			}
 		} else {
 			// Simulate custom selection for menu options 1-4
-			filteredAlbums = ds.simulateCustomSelection(artist.Albums, selectedFilter)
+			filteredAlbums = ds.simulateCustomSelection(albums, selectedFilter)
 			usedCustomSelection = true
 			if len(filteredAlbums) == 0 {
 				return &shared.DownloadStats{}, shared.ErrNoItemsSelected
@@ -262,6 +299,7 @@ func (ds *DownloadService) DownloadTracks(ctx context.Context, tracks []shared.T
 	ds.downloader.SetDebugMode(debug)
 	ds.logger.SetDebugMode(debug)
 	ds.apiClient.SetDebugMode(debug)
+	ds.removeStaleTempFiles()
 	
 	// Pre-populate MusicBrainz metadata
 	if album != nil && len(tracks) > 0 {
@@ -285,6 +323,16 @@ func (ds *DownloadService) DownloadTracks(ctx context.Context, tracks []shared.T
 	return ds.downloadTracksWithParallelism(ctx, tracks, album, coverData, cfg, debug, format, bitrate)
 }
 
+// removeStaleTempFiles cleans up the temporary files left behind by a crash or a killed process, once
+// before the first download. The message goes to stderr so that script output on stdout stays clean.
+func (ds *DownloadService) removeStaleTempFiles() {
+	ds.tempCleanup.Do(func() {
+		if removed, err := ds.fileSystem.RemoveStaleTempFiles(); err == nil && removed > 0 {
+			shared.ColorInfo.Fprintf(os.Stderr, "🧹 Removed %d leftover temporary files from %s\n", removed, ds.fileSystem.config.DownloadLocation)
+		}
+	})
+}
+
 // ============================================================================
 // 4.2 Info Retrieval Methods
 // ============================================================================
@@ -328,7 +376,13 @@ func (ds *DownloadService) getParallelism(cfg *config.Config) int {
 	return parallelism
 }
 
-func (ds *DownloadService) presentDownloadMenu(albums []shared.Album) (string, bool) {
+func (ds *DownloadService) presentDownloadMenu(releases []discography.Group) (string, bool) {
+	albums := discography.Preferred(releases)
+	editions := make(map[string][]shared.Album)
+	for _, release := range releases {
+		editions[release.Preferred.ID] = release.Duplicates
+	}
+	
 	// Count albums by type
 	albumCount := 0
 	epCount := 0
@@ -384,6 +438,9 @@ func (ds *DownloadService) presentDownloadMenu(albums []shared.Album) (string, b
 				prefix := fmt.Sprintf("│ %2d. ", counter)
 				formattedLine := shared.FormatAlbumWithTrackCountProfessional(prefix, album.Title, album.Artist, album.ReleaseDate, trackCountStr, album.AudioQuality)
 				fmt.Println(formattedLine)
+				if duplicates := editions[album.ID]; len(duplicates) > 0 {
+					shared.ColorDim.Printf("│       ↳ %s\n", formatEditions(duplicates))
+				}
 				counter++
 			}
 		}
@@ -537,6 +594,30 @@ func (ds *DownloadService) groupAndSortAlbums(albums []shared.Album) map[string]
 	return grouped
 }
 
+// formatEditions describes the editions collapsed into a release, e.g. "2 other editions: Title (Clean), Title (2011 Remaster)"
+func formatEditions(duplicates []shared.Album) string {
+	titles := make([]string, len(duplicates))
+	for i, album := range duplicates {
+		titles[i] = album.Title
+		if len(album.ReleaseDate) >= 4 {
+			titles[i] += " [" + album.ReleaseDate[:4] + "]"
+		}
+	}
+	label := "other editions"
+	if len(duplicates) == 1 {
+		label = "other edition"
+	}
+	return fmt.Sprintf("%d %s: %s", len(duplicates), label, strings.Join(titles, ", "))
+}
+
+// policyName returns the duplicate policy in effect for messages
+func policyName(policy string) string {
+	if policy == "" {
+		return discography.DefaultPolicy
+	}
+	return policy
+}
+
 // getAlbumTrackCount attempts to get the track count for an album
 func (ds *DownloadService) getAlbumTrackCount(album shared.Album) int {
 	// First, try TotalTracks field
@@ -641,8 +722,14 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 		totalStats := &shared.DownloadStats{}
 		
 		for _, album := range albums {
+			if ctx.Err() != nil {
+				break
+			}
 			ds.logger.Info("🎵 Starting album download for: %s by %s", album.Title, album.Artist)
 			albumStats, err := ds.DownloadAlbum(ctx, album.ID, cfg, debug, format, bitrate)
+			if err != nil && ctx.Err() != nil {
+				break
+			}
 			if err != nil {
 				ds.logger.Error("❌ Failed to download album %s: %v", album.Title, err)
 				totalStats.FailedCount++
@@ -652,10 +739,10 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 			
 			// Merge stats
 			if albumStats != nil {
-				totalStats.SuccessCount += albumStats.SuccessCount
-				totalStats.FailedCount += albumStats.FailedCount
-				totalStats.SkippedCount += albumStats.SkippedCount
-				totalStats.FailedItems = append(totalStats.FailedItems, albumStats.FailedItems...)
+				ds.mergeStats(totalStats, albumStats)
+			}
+			if ctx.Err() != nil {
+				break
 			}
 			
 			ds.logger.Success("✅ Album download completed for %s", album.Title)
@@ -675,7 +762,13 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 			totalStats := &shared.DownloadStats{}
 			
 			for _, album := range albums {
+				if ctx.Err() != nil {
+					break
+				}
 				albumStats, err := ds.DownloadAlbum(ctx, album.ID, cfg, debug, format, bitrate)
+				if err != nil && ctx.Err() != nil {
+					break
+				}
 				if err != nil {
 					ds.logger.Error("Failed to download album %s: %v", album.Title, err)
 					totalStats.FailedCount++
@@ -684,10 +777,7 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 				}
 				
 				if albumStats != nil {
-					totalStats.SuccessCount += albumStats.SuccessCount
-					totalStats.FailedCount += albumStats.FailedCount
-					totalStats.SkippedCount += albumStats.SkippedCount
-					totalStats.FailedItems = append(totalStats.FailedItems, albumStats.FailedItems...)
+					ds.mergeStats(totalStats, albumStats)
 				}
 			}
 			
@@ -702,8 +792,14 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 			for i := 0; i < maxWorkers; i++ {
 				go func() {
 					for album := range albumChan {
+						if ctx.Err() != nil {
+							statsChan <- &shared.DownloadStats{}
+							continue
+						}
 						stats, err := ds.DownloadAlbum(ctx, album.ID, cfg, debug, format, bitrate)
-						if err != nil {
+						if err != nil && ctx.Err() != nil {
+							stats = &shared.DownloadStats{}
+						} else if err != nil {
 							stats = &shared.DownloadStats{
 								FailedCount: 1,
 								FailedItems: []string{album.Title},
@@ -726,10 +822,7 @@ func (ds *DownloadService) downloadAlbumsUnified(ctx context.Context, albums []s
 			for i := 0; i < len(albums); i++ {
 				stats := <-statsChan
 				if stats != nil {
-					totalStats.SuccessCount += stats.SuccessCount
-					totalStats.FailedCount += stats.FailedCount
-					totalStats.SkippedCount += stats.SkippedCount
-					totalStats.FailedItems = append(totalStats.FailedItems, stats.FailedItems...)
+					ds.mergeStats(totalStats, stats)
 				}
 			}
 			
@@ -820,7 +913,7 @@ func (ds *DownloadService) saveCoverArtToFile(coverData []byte, album *shared.Al
 	}
 
 	// Write the cover art data to cover.jpg
-	if err := os.WriteFile(coverPath, coverData, 0644); err != nil {
+	if err := shared.WriteFileAtomic(coverPath, coverData, 0644); err != nil {
 		return fmt.Errorf("failed to write cover art file: %w", err)
 	}
 
@@ -844,6 +937,12 @@ func (ds *DownloadService) downloadTracksWithParallelism(ctx context.Context, tr
 	stats := &shared.DownloadStats{}
 	trackWorkers := ds.getParallelism(cfg)
 	
+	// Show per-track progress unless a caller such as the TUI provides its own bars
+	if ds.progressFunc == nil {
+		ds.progress.Begin(len(tracks))
+		defer ds.progress.End()
+	}
+	
 	if debug && len(tracks) > 1 {
 		ds.logger.Debug("Using parallelism setting: %d workers for %d tracks in album '%s'", trackWorkers, len(tracks), album.Title)
 	}
@@ -857,33 +956,18 @@ func (ds *DownloadService) downloadTracksWithParallelism(ctx context.Context, tr
 	return stats, nil
 }
 
-func (ds *DownloadService) filterAlbumsByType(albums []shared.Album, filter string) []shared.Album {
-	if filter == "all" || filter == "" {
-		return albums
+// filterAlbums applies a filter expression, which may use the presets of the configuration
+func (ds *DownloadService) filterAlbums(albums []shared.Album, expression string, cfg *config.Config) ([]shared.Album, error) {
+	var presets map[string]string
+	if cfg != nil {
+		presets = cfg.FilterPresets
 	}
 	
-	filters := strings.Split(strings.ToLower(filter), ",")
-	var filtered []shared.Album
-	
-	for _, album := range albums {
-		albumType := strings.ToLower(album.Type)
-		for _, f := range filters {
-			f = strings.TrimSpace(f)
-			if ds.matchesFilter(f, albumType) {
-				filtered = append(filtered, album)
-				break
-			}
-		}
+	filter, err := discography.ParseFilter(expression, presets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
 	}
-	
-	return filtered
-}
-
-func (ds *DownloadService) matchesFilter(filter, albumType string) bool {
-	return filter == albumType ||
-		(filter == "albums" && albumType == "album") ||
-		(filter == "eps" && albumType == "ep") ||
-		(filter == "singles" && albumType == "single")
+	return filter.Apply(albums), nil
 }
 
 // ============================================================================
@@ -896,15 +980,29 @@ func (ds *DownloadService) matchesFilter(filter, albumType string) bool {
 
 func (ds *DownloadService) downloadTracksSequentially(ctx context.Context, tracks []shared.Track, album *shared.Album, coverData []byte, cfg *config.Config, debug bool, format string, bitrate string, stats *shared.DownloadStats) {
 	for _, track := range tracks {
+		if ctx.Err() != nil {
+			stats.CancelledCount++
+			ds.trackFinished(nil)
+			continue
+		}
+		
 		outputPath := ds.fileSystem.GetDownloadPathWithTrack(track, album, format, cfg)
 		
 		if ds.fileSystem.FileExists(outputPath) {
 			ds.logger.Info("Skipping %s - already exists", track.Title)
 			stats.SkippedCount++
+			stats.Files = append(stats.Files, outputPath)
+			ds.trackFinished(nil)
 			continue
 		}
 		
-		_, err := downloader.DownloadTrack(ctx, ds.apiClient, track, album, outputPath, coverData, nil, debug, format, bitrate, cfg, ds.warningCollector)
+		bar := ds.progressBar(track)
+		filePath, err := downloader.DownloadTrack(ctx, ds.apiClient, track, album, outputPath, coverData, bar, debug, format, bitrate, cfg, ds.warningCollector)
+		ds.trackFinished(bar)
+		if err != nil && ctx.Err() != nil {
+			stats.CancelledCount++
+			continue
+		}
 		if err != nil {
 			ds.logger.Error("Failed to download %s: %v", track.Title, err)
 			stats.FailedCount++
@@ -913,6 +1011,7 @@ func (ds *DownloadService) downloadTracksSequentially(ctx context.Context, track
 		}
 		
 		stats.SuccessCount++
+		stats.Files = append(stats.Files, filePath)
 	}
 }
 
@@ -942,6 +1041,13 @@ func (ds *DownloadService) trackWorker(ctx context.Context, workerID int, jobs <
 	for track := range jobs {
 		result := trackDownloadResult{track: track}
 		
+		if ctx.Err() != nil {
+			result.cancelled = true
+			ds.trackFinished(nil)
+			results <- result
+			continue
+		}
+		
 		outputPath := ds.fileSystem.GetDownloadPathWithTrack(track, album, format, cfg)
 		
 		if ds.fileSystem.FileExists(outputPath) {
@@ -949,18 +1055,25 @@ func (ds *DownloadService) trackWorker(ctx context.Context, workerID int, jobs <
 				ds.logger.Debug("Worker %d: Skipping %s - already exists", workerID, track.Title)
 			}
 			result.skipped = true
+			result.path = outputPath
+			ds.trackFinished(nil)
 			results <- result
 			continue
 		}
 		
-		_, err := downloader.DownloadTrack(ctx, ds.apiClient, track, album, outputPath, coverData, nil, debug, format, bitrate, cfg, ds.warningCollector)
-		if err != nil {
+		bar := ds.progressBar(track)
+		filePath, err := downloader.DownloadTrack(ctx, ds.apiClient, track, album, outputPath, coverData, bar, debug, format, bitrate, cfg, ds.warningCollector)
+		ds.trackFinished(bar)
+		if err != nil && ctx.Err() != nil {
+			result.cancelled = true
+		} else if err != nil {
 			if debug {
 				ds.logger.Error("Worker %d: Failed to download %s: %v", workerID, track.Title, err)
 			}
 			result.err = err
 		} else {
 			result.success = true
+			result.path = filePath
 			if debug {
 				ds.logger.Debug("Worker %d: Successfully downloaded %s", workerID, track.Title)
 			}
@@ -1022,10 +1135,27 @@ func (ds *DownloadService) metadataWorker(ctx context.Context, workerID int, job
 // ============================================================================
 
 type trackDownloadResult struct {
-	track   shared.Track
-	success bool
-	skipped bool
-	err     error
+	track     shared.Track
+	success   bool
+	skipped   bool
+	cancelled bool
+	path      string
+	err       error
+}
+
+// progressBar returns the progress bar for a track download, or nil when there is none
+func (ds *DownloadService) progressBar(track shared.Track) *pb.ProgressBar {
+	if ds.progressFunc == nil {
+		return ds.progress.Bar(track)
+	}
+	return ds.progressFunc(track)
+}
+
+// trackFinished counts a downloaded, skipped or failed track on the progress display
+func (ds *DownloadService) trackFinished(bar *pb.ProgressBar) {
+	if ds.progressFunc == nil {
+		ds.progress.Done(bar)
+	}
 }
 
 func (ds *DownloadService) mergeStats(total, addition *shared.DownloadStats) {
@@ -1033,13 +1163,19 @@ func (ds *DownloadService) mergeStats(total, addition *shared.DownloadStats) {
 	total.SkippedCount += addition.SkippedCount
 	total.FailedCount += addition.FailedCount
 	total.FailedItems = append(total.FailedItems, addition.FailedItems...)
+	total.Files = append(total.Files, addition.Files...)
+	total.CancelledCount += addition.CancelledCount
 }
 
 func (ds *DownloadService) updateStatsFromResult(stats *shared.DownloadStats, result trackDownloadResult) {
 	if result.skipped {
 		stats.SkippedCount++
+		stats.Files = append(stats.Files, result.path)
 	} else if result.success {
 		stats.SuccessCount++
+		stats.Files = append(stats.Files, result.path)
+	} else if result.cancelled {
+		stats.CancelledCount++
 	} else {
 		stats.FailedCount++
 		stats.FailedItems = append(stats.FailedItems, result.track.Title)
@@ -1068,6 +1204,10 @@ func (ss *SearchService) Search(ctx context.Context, query string, searchType st
 	return ss.apiClient.Search(ctx, query, searchType, limit, debug)
 }
 
+func (ss *SearchService) SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error) {
+	return ss.apiClient.SearchPage(ctx, query, searchType, limit, offset, debug)
+}
+
 // ============================================================================
 // 6. Spotify Service Wrapper
 // ============================================================================
@@ -1102,14 +1242,74 @@ func (ssw *SpotifyServiceWrapper) GetAlbumTracks(albumURL string) ([]shared.Spot
 	return ssw.convertSpotifyTracks(tracks), name, nil
 }
 
+func (ssw *SpotifyServiceWrapper) GetTrack(trackURL string) (shared.SpotifyTrack, error) {
+	track, err := ssw.client.GetTrack(trackURL)
+	if err != nil {
+		return shared.SpotifyTrack{}, err
+	}
+	
+	return ssw.convertSpotifyTracks([]spotify.SpotifyTrack{track})[0], nil
+}
+
+func (ssw *SpotifyServiceWrapper) GetArtist(artistURL string) (shared.SpotifyArtist, error) {
+	artist, err := ssw.client.GetArtist(artistURL)
+	if err != nil {
+		return shared.SpotifyArtist{}, err
+	}
+	
+	return shared.SpotifyArtist{Name: artist.Name}, nil
+}
+
+func (ssw *SpotifyServiceWrapper) AuthenticateUser(ctx context.Context) error {
+	return ssw.client.AuthenticateUser(ctx)
+}
+
+func (ssw *SpotifyServiceWrapper) GetLikedTracks() ([]shared.SpotifyTrack, error) {
+	tracks, err := ssw.client.GetLikedTracks()
+	if err != nil {
+		return nil, err
+	}
+	
+	return ssw.convertSpotifyTracks(tracks), nil
+}
+
+func (ssw *SpotifyServiceWrapper) GetSavedAlbums() ([]shared.SpotifyAlbum, error) {
+	albums, err := ssw.client.GetSavedAlbums()
+	if err != nil {
+		return nil, err
+	}
+	
+	sharedAlbums := make([]shared.SpotifyAlbum, len(albums))
+	for i, album := range albums {
+		sharedAlbums[i] = shared.SpotifyAlbum{Name: album.Name, Artist: album.Artist}
+	}
+	return sharedAlbums, nil
+}
+
+func (ssw *SpotifyServiceWrapper) GetFollowedArtists() ([]shared.SpotifyArtist, error) {
+	artists, err := ssw.client.GetFollowedArtists()
+	if err != nil {
+		return nil, err
+	}
+	
+	sharedArtists := make([]shared.SpotifyArtist, len(artists))
+	for i, artist := range artists {
+		sharedArtists[i] = shared.SpotifyArtist{Name: artist.Name}
+	}
+	return sharedArtists, nil
+}
+
 func (ssw *SpotifyServiceWrapper) convertSpotifyTracks(tracks []spotify.SpotifyTrack) []shared.SpotifyTrack {
 	sharedTracks := make([]shared.SpotifyTrack, len(tracks))
 	for i, track := range tracks {
 		sharedTracks[i] = shared.SpotifyTrack{
 			Name:        track.Name,
 			Artist:      track.Artist,
+			Artists:     track.Artists,
 			AlbumName:   track.AlbumName,
 			AlbumArtist: track.AlbumArtist,
+			ISRC:        track.ISRC,
+			Duration:    track.Duration,
 		}
 	}
 	return sharedTracks
@@ -1120,27 +1320,148 @@ func (ssw *SpotifyServiceWrapper) convertSpotifyTracks(tracks []spotify.SpotifyT
 // ============================================================================
 
 type NavidromeServiceWrapper struct {
-	client *navidrome.NavidromeClient
+	client  *navidrome.NavidromeClient
+	rescan  bool
+	mu      sync.Mutex
+	pending []pendingPlaylistTracks
 }
 
-func NewNavidromeServiceWrapper(client *navidrome.NavidromeClient) *NavidromeServiceWrapper {
-	return &NavidromeServiceWrapper{client: client}
+// pendingPlaylistTracks are tracks not yet in the library when they were added to a playlist
+type pendingPlaylistTracks struct {
+	playlistID string
+	tracks     []shared.Track
+}
+
+func NewNavidromeServiceWrapper(client *navidrome.NavidromeClient, cfg *config.Config) *NavidromeServiceWrapper {
+	return &NavidromeServiceWrapper{
+		client: client,
+		rescan: cfg != nil && cfg.NavidromeRescan,
+	}
+}
+
+// Client returns the underlying Navidrome client for operations beyond the service interface
+func (nsw *NavidromeServiceWrapper) Client() *navidrome.NavidromeClient {
+	return nsw.client
 }
 
 func (nsw *NavidromeServiceWrapper) Authenticate() error {
-	return fmt.Errorf("Navidrome authentication not implemented")
+	return nsw.client.Authenticate()
+}
+
+// ensureAuthenticated authenticates on first use
+func (nsw *NavidromeServiceWrapper) ensureAuthenticated() error {
+	if nsw.client.IsAuthenticated() {
+		return nil
+	}
+	return nsw.Authenticate()
 }
 
-func (nsw *NavidromeServiceWrapper) CreatePlaylist(name string, tracks []shared.Track) error {
-	return fmt.Errorf("Navidrome playlist creation not implemented")
+func (nsw *NavidromeServiceWrapper) CreatePlaylist(name string, tracks []shared.Track) (string, error) {
+	if err := nsw.ensureAuthenticated(); err != nil {
+		return "", err
+	}
+
+	playlistID, err := nsw.client.CreatePlaylist(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(tracks) > 0 {
+		if err := nsw.AddTracksToPlaylist(playlistID, tracks); err != nil {
+			return playlistID, err
+		}
+	}
+
+	return playlistID, nil
 }
 
 func (nsw *NavidromeServiceWrapper) GetPlaylists() ([]shared.NavidromePlaylist, error) {
-	return nil, fmt.Errorf("Navidrome playlist retrieval not implemented")
+	if err := nsw.ensureAuthenticated(); err != nil {
+		return nil, err
+	}
+
+	playlists, err := nsw.client.GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]shared.NavidromePlaylist, 0, len(playlists))
+	for _, playlist := range playlists {
+		result = append(result, shared.NavidromePlaylist{ID: playlist.ID, Name: playlist.Name})
+	}
+	return result, nil
 }
 
+// AddTracksToPlaylist adds the tracks found in the library. Tracks without a match are
+// reported through a *navidrome.UnmatchedTracksError after the others were added, and
+// kept for FinishSession to retry.
 func (nsw *NavidromeServiceWrapper) AddTracksToPlaylist(playlistID string, tracks []shared.Track) error {
-	return fmt.Errorf("Navidrome add tracks not implemented")
+	if err := nsw.ensureAuthenticated(); err != nil {
+		return err
+	}
+
+	songIDs, unmatched, err := nsw.client.MatchTracks(tracks)
+	if err != nil {
+		return err
+	}
+
+	if len(songIDs) > 0 {
+		if err := nsw.client.AddTracksToPlaylist(playlistID, songIDs); err != nil {
+			return err
+		}
+	}
+
+	if len(unmatched) > 0 {
+		nsw.mu.Lock()
+		nsw.pending = append(nsw.pending, pendingPlaylistTracks{playlistID: playlistID, tracks: unmatched})
+		nsw.mu.Unlock()
+		return &navidrome.UnmatchedTracksError{Tracks: unmatched}
+	}
+	return nil
+}
+
+// FinishSession is the post-download hook. When NavidromeRescan is enabled it scans the library
+// for the new files and adds the tracks that could not be matched before to their playlists.
+func (nsw *NavidromeServiceWrapper) FinishSession(ctx context.Context) error {
+	if !nsw.rescan {
+		return nil
+	}
+	if err := nsw.ensureAuthenticated(); err != nil {
+		return err
+	}
+
+	scanCtx, cancel := context.WithTimeout(ctx, navidrome.DefaultScanTimeout)
+	defer cancel()
+	if _, err := nsw.client.RescanLibrary(scanCtx, navidrome.DefaultScanPollInterval); err != nil {
+		return fmt.Errorf("library rescan failed: %w", err)
+	}
+
+	return nsw.retryPendingTracks()
+}
+
+// retryPendingTracks matches the pending tracks again, keeping those still missing
+func (nsw *NavidromeServiceWrapper) retryPendingTracks() error {
+	nsw.mu.Lock()
+	pending := nsw.pending
+	nsw.pending = nil
+	nsw.mu.Unlock()
+
+	var stillMissing []shared.Track
+	for _, entry := range pending {
+		err := nsw.AddTracksToPlaylist(entry.playlistID, entry.tracks)
+		var unmatchedErr *navidrome.UnmatchedTracksError
+		switch {
+		case errors.As(err, &unmatchedErr):
+			stillMissing = append(stillMissing, unmatchedErr.Tracks...)
+		case err != nil:
+			return err
+		}
+	}
+
+	if len(stillMissing) > 0 {
+		return &navidrome.UnmatchedTracksError{Tracks: stillMissing}
+	}
+	return nil
 }
 
 // ============================================================================
@@ -1221,6 +1542,14 @@ func (fss *FileSystemService) ValidateDownloadLocation(path string) error {
 	return nil
 }
 
+// RemoveStaleTempFiles removes the temporary files an interrupted run left in the download location
+func (fss *FileSystemService) RemoveStaleTempFiles() (int, error) {
+	if fss.config == nil {
+		return 0, nil
+	}
+	return shared.RemoveStaleTempFiles(fss.config.DownloadLocation, StaleTempFileAge)
+}
+
 func (fss *FileSystemService) SanitizeFileName(filename string) string {
 	return shared.SanitizeFileName(filename)
 }
@@ -1324,25 +1653,33 @@ func (fss *FileSystemService) ProcessNamingMaskForFolder(mask string, track shar
 // ============================================================================
 
 type MetadataService struct {
+	processor        *downloader.MetadataProcessor
 	warningCollector *shared.WarningCollector
 }
 
-func NewMetadataService(warningCollector interfaces.WarningCollectorService) *MetadataService {
+func NewMetadataService(cfg *config.Config, warningCollector interfaces.WarningCollectorService) *MetadataService {
+	processor := downloader.NewMetadataProcessor()
+	processor.ApplyConfig(cfg)
+
 	return &MetadataService{
+		processor:        processor,
 		warningCollector: warningCollector.(*shared.WarningCollector),
 	}
 }
 
 func (ms *MetadataService) AddMetadata(filePath string, track shared.Track, album *shared.Album, coverData []byte, totalTracks int) error {
-	return fmt.Errorf("metadata service not implemented")
+	if strings.EqualFold(filepath.Ext(filePath), ".flac") {
+		return ms.processor.AddMetadata(filePath, track, album, coverData, totalTracks, ms.warningCollector)
+	}
+	return ms.processor.AddLossyMetadata(filePath, track, album, totalTracks, ms.warningCollector)
 }
 
 func (ms *MetadataService) ExtractMetadata(filePath string) (*shared.Track, error) {
-	return nil, fmt.Errorf("metadata extraction not implemented")
+	return downloader.ExtractTrackMetadata(filePath)
 }
 
 func (ms *MetadataService) ValidateMetadata(filePath string, expectedTrack shared.Track) error {
-	return fmt.Errorf("metadata validation not implemented")
+	return downloader.ValidateTrackMetadata(filePath, expectedTrack, nil)
 }
 
 type ConversionService struct{}
@@ -1,10 +1,20 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"dab-downloader/internal/api/navidrome"
 	"dab-downloader/internal/config"
 	"dab-downloader/internal/shared"
 )
@@ -115,6 +125,28 @@ func TestFileSystemService(t *testing.T) {
 	}
 }
 
+func TestStaleTempFilesRemovedOnlyForDownloads(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, ".01 - Song.dab-tmp-123.flac")
+	if err := os.WriteFile(stale, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	container := NewServiceContainer(&config.Config{DownloadLocation: root}, http.DefaultClient)
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("building the services removed the temporary file: %v", err)
+	}
+
+	container.DownloadService.(*DownloadService).removeStaleTempFiles()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temporary file was not removed before downloading")
+	}
+}
+
 func TestNamingMasks(t *testing.T) {
 	cfg := &config.Config{
 		DownloadLocation: "./test-downloads",
@@ -318,4 +350,60 @@ func TestParallelismConfiguration(t *testing.T) {
 	if container == nil {
 		t.Error("Service container should be created successfully with parallelism config")
 	}
-}
\ No newline at end of file
+}
+
+func TestNavidromeFinishSession(t *testing.T) {
+	var mu sync.Mutex
+	scanned := false
+	var added []string
+
+	// A Subsonic server whose library only contains the song after a scan
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		query := r.URL.Query()
+		if query.Get("f") == "xml" {
+			w.Write([]byte(`<subsonic-response status="ok" version="1.16.1"></subsonic-response>`))
+			return
+		}
+
+		response := map[string]interface{}{"status": "ok"}
+		switch strings.TrimSuffix(path.Base(r.URL.Path), ".view") {
+		case "startScan", "getScanStatus":
+			scanned = true
+			response["scanStatus"] = map[string]interface{}{"scanning": false, "count": 1}
+		case "search3":
+			songs := []map[string]interface{}{}
+			if scanned {
+				songs = append(songs, map[string]interface{}{"id": "s1", "title": "Video Games", "artist": "Lana Del Rey"})
+			}
+			response["searchResult3"] = map[string]interface{}{"song": songs}
+		case "updatePlaylist":
+			added = append(added, query["songIdToAdd"]...)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"subsonic-response": response})
+	}))
+	defer server.Close()
+
+	client := navidrome.NewNavidromeClient(server.URL, "user", "secret")
+	wrapper := NewNavidromeServiceWrapper(client, &config.Config{NavidromeRescan: true})
+
+	tracks := []shared.Track{{Title: "Video Games", Artist: "Lana Del Rey"}}
+	var unmatchedErr *navidrome.UnmatchedTracksError
+	if err := wrapper.AddTracksToPlaylist("pl1", tracks); !errors.As(err, &unmatchedErr) {
+		t.Fatalf("Expected the track to be unmatched before the scan, got %v", err)
+	}
+
+	if err := wrapper.FinishSession(context.Background()); err != nil {
+		t.Fatalf("Expected the pending track to be added after the scan, got %v", err)
+	}
+	if len(added) != 1 || added[0] != "s1" {
+		t.Errorf("Expected song s1 to be added, got %v", added)
+	}
+
+	// Nothing is left to retry
+	if len(wrapper.pending) != 0 {
+		t.Errorf("Expected no pending tracks, got %+v", wrapper.pending)
+	}
+}
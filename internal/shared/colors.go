@@ -14,6 +14,7 @@ var (
 	ColorError   = color.New(color.FgRed)
 	ColorPrompt  = color.New(color.FgBlue, color.Bold)
 	ColorDebug   = color.New(color.FgMagenta, color.Faint) // Added for debug messages
+	ColorDim     = color.New(color.Faint)                  // Secondary details in listings
 )
 
 // InitializeColors initializes color output based on TTY detection
@@ -0,0 +1,117 @@
+package shared
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tempFileMarker is part of the name of every file that is still being written
+const tempFileMarker = ".dab-tmp-"
+
+// CreateTempFile creates an empty temporary file in the directory of path, e.g.
+// ".01 - Song.dab-tmp-1234.flac" for "01 - Song.flac". The name keeps the extension so tools
+// that go by it, such as ffmpeg, treat it like the final file.
+func CreateTempFile(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	return os.CreateTemp(dir, "."+strings.TrimSuffix(base, ext)+tempFileMarker+"*"+ext)
+}
+
+// IsTempFile reports whether a file name was created by CreateTempFile
+func IsTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, tempFileMarker)
+}
+
+// CommitFile flushes a finished temporary file to disk and renames it to path
+func CommitFile(tempPath, path string, perm os.FileMode) error {
+	f, err := os.OpenFile(tempPath, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to flush %s: %w", tempPath, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tempPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", filepath.Base(path), err)
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// WriteFileAtomic writes data to path through a temporary file in the same directory, so path
+// never holds a partly written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := CreateTempFile(path)
+	if err != nil {
+		return err
+	}
+	tempPath := f.Name()
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = CommitFile(tempPath, path, perm)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// RemoveStaleTempFiles removes the temporary files under root that were last written longer ago
+// than the given age, left behind by a crash or a killed process. It returns how many it removed.
+func RemoveStaleTempFiles(root string, age time.Duration) (int, error) {
+	if root == "" {
+		return 0, nil
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-age)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Skip unreadable directories
+		}
+		if entry.IsDir() || !IsTempFile(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			removed++
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return removed, err
+}
+
+// syncDir flushes a rename to disk. Not every platform supports syncing a directory, so errors
+// are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
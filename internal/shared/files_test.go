@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cover.jpg")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "new" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestCreateTempFileKeepsExtension(t *testing.T) {
+	f, err := CreateTempFile(filepath.Join(t.TempDir(), "01 - Song.flac"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	name := filepath.Base(f.Name())
+	if !IsTempFile(name) || filepath.Ext(name) != ".flac" {
+		t.Errorf("unexpected temporary file name %q", name)
+	}
+	if IsTempFile("01 - Song.flac") {
+		t.Error("a regular file was taken for a temporary one")
+	}
+}
+
+func TestRemoveStaleTempFiles(t *testing.T) {
+	root := t.TempDir()
+	album := filepath.Join(root, "Artist", "Album")
+	if err := os.MkdirAll(album, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := filepath.Join(album, ".01 - Song.dab-tmp-123.flac")
+	fresh := filepath.Join(album, ".02 - Song.dab-tmp-456.flac")
+	track := filepath.Join(album, "01 - Song.flac")
+	for _, path := range []string{stale, fresh, track} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(track, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := RemoveStaleTempFiles(root, time.Hour)
+	if err != nil || removed != 1 {
+		t.Fatalf("removed %d files, err %v; want 1", removed, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temporary file was not removed")
+	}
+	for _, path := range []string{fresh, track} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should be kept: %v", filepath.Base(path), err)
+		}
+	}
+
+	if removed, err := RemoveStaleTempFiles(filepath.Join(root, "missing"), time.Hour); err != nil || removed != 0 {
+		t.Errorf("missing root: removed %d, err %v", removed, err)
+	}
+}
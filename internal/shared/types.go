@@ -34,6 +34,7 @@ type Track struct {
 	AlbumID       string       `json:"albumId"`                   // Added AlbumID field
 	MusicBrainzID string       `json:"musicbrainzId,omitempty"`   // MusicBrainz ID for the track
 	AudioQuality  AudioQuality `json:"audioQuality,omitempty"`
+	ParentalWarning bool       `json:"parentalWarning,omitempty"` // Explicit lyrics
 }
 
 type Artist struct {
@@ -70,6 +71,7 @@ type Album struct {
 	TotalDiscs    int          `json:"totalDiscs,omitempty"`
 	MusicBrainzID string       `json:"musicbrainzId,omitempty"` // MusicBrainz ID for the album
 	AudioQuality  AudioQuality `json:"audioQuality,omitempty"`
+	ParentalWarning bool       `json:"parentalWarning,omitempty"` // Explicit edition
 }
 
 // API response structures
@@ -119,14 +121,29 @@ type DownloadStats struct {
 	SkippedCount int
 	FailedCount  int
 	FailedItems  []string
+	Files        []string // Final paths of the downloaded and already present tracks
+	// Tracks not downloaded because the download was interrupted
+	CancelledCount int
 }
 
 // Spotify types
 type SpotifyTrack struct {
 	Name        string
-	Artist      string
+	Artist      string   // All credited artists joined with ", "
+	Artists     []string // Credited artists, primary artist first
 	AlbumName   string
 	AlbumArtist string
+	ISRC        string
+	Duration    int // Seconds
+}
+
+type SpotifyAlbum struct {
+	Name   string
+	Artist string
+}
+
+type SpotifyArtist struct {
+	Name string
 }
 
 // Navidrome types
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,6 +13,7 @@ import (
 	"log"
 	"net/http"
 	"time"
+	"unicode"
 
 	"github.com/mattn/go-isatty"
 )
@@ -73,6 +75,29 @@ func RetryWithBackoff(maxRetries int, initialDelaySec int, fn func() error) erro
 	return fmt.Errorf("failed after %d attempts: %w", maxRetries, err)
 }
 
+// RetryWithBackoffContext is RetryWithBackoff that stops retrying as soon as the context is cancelled
+func RetryWithBackoffContext(ctx context.Context, maxRetries int, initialDelaySec int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		delay := time.Duration(initialDelaySec) * time.Second * (1 << attempt)
+		jitter := time.Duration(rand.Intn(100)) * time.Millisecond
+		select {
+		case <-time.After(delay + jitter):
+		case <-ctx.Done():
+			return err
+		}
+	}
+	return fmt.Errorf("failed after %d attempts: %w", maxRetries, err)
+}
+
 // RetryWithBackoffForHTTP retries HTTP requests with smart error handling
 func RetryWithBackoffForHTTP(maxRetries int, initialDelay time.Duration, maxDelay time.Duration, fn func() error) error {
 	return RetryWithBackoffForHTTPWithDebug(maxRetries, initialDelay, maxDelay, fn, false)
@@ -334,6 +359,90 @@ func CheckFFmpeg() bool {
 	return err == nil
 }
 
+// IsHiRes reports whether an audio quality is better than CD quality (16 bit, up to 48 kHz)
+func IsHiRes(quality AudioQuality) bool {
+	return quality.IsHiRes || quality.MaximumBitDepth > 16 || quality.MaximumSamplingRate > 48
+}
+
+// NormalizeText lowercases text, drops apostrophes and turns other punctuation into spaces, so that
+// names from different sources can be compared
+func NormalizeText(text string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if r == '\'' || r == '’' {
+			return -1
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+// TextSimilarity compares two normalized texts: 1 for equal texts, 0.9 when one contains the other,
+// otherwise the Dice coefficient of their words
+func TextSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return 0.9
+	}
+	return WordSimilarity(a, b)
+}
+
+// WordSimilarity is the Dice coefficient of the words of two normalized texts
+func WordSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	inB := make(map[string]int, len(wordsB))
+	for _, word := range wordsB {
+		inB[word]++
+	}
+	common := 0
+	for _, word := range wordsA {
+		if inB[word] > 0 {
+			inB[word]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wordsA)+len(wordsB))
+}
+
+// ParseRange parses "A-B", "A-", "-B" or "A" into bounds, where 0 means unbounded. Reversed bounds
+// are swapped.
+func ParseRange(value string) (int, int, error) {
+	lowText, highText, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	if !isRange {
+		highText = lowText
+	}
+
+	var bounds [2]int
+	for i, text := range []string{lowText, highText} {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		bound, err := strconv.Atoi(text)
+		if err != nil || bound < 0 {
+			return 0, 0, fmt.Errorf("%q is not a number", text)
+		}
+		bounds[i] = bound
+	}
+	if bounds[0] == 0 && bounds[1] == 0 {
+		return 0, 0, fmt.Errorf("no bounds given")
+	}
+	if bounds[0] > 0 && bounds[1] > 0 && bounds[0] > bounds[1] {
+		bounds[0], bounds[1] = bounds[1], bounds[0]
+	}
+	return bounds[0], bounds[1], nil
+}
+
 // FormatBitrateInfo formats audio quality information with colors
 func FormatBitrateInfo(audioQuality AudioQuality) string {
 	if audioQuality.MaximumSamplingRate == 0 && audioQuality.MaximumBitDepth == 0 {
@@ -358,7 +467,7 @@ func FormatBitrateInfo(audioQuality AudioQuality) string {
 	bitrateInfo := fmt.Sprintf("[%s/%d]", samplingRateStr, audioQuality.MaximumBitDepth)
 	
 	// Color the bitrate info based on quality
-	if audioQuality.IsHiRes || audioQuality.MaximumBitDepth >= 24 || audioQuality.MaximumSamplingRate > 48.0 {
+	if IsHiRes(audioQuality) {
 		return ColorSuccess.Sprint(bitrateInfo) // Green for hi-res
 	} else if audioQuality.MaximumBitDepth >= 16 {
 		return ColorWarning.Sprint(bitrateInfo) // Yellow for CD quality
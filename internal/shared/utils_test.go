@@ -0,0 +1,72 @@
+package shared
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value     string
+		low, high int
+	}{
+		{"2010-2019", 2010, 2019},
+		{"2019-2010", 2010, 2019},
+		{"2015-", 2015, 0},
+		{"-2000", 0, 2000},
+		{"12", 12, 12},
+		{" 5 - 11 ", 5, 11},
+	}
+	for _, test := range tests {
+		low, high, err := ParseRange(test.value)
+		if err != nil {
+			t.Errorf("ParseRange(%q) failed: %v", test.value, err)
+			continue
+		}
+		if low != test.low || high != test.high {
+			t.Errorf("ParseRange(%q) = %d, %d, want %d, %d", test.value, low, high, test.low, test.high)
+		}
+	}
+
+	for _, value := range []string{"", "-", "abc", "2010-soon"} {
+		if _, _, err := ParseRange(value); err == nil {
+			t.Errorf("ParseRange(%q) succeeded", value)
+		}
+	}
+}
+
+func TestIsHiRes(t *testing.T) {
+	tests := []struct {
+		quality AudioQuality
+		want    bool
+	}{
+		{AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 44.1}, false},
+		{AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 48}, false},
+		{AudioQuality{MaximumBitDepth: 20, MaximumSamplingRate: 48}, true},
+		{AudioQuality{MaximumBitDepth: 16, MaximumSamplingRate: 96}, true},
+		{AudioQuality{IsHiRes: true}, true},
+	}
+	for _, test := range tests {
+		if got := IsHiRes(test.quality); got != test.want {
+			t.Errorf("IsHiRes(%+v) = %v, want %v", test.quality, got, test.want)
+		}
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	if got := NormalizeText("Don't Stop Me Now!  (Live)"); got != "dont stop me now live" {
+		t.Errorf("NormalizeText() = %q", got)
+	}
+
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"abbey road", "abbey road", 1},
+		{"abbey road", "abbey road remastered 2019", 0.9},
+		{"one two three", "one two four", 2.0 / 3},
+		{"one", "", 0},
+	}
+	for _, test := range tests {
+		if got := TextSimilarity(test.a, test.b); got != test.want {
+			t.Errorf("TextSimilarity(%q, %q) = %.3f, want %.3f", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // WarningType represents different types of warnings
@@ -16,6 +17,8 @@ const (
 	CoverArtMetadataWarning
 	AlbumFetchWarning
 	TrackSkippedWarning
+	AcoustIDMatchWarning
+	AcoustIDLowScoreWarning
 )
 
 // Warning represents a single warning with context
@@ -30,6 +33,7 @@ type Warning struct {
 type WarningCollector struct {
 	warnings []Warning
 	enabled  bool
+	mu       sync.Mutex // Warnings are added from parallel download workers
 }
 
 // NewWarningCollector creates a new warning collector
@@ -52,6 +56,8 @@ func (wc *WarningCollector) AddWarning(warningType WarningType, context, message
 		Context: context,
 		Details: details,
 	}
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
 	wc.warnings = append(wc.warnings, warning)
 }
 
@@ -88,12 +94,27 @@ func (wc *WarningCollector) AddTrackSkippedWarning(trackPath string) {
 	wc.AddWarning(TrackSkippedWarning, trackPath, "Track already exists", "")
 }
 
+// AddAcoustIDMatchWarning records a track identified by its AcoustID fingerprint along with the match confidence
+func (wc *WarningCollector) AddAcoustIDMatchWarning(artist, title string, score float64) {
+	context := fmt.Sprintf("%s - %s (confidence %.0f%%)", artist, title, score*100)
+	wc.AddWarning(AcoustIDMatchWarning, context, "Identified by AcoustID fingerprint", fmt.Sprintf("score %.2f", score))
+}
+
+// AddAcoustIDLowScoreWarning records a track whose best AcoustID match was rejected for scoring below the minimum
+func (wc *WarningCollector) AddAcoustIDLowScoreWarning(artist, title string, score, minScore float64) {
+	context := fmt.Sprintf("%s - %s (confidence %.0f%%)", artist, title, score*100)
+	wc.AddWarning(AcoustIDLowScoreWarning, context, "AcoustID match rejected", fmt.Sprintf("score %.2f, minimum %.2f", score, minScore))
+}
+
 // RemoveWarningsByTypeAndContext removes warnings of a specific type and context
 func (wc *WarningCollector) RemoveWarningsByTypeAndContext(warningType WarningType, context string) {
 	if !wc.enabled {
 		return
 	}
 	
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
 	var filteredWarnings []Warning
 	for _, warning := range wc.warnings {
 		// Keep warnings that don't match the type and context
@@ -110,18 +131,30 @@ func (wc *WarningCollector) RemoveMusicBrainzReleaseWarning(artist, album string
 	wc.RemoveWarningsByTypeAndContext(MusicBrainzReleaseWarning, context)
 }
 
+// Warnings returns a copy of the collected warnings in the order they were added
+func (wc *WarningCollector) Warnings() []Warning {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return append([]Warning(nil), wc.warnings...)
+}
+
 // HasWarnings returns true if there are any warnings
 func (wc *WarningCollector) HasWarnings() bool {
-	return len(wc.warnings) > 0
+	return wc.GetWarningCount() > 0
 }
 
 // GetWarningCount returns the total number of warnings
 func (wc *WarningCollector) GetWarningCount() int {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
 	return len(wc.warnings)
 }
 
 // GetWarningsByType returns warnings grouped by type
 func (wc *WarningCollector) GetWarningsByType() map[WarningType][]Warning {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
 	grouped := make(map[WarningType][]Warning)
 	for _, warning := range wc.warnings {
 		grouped[warning.Type] = append(grouped[warning.Type], warning)
@@ -135,7 +168,7 @@ func (wc *WarningCollector) PrintSummary() {
 		return
 	}
 
-	ColorWarning.Printf("\n⚠️  Warning Summary (%d warnings):\n", len(wc.warnings))
+	ColorWarning.Printf("\n⚠️  Warning Summary (%d warnings):\n", wc.GetWarningCount())
 	ColorWarning.Println(strings.Repeat("─", 50))
 
 	grouped := wc.GetWarningsByType()
@@ -202,6 +235,10 @@ func (wc *WarningCollector) getWarningTypeTitle(warningType WarningType) string
 		return "Album Information Fetch Failures"
 	case TrackSkippedWarning:
 		return "Tracks Skipped (Already Exist)"
+	case AcoustIDMatchWarning:
+		return "Tracks Identified by AcoustID Fingerprint (No ISRC Match)"
+	case AcoustIDLowScoreWarning:
+		return "AcoustID Matches Rejected for a Low Score"
 	default:
 		return "Other Warnings"
 	}
@@ -0,0 +1,573 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+	"github.com/cheggaaa/pb/v3"
+)
+
+const (
+	searchLimit  = 25  // Results per type
+	maxWorkers   = 10  // Same limit as the download service
+	logLines     = 200 // Lines of output kept for the log pane
+	redrawPeriod = 100 * time.Millisecond
+)
+
+// searchTypes are the search types cycled with the t key
+var searchTypes = []string{"all", "artist", "album", "track"}
+
+// ErrNoTerminal is returned by Run when there is no terminal to draw on. Callers fall back to
+// the prompt-based flow.
+var ErrNoTerminal = errors.New("no interactive terminal")
+
+// Searcher runs the searches of the terminal UI
+type Searcher interface {
+	SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error)
+}
+
+// Downloader is the part of the download service used by the terminal UI
+type Downloader interface {
+	GetArtistInfo(ctx context.Context, artistID string, config *config.Config, debug bool) (*shared.Artist, error)
+	GetAlbumInfo(ctx context.Context, albumID string, config *config.Config, debug bool) (*shared.Album, error)
+	DownloadTracks(ctx context.Context, tracks []shared.Track, album *shared.Album, config *config.Config, debug bool, format string, bitrate string) (*shared.DownloadStats, error)
+	SetProgressFunc(progressFunc func(track shared.Track) *pb.ProgressBar)
+}
+
+// WarningSource provides the warnings collected during downloads
+type WarningSource interface {
+	Warnings() []shared.Warning
+}
+
+// Options configures the terminal UI
+type Options struct {
+	Config     *config.Config
+	Debug      bool
+	Query      string // Searched when the UI opens
+	SearchType string
+}
+
+// pane is the part of the screen that receives movement keys
+type pane int
+
+const (
+	browserPane pane = iota
+	queuePane
+)
+
+// App is the full-screen terminal UI: a search box, a browser tree of results with checkboxes,
+// the download queue with a progress bar per track, and a pane with warnings and log output.
+type App struct {
+	searcher   Searcher
+	downloader Downloader
+	warnings   WarningSource
+	options    Options
+	format     string
+	bitrate    string
+
+	ctx    context.Context
+	events chan func()
+	queue  *Queue
+	log    *logBuffer
+
+	roots        []*node
+	cursor       int
+	scroll       int
+	queueCursor  int
+	queueScroll  int
+	focus        pane
+	editing      bool
+	query        []rune
+	searchType   string
+	searching    bool
+	status       string
+	confirmQuit  bool
+	seenWarnings int
+	lastFrame    string
+}
+
+// NewApp creates the terminal UI
+func NewApp(searcher Searcher, downloader Downloader, warnings WarningSource, options Options) *App {
+	if options.Config == nil {
+		options.Config = &config.Config{}
+	}
+	searchType := options.SearchType
+	if !containsString(searchTypes, searchType) {
+		searchType = "all"
+	}
+
+	app := &App{
+		searcher:   searcher,
+		downloader: downloader,
+		warnings:   warnings,
+		options:    options,
+		format:     options.Config.Format,
+		bitrate:    options.Config.Bitrate,
+		ctx:        context.Background(),
+		events:     make(chan func(), 64),
+		queue:      NewQueue(),
+		log:        newLogBuffer(logLines),
+		query:      []rune(options.Query),
+		searchType: searchType,
+		editing:    options.Query == "",
+	}
+	if app.format == "" {
+		app.format = "flac"
+	}
+	if app.bitrate == "" {
+		app.bitrate = "320"
+	}
+	return app
+}
+
+// Run draws the UI on the terminal until the user quits. Output printed by the services while
+// it runs is shown in the log pane. It returns ErrNoTerminal when the terminal cannot be used.
+func (a *App) Run(ctx context.Context) error {
+	tty, err := openTTY()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	defer tty.Close()
+
+	restoreTerminal, err := makeRaw(tty)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	defer restoreTerminal()
+
+	restoreOutput, err := captureOutput(a.log)
+	if err != nil {
+		return err
+	}
+	defer restoreOutput()
+
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l") // Alternate screen, hidden cursor
+	defer fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.ctx = ctx
+
+	workers := a.options.Config.Parallelism
+	if workers > maxWorkers {
+		workers = maxWorkers
+	}
+	a.downloader.SetProgressFunc(a.queue.ProgressBar)
+	defer a.downloader.SetProgressFunc(nil)
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		a.queue.Run(ctx, workers, a.download)
+	}()
+
+	keys := make(chan Key, 16)
+	go readKeys(tty, keys)
+
+	if len(a.query) > 0 {
+		a.search()
+	}
+
+	ticker := time.NewTicker(redrawPeriod)
+	defer ticker.Stop()
+	for {
+		a.draw(tty)
+		select {
+		case key := <-keys:
+			if a.handleKey(key) {
+				cancel()
+				select {
+				case <-workersDone:
+				case <-time.After(5 * time.Second):
+				}
+				return nil
+			}
+		case event := <-a.events:
+			event()
+		case <-ticker.C:
+			a.collectWarnings()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Summary describes what the queue downloaded, for printing after the UI closes
+func (a *App) Summary() string {
+	counts := a.queue.Counts()
+	return fmt.Sprintf("Downloaded: %d, already present: %d, failed: %d, not started: %d",
+		counts[stateDone], counts[stateSkipped], counts[stateFailed], counts[stateQueued]+counts[stateDownloading])
+}
+
+// handleKey applies a key press and reports whether the UI should close
+func (a *App) handleKey(key Key) bool {
+	if key.Name != "ctrl-c" && key.Rune != 'q' || a.editing {
+		a.confirmQuit = false
+	}
+
+	if a.editing {
+		a.handleEditKey(key)
+		return false
+	}
+
+	switch {
+	case key.Name == "ctrl-c" || key.Rune == 'q':
+		if a.queue.Busy() && !a.confirmQuit {
+			a.confirmQuit = true
+			a.status = "Downloads are running. Press q again to stop them and quit."
+			return false
+		}
+		return true
+	case key.Rune == '/':
+		a.editing = true
+	case key.Rune == 't':
+		a.searchType = nextSearchType(a.searchType)
+		a.search()
+	case key.Name == "tab" || key.Name == "backtab":
+		if a.focus == browserPane {
+			a.focus = queuePane
+		} else {
+			a.focus = browserPane
+		}
+	case key.Name == "up" || key.Rune == 'k':
+		a.move(-1)
+	case key.Name == "down" || key.Rune == 'j':
+		a.move(1)
+	case key.Name == "pgup":
+		a.move(-10)
+	case key.Name == "pgdown":
+		a.move(10)
+	case key.Name == "home" || key.Rune == 'g':
+		a.move(-1 << 30)
+	case key.Name == "end" || key.Rune == 'G':
+		a.move(1 << 30)
+	case key.Name == "enter" || key.Name == "right" || key.Rune == 'l':
+		if n := a.currentNode(); n != nil && a.focus == browserPane {
+			if n.expanded && key.Name == "enter" {
+				n.expanded = false
+			} else {
+				a.expand(n)
+			}
+		}
+	case key.Name == "left" || key.Rune == 'h':
+		if n := a.currentNode(); n != nil && a.focus == browserPane {
+			a.collapse(n)
+		}
+	case key.Rune == ' ':
+		if n := a.currentNode(); n != nil && a.focus == browserPane {
+			n.toggle()
+			a.move(1)
+		}
+	case key.Rune == 'd':
+		a.queueSelection()
+	case key.Rune == 'x' || key.Name == "delete":
+		if a.focus == queuePane && a.queue.Remove(a.queueCursor) {
+			a.status = "Removed from the queue"
+		}
+	case key.Rune == 'r':
+		if retried := a.queue.RetryFailed(); retried > 0 {
+			a.status = fmt.Sprintf("Retrying %d failed tracks", retried)
+		}
+	}
+	return false
+}
+
+// handleEditKey edits the search query
+func (a *App) handleEditKey(key Key) {
+	switch {
+	case key.Name == "enter":
+		a.editing = false
+		a.search()
+	case key.Name == "esc":
+		a.editing = false
+	case key.Name == "ctrl-c":
+		a.editing = false
+	case key.Name == "tab":
+		a.searchType = nextSearchType(a.searchType)
+	case key.Name == "backspace":
+		if len(a.query) > 0 {
+			a.query = a.query[:len(a.query)-1]
+		}
+	case key.Rune != 0:
+		a.query = append(a.query, key.Rune)
+	}
+}
+
+// move moves the cursor of the focused pane
+func (a *App) move(delta int) {
+	if a.focus == queuePane {
+		a.queueCursor = clamp(a.queueCursor+delta, 0, len(a.queue.Entries())-1)
+		return
+	}
+	a.cursor = clamp(a.cursor+delta, 0, len(visibleNodes(a.roots))-1)
+}
+
+// currentNode returns the node under the browser cursor
+func (a *App) currentNode() *node {
+	nodes := visibleNodes(a.roots)
+	if a.cursor < 0 || a.cursor >= len(nodes) {
+		return nil
+	}
+	return nodes[a.cursor]
+}
+
+// search runs the query in the background and replaces the browser tree with the results
+func (a *App) search() {
+	query, searchType := strings.TrimSpace(string(a.query)), a.searchType
+	if query == "" {
+		return
+	}
+
+	a.searching = true
+	a.status = fmt.Sprintf("Searching for '%s' (%s)...", query, searchType)
+	go func() {
+		results, err := a.searcher.SearchPage(a.ctx, query, searchType, searchLimit, 0, a.options.Debug)
+		a.post(func() {
+			a.searching = false
+			if err != nil {
+				a.status = fmt.Sprintf("Search failed: %v", err)
+				return
+			}
+			a.roots, a.cursor, a.scroll, a.focus = resultNodes(results), 0, 0, browserPane
+			a.status = fmt.Sprintf("%d results for '%s'", len(a.roots), query)
+		})
+	}()
+}
+
+// expand opens an artist, release group or album, loading its children the first time
+func (a *App) expand(n *node) {
+	if !n.expandable() || n.loading {
+		return
+	}
+	if n.loaded {
+		n.expanded = true
+		return
+	}
+
+	n.loading, n.err = true, nil
+	go func() {
+		var artist *shared.Artist
+		var album *shared.Album
+		var err error
+		if n.kind == artistNode {
+			artist, err = a.downloader.GetArtistInfo(a.ctx, shared.IdToString(n.artist.ID), a.options.Config, a.options.Debug)
+		} else {
+			album, err = a.downloader.GetAlbumInfo(a.ctx, n.album.ID, a.options.Config, a.options.Debug)
+		}
+
+		a.post(func() {
+			n.loading = false
+			if err != nil {
+				n.err = err
+				a.status = fmt.Sprintf("Failed to load %s: %v", n.label(), err)
+				return
+			}
+			if artist != nil {
+				n.setDiscography(artist)
+			} else {
+				n.setTracks(album)
+			}
+			n.expanded = true
+		})
+	}()
+}
+
+// collapse closes the node, or moves to its parent when it is already closed
+func (a *App) collapse(n *node) {
+	if n.expanded {
+		n.expanded = false
+		return
+	}
+	if n.parent == nil {
+		return
+	}
+	for i, visible := range visibleNodes(a.roots) {
+		if visible == n.parent {
+			a.cursor = i
+			n.parent.expanded = false
+			return
+		}
+	}
+}
+
+// queueSelection adds the checked items, or the item under the cursor, to the download queue
+func (a *App) queueSelection() {
+	selections := checkedSelections(a.roots)
+	if len(selections) == 0 {
+		if n := a.currentNode(); n != nil {
+			n.toggle()
+			selections = checkedSelections([]*node{rootOf(n)})
+		}
+	}
+	uncheckAll(a.roots)
+	if len(selections) == 0 {
+		return
+	}
+
+	a.status = fmt.Sprintf("Adding %d items to the queue...", len(selections))
+	go a.resolve(selections)
+}
+
+// resolve turns selections into tracks and adds them to the queue
+func (a *App) resolve(selections []selection) {
+	added := 0
+	for _, item := range selections {
+		if a.ctx.Err() != nil {
+			return
+		}
+		switch item.kind {
+		case trackNode:
+			album := item.album
+			if !item.loaded {
+				album = a.trackAlbum(item.track)
+			}
+			added += a.queue.Add([]shared.Track{item.track}, &album)
+		case albumNode:
+			added += a.queueAlbum(item.album, item.loaded)
+		case artistNode:
+			albums := item.artist.Albums
+			if !item.loaded {
+				artist, err := a.downloader.GetArtistInfo(a.ctx, shared.IdToString(item.artist.ID), a.options.Config, a.options.Debug)
+				if err != nil {
+					a.log.Add(fmt.Sprintf("Failed to get artist %s: %v", item.artist.Name, err))
+					continue
+				}
+				albums = artist.Albums
+			}
+			for _, album := range albums {
+				added += a.queueAlbum(album, false)
+			}
+		}
+	}
+
+	a.post(func() {
+		a.status = fmt.Sprintf("Queued %d tracks", added)
+	})
+}
+
+// queueAlbum adds the tracks of an album to the queue, fetching the track list when needed
+func (a *App) queueAlbum(album shared.Album, loaded bool) int {
+	if !loaded || len(album.Tracks) == 0 {
+		details, err := a.downloader.GetAlbumInfo(a.ctx, album.ID, a.options.Config, a.options.Debug)
+		if err != nil {
+			a.log.Add(fmt.Sprintf("Failed to get album %s: %v", album.Title, err))
+			return 0
+		}
+		album = *details
+	}
+	return a.queue.Add(album.Tracks, &album)
+}
+
+// trackAlbum fetches the album of a track found by search, so it is tagged like an album download
+func (a *App) trackAlbum(track shared.Track) shared.Album {
+	if track.AlbumID != "" {
+		if album, err := a.downloader.GetAlbumInfo(a.ctx, track.AlbumID, a.options.Config, a.options.Debug); err == nil {
+			return *album
+		}
+	}
+
+	album := shared.Album{ID: track.AlbumID, Title: track.AlbumTitle, Artist: track.AlbumArtist, ReleaseDate: track.ReleaseDate, Year: track.Year}
+	if album.Title == "" {
+		album.Title = track.Album
+	}
+	if album.Artist == "" {
+		album.Artist = track.Artist
+	}
+	return album
+}
+
+// download downloads one queued track with the download service
+func (a *App) download(ctx context.Context, track shared.Track, album *shared.Album) (*shared.DownloadStats, error) {
+	return a.downloader.DownloadTracks(ctx, []shared.Track{track}, album, a.options.Config, a.options.Debug, a.format, a.bitrate)
+}
+
+// collectWarnings copies new warnings to the log pane
+func (a *App) collectWarnings() {
+	if a.warnings == nil {
+		return
+	}
+	warnings := a.warnings.Warnings()
+	if len(warnings) < a.seenWarnings {
+		a.seenWarnings = len(warnings)
+	}
+	for _, warning := range warnings[a.seenWarnings:] {
+		line := "⚠ " + warning.Message
+		if warning.Context != "" {
+			line += ": " + warning.Context
+		}
+		a.log.Add(line)
+	}
+	a.seenWarnings = len(warnings)
+}
+
+// post runs a function on the UI goroutine
+func (a *App) post(event func()) {
+	select {
+	case a.events <- event:
+	case <-a.ctx.Done():
+	}
+}
+
+// draw writes the frame to the terminal when it changed
+func (a *App) draw(w io.Writer) {
+	width, height := 80, 24
+	if tty, ok := w.(*os.File); ok {
+		if columns, rows, err := terminalSize(tty); err == nil && columns > 0 && rows > 0 {
+			width, height = columns, rows
+		}
+	}
+
+	frame := "\x1b[H" + strings.Join(a.render(width, height), "\x1b[K\r\n") + "\x1b[K\x1b[J"
+	if frame != a.lastFrame {
+		io.WriteString(w, frame)
+		a.lastFrame = frame
+	}
+}
+
+// readKeys sends key presses from the terminal until it is closed
+func readKeys(tty io.Reader, keys chan<- Key) {
+	buffer := make([]byte, 256)
+	for {
+		n, err := tty.Read(buffer)
+		if err != nil {
+			return
+		}
+		for _, key := range parseKeys(buffer[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// rootOf returns the top-level node of a branch
+func rootOf(n *node) *node {
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+// nextSearchType returns the search type after the given one
+func nextSearchType(searchType string) string {
+	for i, t := range searchTypes {
+		if t == searchType {
+			return searchTypes[(i+1)%len(searchTypes)]
+		}
+	}
+	return searchTypes[0]
+}
+
+func clamp(value, min, max int) int {
+	if value > max {
+		value = max
+	}
+	if value < min {
+		value = min
+	}
+	return value
+}
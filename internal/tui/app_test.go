@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dab-downloader/internal/config"
+	"dab-downloader/internal/shared"
+	"github.com/cheggaaa/pb/v3"
+)
+
+// fakeServices serves one artist with two albums and records downloads
+type fakeServices struct {
+	mu         sync.Mutex
+	downloaded []string
+}
+
+func (f *fakeServices) SearchPage(ctx context.Context, query string, searchType string, limit int, offset int, debug bool) (*shared.SearchResults, error) {
+	return &shared.SearchResults{Artists: []shared.Artist{{ID: "artist-1", Name: "Artist"}}}, nil
+}
+
+func (f *fakeServices) GetArtistInfo(ctx context.Context, artistID string, cfg *config.Config, debug bool) (*shared.Artist, error) {
+	return &shared.Artist{ID: artistID, Name: "Artist", Albums: []shared.Album{{ID: "a1", Title: "First"}, {ID: "a2", Title: "Second"}}}, nil
+}
+
+func (f *fakeServices) GetAlbumInfo(ctx context.Context, albumID string, cfg *config.Config, debug bool) (*shared.Album, error) {
+	return &shared.Album{ID: albumID, Title: albumID, Tracks: []shared.Track{{ID: albumID + "-1", Title: "Track"}}}, nil
+}
+
+func (f *fakeServices) DownloadTracks(ctx context.Context, tracks []shared.Track, album *shared.Album, cfg *config.Config, debug bool, format string, bitrate string) (*shared.DownloadStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downloaded = append(f.downloaded, shared.IdToString(tracks[0].ID))
+	return &shared.DownloadStats{SuccessCount: 1}, nil
+}
+
+func (f *fakeServices) SetProgressFunc(progressFunc func(track shared.Track) *pb.ProgressBar) {}
+
+// waitForEvent runs the next background result on the test goroutine
+func waitForEvent(t *testing.T, app *App) {
+	t.Helper()
+	select {
+	case event := <-app.events:
+		event()
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a background result")
+	}
+}
+
+func typeKeys(app *App, text string) {
+	for _, r := range text {
+		app.handleKey(Key{Rune: r})
+	}
+}
+
+func TestAppSearchBrowseAndQueue(t *testing.T) {
+	services := &fakeServices{}
+	app := NewApp(services, services, nil, Options{Config: &config.Config{}})
+
+	typeKeys(app, "artist")
+	app.handleKey(Key{Name: "enter"})
+	waitForEvent(t, app)
+	if len(app.roots) != 1 || app.roots[0].label() != "Artist" {
+		t.Fatalf("unexpected results: %v", app.roots)
+	}
+
+	app.handleKey(Key{Name: "right"})
+	waitForEvent(t, app)
+	if got := len(visibleNodes(app.roots)); got != 2 {
+		t.Fatalf("expanded artist shows %d nodes, want 2", got)
+	}
+
+	app.handleKey(Key{Rune: ' '})
+	app.handleKey(Key{Rune: 'd'})
+	waitForEvent(t, app)
+	if got := len(app.queue.Entries()); got != 2 {
+		t.Fatalf("queued %d tracks, want 2", got)
+	}
+	if len(checkedSelections(app.roots)) != 0 {
+		t.Error("selections should be cleared after queueing")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go app.queue.Run(ctx, 1, app.download)
+	deadline := time.Now().Add(2 * time.Second)
+	for app.queue.Busy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	services.mu.Lock()
+	defer services.mu.Unlock()
+	if strings.Join(services.downloaded, ",") != "a1-1,a2-1" {
+		t.Errorf("downloaded %v, want a1-1 and a2-1", services.downloaded)
+	}
+}
+
+func TestAppQuitConfirmsWhileBusy(t *testing.T) {
+	services := &fakeServices{}
+	app := NewApp(services, services, nil, Options{Config: &config.Config{}, Query: "artist"})
+
+	if app.handleKey(Key{Rune: 'q'}) != true {
+		t.Fatal("q should quit when nothing is queued")
+	}
+
+	app.queue.Add([]shared.Track{{ID: "1"}}, &shared.Album{})
+	if app.handleKey(Key{Rune: 'q'}) {
+		t.Fatal("q should ask for confirmation while downloads are queued")
+	}
+	if !app.handleKey(Key{Rune: 'q'}) {
+		t.Fatal("second q should quit")
+	}
+}
+
+func TestRenderFitsTerminal(t *testing.T) {
+	services := &fakeServices{}
+	app := NewApp(services, services, nil, Options{Config: &config.Config{}})
+	app.queue.Add([]shared.Track{{ID: "1", Title: "A very long track title that needs truncating", Artist: "Artist"}}, &shared.Album{})
+
+	lines := app.render(60, 20)
+	if len(lines) != 20 {
+		t.Fatalf("rendered %d lines, want 20", len(lines))
+	}
+	if lines := app.render(30, 10); len(lines) != 1 || !strings.Contains(lines[0], "too small") {
+		t.Errorf("small terminal should show a notice, got %q", lines)
+	}
+}
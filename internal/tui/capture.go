@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// ansiPattern matches color and cursor escape sequences in captured output
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// logBuffer keeps the most recent lines of captured output
+type logBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	limit int
+}
+
+func newLogBuffer(limit int) *logBuffer {
+	return &logBuffer{limit: limit}
+}
+
+// Add appends a line, dropping the oldest lines over the limit
+func (l *logBuffer) Add(line string) {
+	line = strings.TrimSpace(ansiPattern.ReplaceAllString(line, ""))
+	if line == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+	if len(l.lines) > l.limit {
+		l.lines = l.lines[len(l.lines)-l.limit:]
+	}
+}
+
+// Last returns up to n of the most recent lines, oldest first
+func (l *logBuffer) Last(n int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n > len(l.lines) {
+		n = len(l.lines)
+	}
+	return append([]string(nil), l.lines[len(l.lines)-n:]...)
+}
+
+// captureOutput sends everything printed to stdout, including colored output, to the log
+// instead of the screen. The returned function restores stdout.
+func captureOutput(log *logBuffer) (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout, color.Output = writer, writer
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			// Progress output rewrites the line with carriage returns; keep the last state
+			line := scanner.Text()
+			if index := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); index >= 0 {
+				line = line[index+1:]
+			}
+			log.Add(line)
+		}
+	}()
+
+	return func() {
+		os.Stdout, color.Output = stdout, colorOutput
+		writer.Close()
+		<-done
+		reader.Close()
+	}, nil
+}
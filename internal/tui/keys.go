@@ -0,0 +1,89 @@
+package tui
+
+import "unicode/utf8"
+
+// Key is a key press read from the terminal. Rune is set for printable characters, Name for
+// everything else.
+type Key struct {
+	Name string // "up", "down", "left", "right", "pgup", "pgdown", "home", "end", "enter", "tab", "backspace", "esc", "ctrl-c"
+	Rune rune
+}
+
+// escapeKeys maps the escape sequences sent by common terminals to key names
+var escapeKeys = map[string]string{
+	"\x1b[A":  "up",
+	"\x1b[B":  "down",
+	"\x1b[C":  "right",
+	"\x1b[D":  "left",
+	"\x1bOA":  "up",
+	"\x1bOB":  "down",
+	"\x1bOC":  "right",
+	"\x1bOD":  "left",
+	"\x1b[5~": "pgup",
+	"\x1b[6~": "pgdown",
+	"\x1b[H":  "home",
+	"\x1b[F":  "end",
+	"\x1b[1~": "home",
+	"\x1b[4~": "end",
+	"\x1b[3~": "delete",
+	"\x1b[Z":  "backtab",
+}
+
+// parseKeys splits terminal input into key presses. Unknown escape sequences are dropped.
+func parseKeys(input []byte) []Key {
+	var keys []Key
+	for len(input) > 0 {
+		switch b := input[0]; {
+		case b == 0x1b:
+			length := escapeLength(input)
+			if length == 1 {
+				keys = append(keys, Key{Name: "esc"})
+			} else if name, ok := escapeKeys[string(input[:length])]; ok {
+				keys = append(keys, Key{Name: name})
+			}
+			input = input[length:]
+			continue
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Name: "enter"})
+		case b == '\t':
+			keys = append(keys, Key{Name: "tab"})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Name: "backspace"})
+		case b == 0x03:
+			keys = append(keys, Key{Name: "ctrl-c"})
+		case b < 0x20:
+			// Other control characters are ignored
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, Key{Rune: r})
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// escapeLength returns the length of the escape sequence at the start of the input: CSI
+// sequences end with a byte from '@' to '~', SS3 sequences after one more byte.
+func escapeLength(input []byte) int {
+	if len(input) < 2 {
+		return 1
+	}
+	switch input[1] {
+	case '[':
+		for i := 2; i < len(input); i++ {
+			if input[i] >= '@' && input[i] <= '~' {
+				return i + 1
+			}
+		}
+		return len(input)
+	case 'O':
+		if len(input) >= 3 {
+			return 3
+		}
+		return len(input)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{"runes", "aé", []Key{{Rune: 'a'}, {Rune: 'é'}}},
+		{"arrows", "\x1b[A\x1bOB\x1b[C\x1b[D", []Key{{Name: "up"}, {Name: "down"}, {Name: "right"}, {Name: "left"}}},
+		{"paging", "\x1b[5~\x1b[6~", []Key{{Name: "pgup"}, {Name: "pgdown"}}},
+		{"controls", "\r\t\x7f\x03", []Key{{Name: "enter"}, {Name: "tab"}, {Name: "backspace"}, {Name: "ctrl-c"}}},
+		{"lone escape", "\x1b", []Key{{Name: "esc"}}},
+		{"unknown sequence", "\x1b[99~x", []Key{{Rune: 'x'}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
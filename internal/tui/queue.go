@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"dab-downloader/internal/shared"
+	"github.com/cheggaaa/pb/v3"
+)
+
+// itemState is the download state of a queued track
+type itemState int
+
+const (
+	stateQueued itemState = iota
+	stateDownloading
+	stateDone
+	stateSkipped
+	stateFailed
+)
+
+// queueItem is one track in the download queue
+type queueItem struct {
+	track shared.Track
+	album *shared.Album
+	state itemState
+	bar   *pb.ProgressBar
+	err   error
+}
+
+// queueEntry is a copy of a queue item for drawing
+type queueEntry struct {
+	Title   string
+	Artist  string
+	State   itemState
+	Current int64
+	Total   int64
+	Err     error
+}
+
+// downloadFunc downloads one track and returns the download statistics
+type downloadFunc func(ctx context.Context, track shared.Track, album *shared.Album) (*shared.DownloadStats, error)
+
+// Queue is the list of tracks to download. Workers take tracks in order; it is safe for
+// concurrent use.
+type Queue struct {
+	mu    sync.Mutex
+	items []*queueItem
+	wake  chan struct{}
+}
+
+// NewQueue creates an empty download queue
+func NewQueue() *Queue {
+	return &Queue{wake: make(chan struct{}, 1)}
+}
+
+// Add queues the tracks of an album and returns how many were new. Tracks already in the queue
+// and not failed are not added again.
+func (q *Queue) Add(tracks []shared.Track, album *shared.Album) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	added := 0
+	for _, track := range tracks {
+		if existing := q.find(track); existing != nil {
+			if existing.state != stateFailed {
+				continue
+			}
+			existing.state, existing.err, existing.bar = stateQueued, nil, nil
+		} else {
+			q.items = append(q.items, &queueItem{track: track, album: album})
+		}
+		added++
+	}
+	if added > 0 {
+		q.signal()
+	}
+	return added
+}
+
+// Remove drops a track that has not started yet from the queue
+func (q *Queue) Remove(index int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if index < 0 || index >= len(q.items) || q.items[index].state != stateQueued {
+		return false
+	}
+	q.items = append(q.items[:index], q.items[index+1:]...)
+	return true
+}
+
+// RetryFailed queues the failed tracks again and returns how many there were
+func (q *Queue) RetryFailed() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	retried := 0
+	for _, item := range q.items {
+		if item.state == stateFailed {
+			item.state, item.err, item.bar = stateQueued, nil, nil
+			retried++
+		}
+	}
+	if retried > 0 {
+		q.signal()
+	}
+	return retried
+}
+
+// Entries returns a copy of the queue for drawing
+func (q *Queue) Entries() []queueEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]queueEntry, len(q.items))
+	for i, item := range q.items {
+		entries[i] = queueEntry{Title: item.track.Title, Artist: item.track.Artist, State: item.state, Err: item.err}
+		if item.bar != nil {
+			entries[i].Current, entries[i].Total = item.bar.Current(), item.bar.Total()
+		}
+	}
+	return entries
+}
+
+// Counts returns the number of tracks in each state
+func (q *Queue) Counts() map[itemState]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	counts := make(map[itemState]int)
+	for _, item := range q.items {
+		counts[item.state]++
+	}
+	return counts
+}
+
+// Busy reports whether tracks are queued or downloading
+func (q *Queue) Busy() bool {
+	counts := q.Counts()
+	return counts[stateQueued]+counts[stateDownloading] > 0
+}
+
+// ProgressBar returns a progress bar for a track that is downloading. The bar is never started,
+// so it only counts bytes and the queue panel draws it.
+func (q *Queue) ProgressBar(track shared.Track) *pb.ProgressBar {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item := q.find(track)
+	if item == nil || item.state != stateDownloading {
+		return nil
+	}
+	if item.bar == nil {
+		item.bar = pb.New64(0)
+	}
+	return item.bar
+}
+
+// Run downloads queued tracks with the given number of workers until the context is cancelled
+func (q *Queue) Run(ctx context.Context, workers int, download downloadFunc) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, download)
+		}()
+	}
+	wg.Wait()
+}
+
+// work takes tracks from the queue one at a time
+func (q *Queue) work(ctx context.Context, download downloadFunc) {
+	for {
+		item := q.next()
+		if item == nil {
+			select {
+			case <-q.wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		stats, err := download(ctx, item.track, item.album)
+		q.finish(item, stats, err)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// next marks the first queued track as downloading and returns it, or nil when nothing is queued
+func (q *Queue) next() *queueItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, item := range q.items {
+		if item.state == stateQueued {
+			item.state = stateDownloading
+			// Let another worker pick up the next track
+			for _, rest := range q.items[i+1:] {
+				if rest.state == stateQueued {
+					q.signal()
+					break
+				}
+			}
+			return item
+		}
+	}
+	return nil
+}
+
+// finish records the outcome of a download
+func (q *Queue) finish(item *queueItem, stats *shared.DownloadStats, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch {
+	case err != nil:
+		item.state, item.err = stateFailed, err
+	case stats == nil:
+		item.state, item.err = stateFailed, fmt.Errorf("no download result")
+	case stats.SkippedCount > 0:
+		item.state = stateSkipped
+	case stats.SuccessCount > 0:
+		item.state = stateDone
+	default:
+		item.state, item.err = stateFailed, fmt.Errorf("download failed")
+		if len(stats.FailedItems) > 0 {
+			item.err = fmt.Errorf("download failed: %s", strings.Join(stats.FailedItems, ", "))
+		}
+	}
+}
+
+// find returns the queue item for a track. The caller holds the lock.
+func (q *Queue) find(track shared.Track) *queueItem {
+	id := shared.IdToString(track.ID)
+	for _, item := range q.items {
+		if id != "" && shared.IdToString(item.track.ID) == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// signal wakes a waiting worker. The caller holds the lock.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
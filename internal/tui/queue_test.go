@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"dab-downloader/internal/shared"
+)
+
+func TestQueueAddDeduplicates(t *testing.T) {
+	queue := NewQueue()
+	album := &shared.Album{ID: "album"}
+	tracks := []shared.Track{{ID: float64(1), Title: "One"}, {ID: float64(2), Title: "Two"}}
+
+	if added := queue.Add(tracks, album); added != 2 {
+		t.Fatalf("added %d tracks, want 2", added)
+	}
+	if added := queue.Add(tracks[:1], album); added != 0 {
+		t.Fatalf("added %d duplicate tracks, want 0", added)
+	}
+	if !queue.Remove(1) || len(queue.Entries()) != 1 {
+		t.Fatal("queued track was not removed")
+	}
+}
+
+func TestQueueRun(t *testing.T) {
+	queue := NewQueue()
+	album := &shared.Album{ID: "album"}
+	queue.Add([]shared.Track{{ID: float64(1)}, {ID: float64(2)}, {ID: float64(3)}}, album)
+
+	var mu sync.Mutex
+	downloaded := 0
+	download := func(ctx context.Context, track shared.Track, album *shared.Album) (*shared.DownloadStats, error) {
+		if queue.ProgressBar(track) == nil {
+			t.Errorf("no progress bar for track %v", track.ID)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		downloaded++
+		switch track.ID {
+		case float64(2):
+			return &shared.DownloadStats{SkippedCount: 1}, nil
+		case float64(3):
+			return nil, errors.New("network error")
+		}
+		return &shared.DownloadStats{SuccessCount: 1}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx, 2, download)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Busy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	counts := queue.Counts()
+	if counts[stateDone] != 1 || counts[stateSkipped] != 1 || counts[stateFailed] != 1 {
+		t.Fatalf("unexpected states: %v", counts)
+	}
+
+	if retried := queue.RetryFailed(); retried != 1 {
+		t.Fatalf("retried %d tracks, want 1", retried)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for queue.Busy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if downloaded != 4 {
+		t.Errorf("download called %d times, want 4", downloaded)
+	}
+}
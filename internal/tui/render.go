@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	minWidth  = 40
+	minHeight = 12
+	barWidth  = 10
+)
+
+// stateIcons are drawn before each queue entry
+var stateIcons = map[itemState]string{
+	stateQueued:      "·",
+	stateDownloading: "↓",
+	stateDone:        "✓",
+	stateSkipped:     "=",
+	stateFailed:      "✗",
+}
+
+// stateColors are the SGR colors of the queue states
+var stateColors = map[itemState]string{
+	stateDownloading: "36",
+	stateDone:        "32",
+	stateSkipped:     "33",
+	stateFailed:      "31",
+}
+
+// render returns the lines of the screen for the given terminal size
+func (a *App) render(width, height int) []string {
+	if width < minWidth || height < minHeight {
+		return []string{fit(fmt.Sprintf("Terminal too small (%dx%d), at least %dx%d needed", width, height, minWidth, minHeight), width)}
+	}
+
+	logHeight := 3
+	if height >= 24 {
+		logHeight = 6
+	}
+	mainHeight := height - 5 - logHeight
+	leftWidth := width * 3 / 5
+	rightWidth := width - leftWidth - 1
+
+	lines := []string{a.renderHeader(width), a.renderSearch(width)}
+
+	entries := a.queue.Entries()
+	lines = append(lines, separator("Browse", leftWidth, "┬", a.queueTitle(entries), rightWidth))
+	left := a.renderBrowser(leftWidth, mainHeight)
+	right := a.renderQueue(entries, rightWidth, mainHeight)
+	for i := 0; i < mainHeight; i++ {
+		lines = append(lines, left[i]+"│"+right[i])
+	}
+
+	lines = append(lines, separator("Warnings & log", leftWidth, "┴", "", rightWidth))
+	logLines := a.log.Last(logHeight)
+	for i := 0; i < logHeight; i++ {
+		line := ""
+		if i < len(logLines) {
+			line = logLines[i]
+			if strings.HasPrefix(line, "⚠") {
+				line = style("33", fit(line, width))
+			}
+		}
+		lines = append(lines, fit(line, width))
+	}
+
+	return append(lines, a.renderStatus(width))
+}
+
+// renderHeader draws the title line with the key help
+func (a *App) renderHeader(width int) string {
+	help := "/ search  t type  space select  d download  tab queue  r retry  q quit"
+	if a.editing {
+		help = "enter search  tab type  esc cancel"
+	}
+	return style("1", fit("dab-downloader", 16)) + fit(help, width-16)
+}
+
+// renderSearch draws the search box
+func (a *App) renderSearch(width int) string {
+	prefix := fmt.Sprintf("Search [%s]: ", a.searchType)
+	query := string(a.query)
+	if a.editing {
+		query += "█"
+	}
+	if a.searching {
+		query += "  searching..."
+	}
+	// Keep the end of a long query visible while typing
+	available := width - runewidth.StringWidth(prefix)
+	for runewidth.StringWidth(query) > available && query != "" {
+		_, size := firstRune(query)
+		query = query[size:]
+	}
+	return fit(prefix+query, width)
+}
+
+// renderBrowser draws the result tree
+func (a *App) renderBrowser(width, height int) []string {
+	lines := make([]string, height)
+	nodes := visibleNodes(a.roots)
+
+	if len(nodes) == 0 {
+		hint := "Press / to search"
+		if a.searching {
+			hint = "Searching..."
+		} else if len(a.query) > 0 && !a.editing {
+			hint = "No results"
+		}
+		for i := range lines {
+			lines[i] = fit("", width)
+		}
+		lines[0] = fit(" "+hint, width)
+		return lines
+	}
+
+	a.cursor = clamp(a.cursor, 0, len(nodes)-1)
+	a.scroll = scrollFor(a.cursor, a.scroll, height)
+	for i := range lines {
+		index := a.scroll + i
+		if index >= len(nodes) {
+			lines[i] = fit("", width)
+			continue
+		}
+		line := fit(nodeLine(nodes[index]), width)
+		if index == a.cursor {
+			if a.focus == browserPane {
+				line = style("7", line)
+			} else {
+				line = style("1", line)
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// nodeLine formats one line of the tree
+func nodeLine(n *node) string {
+	marker := "  "
+	if n.expandable() {
+		marker = "▸ "
+		if n.expanded {
+			marker = "▾ "
+		}
+	}
+	box := "[ ] "
+	if n.checked {
+		box = "[x] "
+	}
+
+	line := strings.Repeat("  ", n.depth()) + marker + box + n.label()
+	if n.loading {
+		line += "  loading..."
+	}
+	if n.err != nil {
+		line += "  (failed: " + n.err.Error() + ")"
+	}
+	return line
+}
+
+// queueTitle names the queue panel with its counts
+func (a *App) queueTitle(entries []queueEntry) string {
+	if len(entries) == 0 {
+		return "Queue"
+	}
+	counts := a.queue.Counts()
+	finished := counts[stateDone] + counts[stateSkipped]
+	title := fmt.Sprintf("Queue %d/%d", finished, len(entries))
+	if counts[stateFailed] > 0 {
+		title += fmt.Sprintf(", %d failed", counts[stateFailed])
+	}
+	return title
+}
+
+// renderQueue draws the queue panel with a progress bar per track
+func (a *App) renderQueue(entries []queueEntry, width, height int) []string {
+	lines := make([]string, height)
+	if len(entries) == 0 {
+		for i := range lines {
+			lines[i] = fit("", width)
+		}
+		lines[0] = fit(" Select releases and press d", width)
+		return lines
+	}
+
+	a.queueCursor = clamp(a.queueCursor, 0, len(entries)-1)
+	a.queueScroll = scrollFor(a.queueCursor, a.queueScroll, height)
+	for i := range lines {
+		index := a.queueScroll + i
+		if index >= len(entries) {
+			lines[i] = fit("", width)
+			continue
+		}
+		entry := entries[index]
+		status := entryStatus(entry)
+		title := entry.Title
+		if entry.Artist != "" {
+			title += " - " + entry.Artist
+		}
+		line := stateIcons[entry.State] + " " + fit(title, width-runewidth.StringWidth(status)-3) + " " + status
+		line = fit(line, width)
+		if index == a.queueCursor && a.focus == queuePane {
+			line = style("7", line)
+		} else if color := stateColors[entry.State]; color != "" {
+			line = style(color, line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// entryStatus describes the state of a queue entry in a fixed-width column
+func entryStatus(entry queueEntry) string {
+	switch entry.State {
+	case stateDownloading:
+		if entry.Total <= 0 {
+			return fmt.Sprintf("%15s", formatBytes(entry.Current))
+		}
+		filled := int(entry.Current * barWidth / entry.Total)
+		if filled > barWidth {
+			filled = barWidth
+		}
+		return fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled), entry.Current*100/entry.Total)
+	case stateDone:
+		return fmt.Sprintf("%15s", "done")
+	case stateSkipped:
+		return fmt.Sprintf("%15s", "already present")
+	case stateFailed:
+		return fmt.Sprintf("%15s", "failed")
+	default:
+		return fmt.Sprintf("%15s", "queued")
+	}
+}
+
+// renderStatus draws the status line, including the error of the failed track under the cursor
+func (a *App) renderStatus(width int) string {
+	status := a.status
+	if a.focus == queuePane {
+		entries := a.queue.Entries()
+		if a.queueCursor < len(entries) && entries[a.queueCursor].Err != nil {
+			status = "Failed: " + entries[a.queueCursor].Err.Error()
+		}
+	}
+	return style("2", fit(status, width))
+}
+
+// separator draws a horizontal rule with a title over each panel
+func separator(leftTitle string, leftWidth int, joint string, rightTitle string, rightWidth int) string {
+	return rule(leftTitle, leftWidth) + joint + rule(rightTitle, rightWidth)
+}
+
+// rule draws a horizontal line of the given width with an optional title
+func rule(title string, width int) string {
+	if title != "" {
+		title = "─ " + title + " "
+	}
+	title = runewidth.Truncate(title, width, "")
+	return title + strings.Repeat("─", width-runewidth.StringWidth(title))
+}
+
+// scrollFor returns the scroll offset that keeps the cursor visible
+func scrollFor(cursor, scroll, height int) int {
+	if cursor < scroll {
+		return cursor
+	}
+	if cursor >= scroll+height {
+		return cursor - height + 1
+	}
+	return scroll
+}
+
+// fit truncates or pads text to exactly the given display width
+func fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	text = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(text)
+	return runewidth.FillRight(runewidth.Truncate(text, width, "…"), width)
+}
+
+// style wraps text in an SGR escape sequence
+func style(code, text string) string {
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// formatBytes formats a byte count for the queue panel
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+func firstRune(text string) (rune, int) {
+	for _, r := range text {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
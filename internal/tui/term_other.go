@@ -0,0 +1,23 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+// errUnsupported is returned where the terminal cannot be switched to raw mode
+var errUnsupported = errors.New("the terminal UI is not supported on this platform")
+
+func openTTY() (*os.File, error) {
+	return nil, errUnsupported
+}
+
+func makeRaw(tty *os.File) (func() error, error) {
+	return nil, errUnsupported
+}
+
+func terminalSize(tty *os.File) (int, int, error) {
+	return 0, 0, errUnsupported
+}
@@ -0,0 +1,263 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"dab-downloader/internal/shared"
+)
+
+// nodeKind is the kind of a line in the browser tree
+type nodeKind int
+
+const (
+	artistNode nodeKind = iota
+	groupNode
+	albumNode
+	trackNode
+)
+
+// groupOrder is the order of the release groups under an artist
+var groupOrder = []string{"ALBUM", "EP", "SINGLE"}
+
+// groupTitles names the release groups
+var groupTitles = map[string]string{"ALBUM": "Albums", "EP": "EPs", "SINGLE": "Singles"}
+
+// node is one line of the browser: an artist with release groups, a release with tracks, or a track
+type node struct {
+	kind     nodeKind
+	artist   shared.Artist
+	album    shared.Album
+	track    shared.Track
+	group    string // Release type of a group node, e.g. "EP"
+	parent   *node
+	children []*node
+	expanded bool
+	loaded   bool // Children were fetched (artists and albums) or built (groups)
+	loading  bool
+	checked  bool
+	err      error
+}
+
+// resultNodes creates the top-level nodes for search results
+func resultNodes(results *shared.SearchResults) []*node {
+	if results == nil {
+		return nil
+	}
+
+	var nodes []*node
+	for _, artist := range results.Artists {
+		nodes = append(nodes, &node{kind: artistNode, artist: artist})
+	}
+	for _, album := range results.Albums {
+		nodes = append(nodes, &node{kind: albumNode, album: album})
+	}
+	for _, track := range results.Tracks {
+		nodes = append(nodes, &node{kind: trackNode, track: track, loaded: true})
+	}
+	return nodes
+}
+
+// expandable reports whether the node can have children
+func (n *node) expandable() bool {
+	return n.kind != trackNode
+}
+
+// depth returns the indentation level of the node
+func (n *node) depth() int {
+	depth := 0
+	for parent := n.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+	return depth
+}
+
+// label returns the text shown for the node
+func (n *node) label() string {
+	switch n.kind {
+	case artistNode:
+		return n.artist.Name
+	case groupNode:
+		title, ok := groupTitles[n.group]
+		if !ok {
+			title = n.group[:1] + strings.ToLower(n.group[1:]) + "s"
+		}
+		return fmt.Sprintf("%s (%d)", title, len(n.children))
+	case albumNode:
+		label := n.album.Title
+		if n.parent == nil && n.album.Artist != "" {
+			label += " - " + n.album.Artist
+		}
+		if year := releaseYear(n.album); year != "" {
+			label += " (" + year + ")"
+		}
+		return label + qualityLabel(n.album.AudioQuality)
+	default:
+		label := n.track.Title
+		if n.parent == nil || n.track.Artist != n.parent.album.Artist {
+			label += " - " + n.track.Artist
+		}
+		if n.track.TrackNumber > 0 && n.parent != nil {
+			label = fmt.Sprintf("%02d. %s", n.track.TrackNumber, label)
+		}
+		if n.parent == nil {
+			label += qualityLabel(n.track.AudioQuality)
+		}
+		return label
+	}
+}
+
+// setDiscography replaces the children of an artist node with its releases grouped by type
+func (n *node) setDiscography(artist *shared.Artist) {
+	n.artist.Albums = artist.Albums
+	groups := make(map[string]*node)
+	var order []string
+	for _, album := range artist.Albums {
+		group := strings.ToUpper(album.Type)
+		if group == "" {
+			group = "ALBUM"
+		}
+		if groups[group] == nil {
+			groups[group] = &node{kind: groupNode, group: group, parent: n, loaded: true, checked: n.checked}
+			order = append(order, group)
+		}
+		groups[group].children = append(groups[group].children, &node{kind: albumNode, album: album, parent: groups[group], checked: n.checked})
+	}
+
+	n.children = nil
+	for _, group := range groupOrder {
+		if groups[group] != nil {
+			n.children = append(n.children, groups[group])
+		}
+	}
+	for _, group := range order {
+		if !containsString(groupOrder, group) {
+			n.children = append(n.children, groups[group])
+		}
+	}
+	n.loaded = true
+}
+
+// setTracks replaces the children of an album node with its tracks
+func (n *node) setTracks(album *shared.Album) {
+	n.album = *album
+	n.children = nil
+	for _, track := range album.Tracks {
+		n.children = append(n.children, &node{kind: trackNode, track: track, parent: n, loaded: true, checked: n.checked})
+	}
+	n.loaded = true
+}
+
+// toggle checks or unchecks the node with everything below it, and updates the nodes above it
+func (n *node) toggle() {
+	n.setChecked(!n.checked)
+	for parent := n.parent; parent != nil; parent = parent.parent {
+		parent.checked = len(parent.children) > 0
+		for _, child := range parent.children {
+			if !child.checked {
+				parent.checked = false
+				break
+			}
+		}
+	}
+}
+
+// setChecked checks or unchecks the node and everything below it
+func (n *node) setChecked(checked bool) {
+	n.checked = checked
+	for _, child := range n.children {
+		child.setChecked(checked)
+	}
+}
+
+// visibleNodes returns the nodes shown in the browser: every root and the children of expanded nodes
+func visibleNodes(roots []*node) []*node {
+	var nodes []*node
+	var walk func([]*node)
+	walk = func(list []*node) {
+		for _, n := range list {
+			nodes = append(nodes, n)
+			if n.expanded {
+				walk(n.children)
+			}
+		}
+	}
+	walk(roots)
+	return nodes
+}
+
+// selection is something checked for download: an artist, an album or a single track
+type selection struct {
+	kind   nodeKind
+	artist shared.Artist
+	album  shared.Album
+	track  shared.Track
+	loaded bool // Artists: the discography is known. Albums: the track list is known.
+}
+
+// checkedSelections returns the checked items, using the highest checked node of each branch
+func checkedSelections(roots []*node) []selection {
+	var selections []selection
+	var walk func([]*node)
+	walk = func(list []*node) {
+		for _, n := range list {
+			if !n.checked {
+				walk(n.children)
+				continue
+			}
+			switch n.kind {
+			case artistNode:
+				selections = append(selections, selection{kind: artistNode, artist: n.artist, loaded: n.loaded})
+			case groupNode:
+				for _, child := range n.children {
+					selections = append(selections, selection{kind: albumNode, album: child.album, loaded: child.loaded})
+				}
+			case albumNode:
+				selections = append(selections, selection{kind: albumNode, album: n.album, loaded: n.loaded})
+			case trackNode:
+				item := selection{kind: trackNode, track: n.track}
+				if n.parent != nil && n.parent.kind == albumNode {
+					item.album, item.loaded = n.parent.album, true
+				}
+				selections = append(selections, item)
+			}
+		}
+	}
+	walk(roots)
+	return selections
+}
+
+// uncheckAll clears every checkbox
+func uncheckAll(roots []*node) {
+	for _, n := range roots {
+		n.setChecked(false)
+	}
+}
+
+// releaseYear returns the album's year, or the year of its release date
+func releaseYear(album shared.Album) string {
+	if album.Year != "" {
+		return album.Year
+	}
+	if len(album.ReleaseDate) >= 4 {
+		return album.ReleaseDate[:4]
+	}
+	return ""
+}
+
+// qualityLabel formats the audio quality as " [24/96]", or "" when unknown
+func qualityLabel(quality shared.AudioQuality) string {
+	if quality.MaximumBitDepth == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d/%g]", quality.MaximumBitDepth, quality.MaximumSamplingRate)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"testing"
+
+	"dab-downloader/internal/shared"
+)
+
+func testArtist() *shared.Artist {
+	return &shared.Artist{
+		ID:   "artist-1",
+		Name: "Artist",
+		Albums: []shared.Album{
+			{ID: "a1", Title: "First", Type: "album"},
+			{ID: "s1", Title: "Single", Type: "single"},
+			{ID: "e1", Title: "EP", Type: "ep"},
+			{ID: "a2", Title: "Second", Type: "album"},
+		},
+	}
+}
+
+func TestSetDiscographyGroupsReleases(t *testing.T) {
+	artist := &node{kind: artistNode, artist: shared.Artist{ID: "artist-1", Name: "Artist"}}
+	artist.setDiscography(testArtist())
+
+	if len(artist.children) != 3 {
+		t.Fatalf("got %d groups, want 3", len(artist.children))
+	}
+	for i, want := range []string{"Albums (2)", "EPs (1)", "Singles (1)"} {
+		if got := artist.children[i].label(); got != want {
+			t.Errorf("group %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestToggleCascades(t *testing.T) {
+	artist := &node{kind: artistNode}
+	artist.setDiscography(testArtist())
+	albums := artist.children[0]
+
+	albums.children[0].toggle()
+	if albums.checked || artist.checked {
+		t.Fatal("parents should stay unchecked while a child is unchecked")
+	}
+	albums.children[1].toggle()
+	if !albums.checked {
+		t.Fatal("group should be checked when all of its albums are")
+	}
+
+	selections := checkedSelections([]*node{artist})
+	if len(selections) != 2 || selections[0].album.ID != "a1" || selections[1].album.ID != "a2" {
+		t.Fatalf("unexpected selections: %+v", selections)
+	}
+
+	artist.toggle()
+	if selections := checkedSelections([]*node{artist}); len(selections) != 1 || selections[0].kind != artistNode {
+		t.Fatalf("checking the artist should select the artist, got %+v", selections)
+	}
+
+	uncheckAll([]*node{artist})
+	if len(checkedSelections([]*node{artist})) != 0 {
+		t.Fatal("uncheckAll left selections")
+	}
+}
+
+func TestVisibleNodesFollowsExpansion(t *testing.T) {
+	artist := &node{kind: artistNode}
+	artist.setDiscography(testArtist())
+	roots := []*node{artist}
+
+	if got := len(visibleNodes(roots)); got != 1 {
+		t.Fatalf("collapsed tree shows %d nodes, want 1", got)
+	}
+	artist.expanded = true
+	artist.children[0].expanded = true
+	if got := len(visibleNodes(roots)); got != 6 {
+		t.Fatalf("expanded tree shows %d nodes, want 6", got)
+	}
+}